@@ -145,9 +145,11 @@ func getServerAddr(appCfg []byte) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	b := config.NewConfigReaderBuilder().WithFs(memFs).WithConfigFile("config.yaml")
-
-	err = b.Build().Unmarshal(&cfg)
+	reader, err := config.NewConfigReaderBuilder().WithFs(memFs).WithConfigFile("config.yaml").Build()
+	if err != nil {
+		return "", err
+	}
+	err = reader.Unmarshal(&cfg)
 	if err != nil {
 		return "", err
 	}
@@ -191,15 +191,15 @@ func GetPingAsync(ctx context.Context, req *gateway.GetPingAsyncdownstreamsListR
 	ctx1 := common.RespHeaderAndStatusToContext(ctx, make(http.Header), 0)
 	ctx2 := common.RespHeaderAndStatusToContext(ctx, make(http.Header), 0)
 
-	backend1Future := common.Async(ctx1, func(ctxInt context.Context) (interface{}, error) {
+	backend1Future := common.Async(ctx1, func(ctxInt context.Context) (*encoder_backend.Pong, error) {
 		return client.Encoder_backendGetPingList(ctxInt, backend1Req)
 	})
-	backend2Future := common.Async(ctx2, func(ctxInt context.Context) (interface{}, error) {
+	backend2Future := common.Async(ctx2, func(ctxInt context.Context) (any, error) {
 		return client.Multi_contenttype_backendPostPingMultiColon(ctxInt, backend2Req)
 	})
 
 	// above 2 calls have been made asynchronously, lets get their results
-	encoderResponseInterface, err := backend1Future.Get()
+	encoderResponse, err := backend1Future.Get()
 	if err != nil {
 		return nil, err
 	}
@@ -210,8 +210,6 @@ func GetPingAsync(ctx context.Context, req *gateway.GetPingAsyncdownstreamsListR
 		return nil, err
 	}
 
-	encoderResponse := encoderResponseInterface.(*encoder_backend.Pong)
-
 	return &gateway.Pong{
 		Identifier: encoderResponse.Identifier,
 	}, nil
@@ -2,11 +2,23 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	frontdoor "temporal_client/internal/gen/pkg/servers/temporal_client"
 	"temporal_client/internal/gen/pkg/servers/temporal_client/temporalworker"
 	pb "temporal_client/protos"
 	"testing"
+
+	"github.com/anz-bank/sysl-go/common"
+	"github.com/anz-bank/sysl-go/core"
+	"github.com/anz-bank/sysl-go/testutil"
+	"github.com/anz-bank/sysl-go/testutil/temporal_tester"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	enumspb "go.temporal.io/api/enums/v1"
 )
 
 func TestExecutorMock(t *testing.T) {
@@ -81,3 +93,45 @@ func TestExecutorMockWithAssertions(t *testing.T) {
 		}).
 		Send()
 }
+
+func TestWorkflowRouter(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.NewTestContext()
+
+	mocks := temporalworker.NewDownstreamMocks(t)
+	mocks.WorkflowWithParamAndReturn.
+		ExpectRequest(temporalworker.Param1{Msg: "hi"}).
+		MockResponse(temporalworker.Param2{Msg2: "hello"}, nil)
+	c := temporal_tester.NewTemporalMockClient(temporal_tester.NewEnvWithWorkflows(mocks.GetWorkflows()...))
+
+	// the routes of the TemporalWorker workflows marked with ~http
+	workflowRouter, err := temporalworker.NewWorkflowRouter(ctx, &core.Hooks{}, common.DefaultCallback(), temporalworker.NewClient(c))
+	require.NoError(t, err)
+	r := chi.NewRouter()
+	workflowRouter.WireRoutes(ctx, r)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body)).WithContext(ctx)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodPost, "/workflows/WorkflowWithParamAndReturn", `{"msg": "hi"}`)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	var started core.TemporalWorkflowStarted
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &started))
+	require.NotEmpty(t, started.WorkflowID)
+	require.NotEmpty(t, started.RunID)
+
+	w = serve(http.MethodGet, "/workflows/WorkflowWithParamAndReturn/"+started.WorkflowID+"?wait=true", ``)
+	require.Equal(t, http.StatusOK, w.Code)
+	status := core.TemporalWorkflowStatus{Result: &temporalworker.Param2{}}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.Equal(t, core.TemporalWorkflowStatus{
+		WorkflowID: started.WorkflowID,
+		RunID:      started.RunID,
+		Status:     enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED.String(),
+		Result:     &temporalworker.Param2{Msg2: "hello"},
+	}, status)
+}
@@ -25,6 +25,9 @@ func createService(ctx context.Context, config AppConfig) (*temporalworker.Tempo
 	// Perform one-time setup based on config here.
 	return &temporalworker.TemporalServiceInterface{
 		WorkflowWithActivities:     WorkflowWithActivities,
+		WorkflowWithSignals:        WorkflowWithSignals,
+		ActivityWithParam:          ActivityWithParam,
+		UndoActivityWithParam:      UndoActivityWithParam,
 		ActivityWithParamAndReturn: ActivityWithParamAndReturn,
 	}, &core.Hooks{}, nil
 }
@@ -34,18 +37,62 @@ func WorkflowWithActivities(
 	activities temporalworker.WorkflowWithActivitiesActivities,
 	req temporalworker.Param1,
 ) (temporalworker.Param2, error) {
+	// UndoActivityWithParam compensates ActivityWithParam if a later activity fails.
+	if _, err := activities.ActivityWithParam(ctx, req).Get(ctx); err != nil {
+		return temporalworker.Param2{}, err
+	}
 	f := activities.ActivityWithParamAndReturn(ctx, temporalworker.Param1{
 		Msg: fmt.Sprintf("%s | Executing Activity", req.Msg),
 	})
 	s, err := f.Get(ctx)
 	if err != nil {
-		return temporalworker.Param2{}, nil
+		return temporalworker.Param2{}, err
 	}
 	return temporalworker.Param2{
 		Msg2: fmt.Sprintf("%s | Activity Executed", s.Msg2),
 	}, nil
 }
 
+func WorkflowWithSignals(ctx workflow.Context, req temporalworker.Param1) (temporalworker.Param2, error) {
+	status := temporalworker.Param2{Msg2: req.Msg}
+	err := temporalworker.SetStatusQueryHandler(ctx, func() (temporalworker.Param2, error) {
+		return status, nil
+	})
+	if err != nil {
+		return temporalworker.Param2{}, err
+	}
+	err = temporalworker.SetRenameUpdateHandler(ctx, func(ctx workflow.Context, req temporalworker.Param1) (temporalworker.Param2, error) {
+		status.Msg2 = req.Msg
+		return status, nil
+	})
+	if err != nil {
+		return temporalworker.Param2{}, err
+	}
+
+	approval, _ := temporalworker.ApproveSignalChannel(ctx).Receive(ctx)
+	return temporalworker.Param2{
+		Msg2: fmt.Sprintf("%s | %s", status.Msg2, approval.Msg),
+	}, nil
+}
+
+func ActivityWithParam(
+	ctx context.Context,
+	client temporalworker.ActivityWithParamClient,
+	req temporalworker.Param1,
+) error {
+	_, err := client.SomedownstreamPost(ctx, &somedownstream.PostRequest{
+		Request: somedownstream.SomeReq{
+			Msg: req.Msg,
+		},
+	})
+	return err
+}
+
+func UndoActivityWithParam(ctx context.Context, req temporalworker.Param1) error {
+	// Undo what ActivityWithParam did for req here.
+	return nil
+}
+
 func ActivityWithParamAndReturn(
 	ctx context.Context,
 	client temporalworker.ActivityWithParamAndReturnClient,
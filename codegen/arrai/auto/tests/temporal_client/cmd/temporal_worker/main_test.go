@@ -3,12 +3,22 @@ package main
 import (
 	"context"
 	"testing"
+	"time"
 
+	workerclient "temporal_client/internal/gen/pkg/servers/temporal_client/temporalworker"
 	temporalworker "temporal_client/internal/gen/pkg/servers/temporal_worker"
 	"temporal_client/internal/gen/pkg/servers/temporal_worker/somedownstream"
 
+	"github.com/anz-bank/sysl-go/core"
+	"github.com/anz-bank/sysl-go/log"
+	"github.com/anz-bank/sysl-go/testutil/temporal_tester"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/mocks"
+	"go.temporal.io/sdk/temporal"
 )
 
 func TestWorkflowWithRealActivity(t *testing.T) {
@@ -18,6 +28,11 @@ func TestWorkflowWithRealActivity(t *testing.T) {
 	testServer := temporalworker.NewTestServer(t, context.Background(), createService, ``)
 	defer testServer.Close()
 
+	// this is mocking the downstream service that the activity ActivityWithParam calls
+	testServer.Mocks.Somedownstream.Post.
+		ExpectBody(somedownstream.SomeReq{Msg: "hi"}).
+		MockResponse(200, map[string]string{}, &somedownstream.SomeResp{})
+
 	// this is mocking the downstream service that the activity ActivityWithParamAndReturn calls
 	testServer.Mocks.Somedownstream.Post.MockResponse(200, map[string]string{}, &somedownstream.SomeResp{
 		Msg: "hi",
@@ -44,6 +59,8 @@ func TestWorkflowWithMockedActivity(t *testing.T) {
 	testServer := temporalworker.NewTestServer(t, context.Background(), createService, ``)
 	defer testServer.Close()
 
+	testServer.Mocks.Self.ActivityWithParam.MockResponse(nil)
+
 	// adding assertions to the activity ActivityWithParamAndReturn but still using the actual activity
 	testServer.Mocks.Self.ActivityWithParamAndReturn.
 		ExpectRequest(temporalworker.Param1{Msg: "hi | Executing Activity"}).
@@ -59,6 +76,78 @@ func TestWorkflowWithMockedActivity(t *testing.T) {
 	assert.Equal(t, "hiii | Activity Executed", resp2.Msg2)
 }
 
+func TestWorkflowCompensatesCompletedActivities(t *testing.T) {
+	t.Parallel()
+
+	var undone []temporalworker.Param1
+	createSagaService := func(ctx context.Context, config AppConfig) (*temporalworker.TemporalServiceInterface, *core.Hooks, error) {
+		service, hooks, err := createService(ctx, config)
+		if err != nil {
+			return nil, nil, err
+		}
+		service.UndoActivityWithParam = func(ctx context.Context, req temporalworker.Param1) error {
+			undone = append(undone, req)
+			return nil
+		}
+		return service, hooks, nil
+	}
+	testServer := temporalworker.NewTestServer(t, context.Background(), createSagaService, ``)
+	defer testServer.Close()
+
+	testServer.Mocks.Self.ActivityWithParam.
+		ExpectRequest(temporalworker.Param1{Msg: "hi"}).
+		MockResponse(nil)
+
+	// the activity after ActivityWithParam fails, so that UndoActivityWithParam compensates it
+	testServer.Mocks.Self.ActivityWithParamAndReturn.
+		MockResponse(temporalworker.Param2{}, temporal.NewNonRetryableApplicationError("declined", "Declined", nil))
+
+	resp, err := testServer.WorkflowWithActivities(context.Background(), temporalworker.Param1{Msg: "hi"})
+	require.NoError(t, err)
+	_, err = resp.Get(context.Background())
+	require.ErrorContains(t, err, "declined")
+	assert.Equal(t, []temporalworker.Param1{{Msg: "hi"}}, undone)
+}
+
+func TestWorkflowWithSignals(t *testing.T) {
+	t.Parallel()
+
+	testServer := temporalworker.NewTestServer(t, context.Background(), createService, ``)
+	defer testServer.Close()
+
+	ctx := context.Background()
+	env := testServer.GetTestWorkflowEnv()
+
+	// the client generated for the apps that call the worker, running the workflow of the test server
+	c := workerclient.NewClient(temporal_tester.NewTemporalMockClient(env))
+
+	// the workflow runs to completion within WorkflowWithSignals, so the query, the update and the
+	// signal are sent from delayed callbacks while it waits for the approval
+	var rename *core.UpdateHandle[workerclient.Param2]
+	env.RegisterDelayedCallback(func() {
+		status, err := c.Status(ctx, "signals", "")
+		require.NoError(t, err)
+		assert.Equal(t, workerclient.Param2{Msg2: "hi"}, status)
+
+		rename, err = c.Rename(ctx, "signals", "", workerclient.Param1{Msg: "renamed"})
+		require.NoError(t, err)
+	}, time.Minute)
+	env.RegisterDelayedCallback(func() {
+		require.NoError(t, c.Approve(ctx, "signals", "", workerclient.Param1{Msg: "approved"}))
+	}, time.Hour)
+
+	run, err := c.WorkflowWithSignals(ctx, workerclient.Param1{Msg: "hi"}, client.StartWorkflowOptions{ID: "signals"})
+	require.NoError(t, err)
+	resp, err := run.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "renamed | approved", resp.Msg2)
+
+	require.NotNil(t, rename)
+	renamed, err := rename.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, workerclient.Param2{Msg2: "renamed"}, renamed)
+}
+
 func TestActivity(t *testing.T) {
 	t.Parallel()
 	testServer := temporalworker.NewTestServer(t, context.Background(), createService, ``)
@@ -86,3 +175,37 @@ func TestActivity(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "hi", resp2.Msg2)
 }
+
+func TestSchedules(t *testing.T) {
+	t.Parallel()
+
+	// the schedule attributes of WorkflowWithoutParam
+	schedule := core.TemporalSchedule{
+		Workflow:        temporalworker.WorkflowWithoutParamName,
+		CronExpressions: []string{"0 * * * *"},
+		Overlap:         "bufferOne",
+		Jitter:          time.Minute,
+	}
+	h := &temporalworker.TemporalServiceHandler{}
+	require.Equal(t, []core.TemporalSchedule{schedule}, h.GetSchedules())
+
+	// the worker reconciles the schedules on start, which creates the missing ones
+	c := mocks.NewClient(t)
+	sc := mocks.NewScheduleClient(t)
+	c.On("ScheduleClient").Return(sc)
+	iter := mocks.NewScheduleListIterator(t)
+	iter.On("HasNext").Return(false).Once()
+	sc.On("List", mock.Anything, mock.Anything).Return(iter, nil).Once()
+	sc.On("Create", mock.Anything, mock.MatchedBy(func(o client.ScheduleOptions) bool {
+		action := o.Action.(*client.ScheduleWorkflowAction)
+		return o.ID == temporalworker.TaskQueueName+"/"+temporalworker.WorkflowWithoutParamName &&
+			assert.ObjectsAreEqual([]string{"0 * * * *"}, o.Spec.CronExpressions) &&
+			o.Spec.Jitter == time.Minute &&
+			o.Overlap == enumspb.SCHEDULE_OVERLAP_POLICY_BUFFER_ONE &&
+			action.Workflow == temporalworker.WorkflowWithoutParamName &&
+			action.TaskQueue == temporalworker.TaskQueueName
+	})).Return(nil, nil).Once()
+
+	ctx := log.PutLogger(context.Background(), log.NewDefaultLogger())
+	require.NoError(t, core.ReconcileTemporalSchedules(ctx, c, temporalworker.TaskQueueName, h.GetSchedules()))
+}
@@ -2,80 +2,227 @@ package common
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"runtime/debug"
+	"sync"
 )
 
-type AsyncFunc func(ctx context.Context) (interface{}, error)
+// AsyncFunc is a function executed in a new go routine. The context it receives carries the
+// values of the caller's context, such as the request logger and trace ID.
+type AsyncFunc[T any] func(ctx context.Context) (T, error)
 
-type AsyncResult struct {
-	Obj interface{}
+type AsyncResult[T any] struct {
+	Obj T
 	Err error
 }
 
 // A Future is an object that can retrieve a value from an Async call.
-type Future interface {
+type Future[T any] interface {
 	// Calling future.Get() will block until the Async function either returns a value or panics or the context is done
-	Get() (interface{}, error)
+	Get() (T, error)
 
 	// GetChan will return a channel that the AsyncResult can be read from.
 	// Panic and context handling is already handled by Async so there is no need to use a select with a context to cancel
 	// the blocking call
-	GetChan() <-chan AsyncResult
+	GetChan() <-chan AsyncResult[T]
 }
 
 // Async executes the passed in fn in a new go routine and returns a Future which can be called to retrieve the
 // result from the go routine.
 // This will capture any panic() in the Future as well as manages channels to pass the returned error and result
 // back to the caller.
-func Async(ctx context.Context, fn AsyncFunc) Future {
-	resultChan := make(chan AsyncResult, 1)
+func Async[T any](ctx context.Context, fn AsyncFunc[T]) Future[T] {
+	resultChan := make(chan AsyncResult[T], 1)
 
 	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				resultChan <- AsyncResult{Obj: nil,
-					Err: &ServerError{
-						Kind:    InternalError,
-						Message: string(debug.Stack()),
-						Cause:   fmt.Errorf("%+v", r),
-					}}
-			}
-		}()
-
-		res, err := fn(ctx)
-		resultChan <- AsyncResult{
-			Obj: res,
-			Err: err,
-		}
+		resultChan <- runAsync(ctx, fn)
 	}()
 
-	return &futureImp{func() (interface{}, error) {
+	return &futureImp[T]{func() (T, error) {
 		select {
 		case <-ctx.Done():
-			return nil, ctx.Err()
+			var t T
+			return t, ctx.Err()
 		case r := <-resultChan:
 			return r.Obj, r.Err
 		}
 	}}
 }
 
-type futureImp struct {
-	get func() (interface{}, error)
+// runAsync calls fn and turns a panic into an InternalError.
+func runAsync[T any](ctx context.Context, fn AsyncFunc[T]) (result AsyncResult[T]) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = AsyncResult[T]{Err: &ServerError{
+				Kind:    InternalError,
+				Message: string(debug.Stack()),
+				Cause:   fmt.Errorf("%+v", r),
+			}}
+		}
+	}()
+
+	res, err := fn(ctx)
+	return AsyncResult[T]{Obj: res, Err: err}
 }
 
-func (future *futureImp) Get() (interface{}, error) {
+type futureImp[T any] struct {
+	get func() (T, error)
+}
+
+func (future *futureImp[T]) Get() (T, error) {
 	return future.get()
 }
 
-func (future *futureImp) GetChan() <-chan AsyncResult {
-	r := make(chan AsyncResult, 1)
+func (future *futureImp[T]) GetChan() <-chan AsyncResult[T] {
+	r := make(chan AsyncResult[T], 1)
 
 	go func() {
 		obj, err := future.get()
 
-		r <- AsyncResult{obj, err}
+		r <- AsyncResult[T]{obj, err}
 	}()
 
 	return r
 }
+
+type indexedResult[T any] struct {
+	index int
+	AsyncResult[T]
+}
+
+// start runs each fn in its own go routine and sends the results to the returned channel,
+// which has room for all of them so that no go routine is left blocked.
+func start[T any](ctx context.Context, fns []AsyncFunc[T]) <-chan indexedResult[T] {
+	results := make(chan indexedResult[T], len(fns))
+	for i, fn := range fns {
+		go func() {
+			results <- indexedResult[T]{i, runAsync(ctx, fn)}
+		}()
+	}
+	return results
+}
+
+// All executes the functions concurrently and returns their results in the same order. The
+// first error is returned as soon as it occurs and cancels the context of the other functions.
+func All[T any](ctx context.Context, fns ...AsyncFunc[T]) ([]T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := start(ctx, fns)
+	values := make([]T, len(fns))
+	for range fns {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case r := <-results:
+			if r.Err != nil {
+				return nil, r.Err
+			}
+			values[r.index] = r.Obj
+		}
+	}
+	return values, nil
+}
+
+// Any executes the functions concurrently and returns the first successful result, which cancels
+// the context of the other functions. If all the functions fail their errors are joined.
+func Any[T any](ctx context.Context, fns ...AsyncFunc[T]) (T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var t T
+	results := start(ctx, fns)
+	errs := make([]error, len(fns))
+	for range fns {
+		select {
+		case <-ctx.Done():
+			return t, ctx.Err()
+		case r := <-results:
+			if r.Err == nil {
+				return r.Obj, nil
+			}
+			errs[r.index] = r.Err
+		}
+	}
+	return t, errors.Join(errs...)
+}
+
+// Race executes the functions concurrently and returns the result of the first one to complete,
+// whether it succeeds or fails. The context of the other functions is cancelled.
+func Race[T any](ctx context.Context, fns ...AsyncFunc[T]) (T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var t T
+	if len(fns) == 0 {
+		return t, errors.New("race without functions")
+	}
+	select {
+	case <-ctx.Done():
+		return t, ctx.Err()
+	case r := <-start(ctx, fns):
+		return r.Obj, r.Err
+	}
+}
+
+// Map applies fn to each item with at most limit calls running at once, or one per item if limit
+// is not positive, and returns the results in the order of the items. The first error stops the
+// remaining items from being started and cancels the context of the calls in progress.
+func Map[T, R any](ctx context.Context, items []T, limit int, fn func(ctx context.Context, item T) (R, error)) ([]R, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if limit <= 0 || limit > len(items) {
+		limit = len(items)
+	}
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	values := make([]R, len(items))
+	indexes := make(chan int)
+	for range limit {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				if ctx.Err() != nil {
+					continue
+				}
+				r := runAsync(ctx, func(ctx context.Context) (R, error) { return fn(ctx, items[i]) })
+				if r.Err != nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = r.Err
+						cancel()
+					}
+					mu.Unlock()
+					continue
+				}
+				values[i] = r.Obj
+			}
+		}()
+	}
+
+feed:
+	for i := range items {
+		select {
+		case <-ctx.Done():
+			break feed
+		case indexes <- i:
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
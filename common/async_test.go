@@ -4,9 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -130,3 +132,129 @@ func TestAsyncGetChan(t *testing.T) {
 	assert.Nil(t, resTimeOut.Obj)
 	assert.Equal(t, context.DeadlineExceeded, resTimeOut.Err)
 }
+
+func TestAsyncTyped(t *testing.T) {
+	future := Async(context.Background(), func(_ context.Context) (int, error) {
+		return 2, nil
+	})
+
+	result, err := future.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, result)
+}
+
+func TestAsyncInheritsTraceID(t *testing.T) {
+	id := uuid.New()
+	ctx := AddTraceIDToContext(context.Background(), id, true)
+
+	result, err := Async(ctx, func(ctx context.Context) (uuid.UUID, error) {
+		return GetTraceIDFromContext(ctx), nil
+	}).Get()
+	assert.NoError(t, err)
+	assert.Equal(t, id, result)
+}
+
+func TestAll(t *testing.T) {
+	results, err := All(context.Background(),
+		func(_ context.Context) (int, error) {
+			time.Sleep(10 * time.Millisecond)
+			return 1, nil
+		},
+		func(_ context.Context) (int, error) { return 2, nil },
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, results)
+}
+
+func TestAllCancelsOnError(t *testing.T) {
+	cancelled := make(chan struct{})
+	_, err := All(context.Background(),
+		func(ctx context.Context) (int, error) {
+			<-ctx.Done()
+			close(cancelled)
+			return 0, ctx.Err()
+		},
+		func(_ context.Context) (int, error) { return 0, errors.New("downstream error") },
+	)
+	assert.EqualError(t, err, "downstream error")
+	<-cancelled
+}
+
+func TestAllCapturesPanic(t *testing.T) {
+	_, err := All(context.Background(), func(_ context.Context) (int, error) {
+		panic("panic error")
+	})
+	assert.IsType(t, &ServerError{}, err)
+	assert.Equal(t, InternalError, err.(*ServerError).Kind)
+}
+
+func TestAny(t *testing.T) {
+	result, err := Any(context.Background(),
+		func(_ context.Context) (string, error) { return "", errors.New("primary down") },
+		func(_ context.Context) (string, error) { return "secondary", nil },
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "secondary", result)
+
+	_, err = Any(context.Background(),
+		func(_ context.Context) (string, error) { return "", errors.New("primary down") },
+		func(_ context.Context) (string, error) { return "", errors.New("secondary down") },
+	)
+	assert.EqualError(t, err, "primary down\nsecondary down")
+}
+
+func TestRace(t *testing.T) {
+	_, err := Race(context.Background(),
+		func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "slow", nil
+		},
+		func(_ context.Context) (string, error) { return "", errors.New("fast error") },
+	)
+	assert.EqualError(t, err, "fast error")
+
+	_, err = Race[string](context.Background())
+	assert.Error(t, err)
+}
+
+func TestMap(t *testing.T) {
+	var running, maxRunning int32
+	results, err := Map(context.Background(), []int{1, 2, 3, 4, 5}, 2, func(_ context.Context, i int) (string, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		return fmt.Sprint(i * 10), nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10", "20", "30", "40", "50"}, results)
+	assert.LessOrEqual(t, maxRunning, int32(2))
+}
+
+func TestMapStopsOnError(t *testing.T) {
+	var calls int32
+	_, err := Map(context.Background(), []int{1, 2, 3, 4, 5}, 1, func(_ context.Context, i int) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		if i == 2 {
+			return 0, errors.New("item error")
+		}
+		return i, nil
+	})
+	assert.EqualError(t, err, "item error")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestMapContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Map(ctx, []int{1, 2}, 0, func(_ context.Context, i int) (int, error) {
+		return i, nil
+	})
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,18 @@
+package common
+
+import (
+	"net/http"
+)
+
+// MaxRequestBodyMiddleware limits the size of request bodies to the given number of bytes.
+// The body of a request is wrapped so that reading past the limit fails with
+// *http.MaxBytesError, which the handler reports like its other errors, through the MapError
+// and WriteError of its callback; MapError maps it to 413 Request Entity Too Large by default.
+func MaxRequestBodyMiddleware(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
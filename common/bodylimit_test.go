@@ -0,0 +1,78 @@
+package common
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/anz-bank/sysl-go/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxRequestBodyMiddleware_LimitsDeclaredBody(t *testing.T) {
+	ctx := testutil.NewTestContext()
+	handler := MaxRequestBodyMiddleware(4)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.ReadAll(r.Body)
+		var maxBytesErr *http.MaxBytesError
+		require.ErrorAs(t, err, &maxBytesErr)
+		HandleError(r.Context(), w, BadRequestError, "Error reading request body", err, nil, nil)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("too long")).WithContext(ctx)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestMaxRequestBodyMiddleware_LeavesTheErrorToTheHandler(t *testing.T) {
+	ctx := testutil.NewTestContext()
+	mapError := func(ctx context.Context, err error) *HTTPError {
+		return &HTTPError{HTTPCode: http.StatusBadRequest, Code: "too_large", Description: "Body too large"}
+	}
+	handler := MaxRequestBodyMiddleware(4)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.ReadAll(r.Body)
+		HandleError(r.Context(), w, BadRequestError, "Error reading request body", err, mapError, nil)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("too long")).WithContext(ctx)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "too_large")
+}
+
+func TestMaxRequestBodyMiddleware_LimitsUndeclaredBody(t *testing.T) {
+	ctx := testutil.NewTestContext()
+	handler := MaxRequestBodyMiddleware(4)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.ReadAll(r.Body)
+		require.Error(t, err)
+		HandleError(r.Context(), w, BadRequestError, "Error reading request body", err, nil, nil)
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("too long")).WithContext(ctx)
+	req.ContentLength = -1
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestMaxRequestBodyMiddleware_AllowsSmallBody(t *testing.T) {
+	ctx := testutil.NewTestContext()
+	handler := MaxRequestBodyMiddleware(4)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.Equal(t, "ok", string(body))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("ok")).WithContext(ctx)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,97 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/anz-bank/sysl-go/log"
+)
+
+// DeadlineMiddleware applies the remaining time budget of the caller to the request context.
+// The budget is read from the header configured in library.deadline.header. Requests without
+// the header (or when no header is configured) are passed through unchanged.
+func DeadlineMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		name := getDeadlineHeader(ctx)
+		if name == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		value := r.Header.Get(name)
+		if value == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		budget, err := ParseRequestTimeout(value)
+		if err != nil {
+			log.Debugf(ctx, "ignoring invalid %s header value %q: %v", name, value, err)
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := context.WithTimeout(ctx, budget)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ParseRequestTimeout parses a time budget header value. The value is either a duration
+// (e.g. 1500ms or 1.5s) or a whole number of milliseconds.
+func ParseRequestTimeout(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// DownstreamBudget returns the timeout to apply to downstream calls made within the given
+// context. It is the smaller of the given timeout and the time remaining before the deadline
+// of the context, less the configured safety margin. The result may be zero or negative when
+// the caller has already run out of time. HTTP downstream calls are further limited to the
+// ClientTimeout of the downstream, which is also the most that is sent onwards.
+func DownstreamBudget(ctx context.Context, timeout time.Duration) time.Duration {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return timeout
+	}
+	var margin time.Duration
+	if cfg := config.GetDefaultConfig(ctx); cfg != nil {
+		margin = cfg.Library.Deadline.SafetyMargin
+	}
+	if remaining := time.Until(deadline) - margin; remaining < timeout {
+		return remaining
+	}
+	return timeout
+}
+
+// OutgoingRequestTimeoutHeader returns the name and value of the header to send to a downstream
+// to propagate the remaining time budget of the given context. It returns false if there is
+// no header configured or the context has no deadline.
+func OutgoingRequestTimeoutHeader(ctx context.Context) (name, value string, ok bool) {
+	name = getDeadlineHeader(ctx)
+	if name == "" {
+		return "", "", false
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return "", "", false
+	}
+	remaining := time.Until(deadline)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return name, strconv.FormatInt(remaining.Milliseconds(), 10) + "ms", true
+}
+
+func getDeadlineHeader(ctx context.Context) string {
+	cfg := config.GetDefaultConfig(ctx)
+	if cfg == nil {
+		return ""
+	}
+	return cfg.Library.Deadline.Header
+}
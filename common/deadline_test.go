@@ -0,0 +1,100 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/stretchr/testify/require"
+)
+
+func deadlineConfigContext(header string, margin time.Duration) context.Context {
+	cfg := config.DefaultConfig{}
+	cfg.Library.Deadline.Header = header
+	cfg.Library.Deadline.SafetyMargin = margin
+	return config.PutDefaultConfig(context.Background(), &cfg)
+}
+
+func TestParseRequestTimeout(t *testing.T) {
+	d, err := ParseRequestTimeout("1500")
+	require.NoError(t, err)
+	require.Equal(t, 1500*time.Millisecond, d)
+
+	d, err = ParseRequestTimeout("2s")
+	require.NoError(t, err)
+	require.Equal(t, 2*time.Second, d)
+
+	_, err = ParseRequestTimeout("soon")
+	require.Error(t, err)
+}
+
+func TestDeadlineMiddlewareAppliesHeader(t *testing.T) {
+	ctx := deadlineConfigContext("X-Request-Timeout", 0)
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	req.Header.Set("X-Request-Timeout", "200ms")
+
+	var deadline time.Time
+	var ok bool
+	DeadlineMiddleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		deadline, ok = r.Context().Deadline()
+	})).ServeHTTP(httptest.NewRecorder(), req)
+
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now().Add(200*time.Millisecond), deadline, 100*time.Millisecond)
+}
+
+func TestDeadlineMiddlewareWithoutConfig(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Request-Timeout", "200ms")
+
+	var ok bool
+	DeadlineMiddleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		_, ok = r.Context().Deadline()
+	})).ServeHTTP(httptest.NewRecorder(), req)
+
+	require.False(t, ok)
+}
+
+func TestDownstreamBudget(t *testing.T) {
+	ctx := deadlineConfigContext("", 100*time.Millisecond)
+	require.Equal(t, time.Minute, DownstreamBudget(ctx, time.Minute))
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	budget := DownstreamBudget(ctx, time.Minute)
+	require.LessOrEqual(t, budget, 900*time.Millisecond)
+	require.Greater(t, budget, 800*time.Millisecond)
+
+	require.Equal(t, 500*time.Millisecond, DownstreamBudget(ctx, 500*time.Millisecond))
+}
+
+func TestDownstreamTimeoutContextUsesCallerDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(deadlineConfigContext("", 0), 100*time.Millisecond)
+	defer cancel()
+
+	ctx, cancel = Callback{DownstreamTimeout: time.Minute}.DownstreamTimeoutContext(ctx)
+	defer cancel()
+
+	deadline, ok := ctx.Deadline()
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now().Add(100*time.Millisecond), deadline, 50*time.Millisecond)
+}
+
+func TestOutgoingRequestTimeoutHeader(t *testing.T) {
+	ctx := deadlineConfigContext("X-Request-Timeout", 0)
+	_, _, ok := OutgoingRequestTimeoutHeader(ctx)
+	require.False(t, ok)
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	name, value, ok := OutgoingRequestTimeoutHeader(ctx)
+	require.True(t, ok)
+	require.Equal(t, "X-Request-Timeout", name)
+	d, err := ParseRequestTimeout(value)
+	require.NoError(t, err)
+	require.LessOrEqual(t, d, time.Second)
+	require.Greater(t, d, 900*time.Millisecond)
+}
@@ -53,8 +53,10 @@ func (g Callback) HandleError(ctx context.Context, w http.ResponseWriter, kind K
 	g.MapError(ctx, se).WriteError(ctx, w)
 }
 
+// DownstreamTimeoutContext returns a context for downstream calls. The timeout is the smaller of
+// DownstreamTimeout and the remaining time budget of the caller (see DownstreamBudget).
 func (g Callback) DownstreamTimeoutContext(ctx context.Context) (context.Context, context.CancelFunc) {
-	return context.WithTimeout(ctx, g.DownstreamTimeout)
+	return context.WithTimeout(ctx, DownstreamBudget(ctx, g.DownstreamTimeout))
 }
 
 // MapError maps an error to an HTTPError in instances where custom error mapping is required.
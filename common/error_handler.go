@@ -2,6 +2,7 @@ package common
 
 import (
 	"context"
+	"errors"
 	"net/http"
 
 	"github.com/anz-bank/sysl-go/log"
@@ -14,6 +15,7 @@ const (
 	downstreamUnavailable = "Downstream system is unavailable"
 	timeoutDownstream     = "Time out from down stream services"
 	unknownError          = "Unknown Error"
+	requestTooLarge       = "Request body too large"
 )
 
 func HandleError(
@@ -76,6 +78,15 @@ func MapError(ctx context.Context, err error) HTTPError {
 		errorCode, desc string
 	)
 
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return HTTPError{
+			HTTPCode:    http.StatusRequestEntityTooLarge,
+			Code:        "1014",
+			Description: requestTooLarge,
+		}
+	}
+
 	switch e := err.(type) {
 	case ErrorKinder:
 		switch e.ErrorKind() {
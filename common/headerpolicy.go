@@ -0,0 +1,116 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"text/template"
+
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/anz-bank/sysl-go/jwtauth"
+	"github.com/anz-bank/sysl-go/log"
+)
+
+// logLevelHeader is the signed header that sets the log level of a request, see
+// core.LogLevelHeader. It only applies to the service that receives it, so it is never sent to a
+// downstream.
+const logLevelHeader = "Sysl-Log-Level"
+
+// HeaderPolicy decides which headers of an incoming request are sent to a downstream.
+// A nil *HeaderPolicy sends all incoming headers, except the one that sets the log level.
+type HeaderPolicy struct {
+	allow  map[string]struct{}
+	deny   map[string]struct{}
+	rename map[string]string
+	set    map[string]*template.Template
+}
+
+type headerTemplateData struct {
+	Claims  jwtauth.Claims
+	Header  http.Header
+	TraceID string
+}
+
+// NewHeaderPolicy compiles the given propagation config into a HeaderPolicy.
+// It returns nil if cfg is nil.
+func NewHeaderPolicy(cfg *config.HeaderPropagationConfig) (*HeaderPolicy, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	p := &HeaderPolicy{
+		allow:  canonicalSet(cfg.Allow),
+		deny:   canonicalSet(cfg.Deny),
+		rename: make(map[string]string, len(cfg.Rename)),
+		set:    make(map[string]*template.Template, len(cfg.Set)),
+	}
+	for from, to := range cfg.Rename {
+		p.rename[http.CanonicalHeaderKey(from)] = to
+	}
+	for name, text := range cfg.Set {
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid propagation template for header %s: %w", name, err)
+		}
+		p.set[http.CanonicalHeaderKey(name)] = tmpl
+	}
+	return p, nil
+}
+
+// Apply returns the headers to send to the downstream for the given incoming headers.
+// The incoming headers are not modified.
+func (p *HeaderPolicy) Apply(ctx context.Context, incoming http.Header) http.Header {
+	if p == nil {
+		result := incoming.Clone()
+		for name := range result {
+			if http.CanonicalHeaderKey(name) == logLevelHeader {
+				delete(result, name)
+			}
+		}
+		return result
+	}
+	result := make(http.Header, len(incoming)+len(p.set))
+	for name, values := range incoming {
+		name = http.CanonicalHeaderKey(name)
+		if name == logLevelHeader {
+			continue
+		}
+		if _, denied := p.deny[name]; denied {
+			continue
+		}
+		if _, allowed := p.allow[name]; len(p.allow) > 0 && !allowed {
+			continue
+		}
+		if to, has := p.rename[name]; has {
+			name = to
+		}
+		for _, v := range values {
+			result.Add(name, v)
+		}
+	}
+	if len(p.set) > 0 {
+		claims, _ := jwtauth.GetClaimsFromContext(ctx)
+		data := headerTemplateData{
+			Claims:  claims,
+			Header:  incoming,
+			TraceID: GetTraceIDFromContext(ctx).String(),
+		}
+		for name, tmpl := range p.set {
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, data); err != nil {
+				log.Debugf(ctx, "not propagating header %s: %v", name, err)
+				continue
+			}
+			result.Set(name, buf.String())
+		}
+	}
+	return result
+}
+
+func canonicalSet(names []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		set[http.CanonicalHeaderKey(name)] = struct{}{}
+	}
+	return set
+}
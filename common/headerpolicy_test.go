@@ -0,0 +1,83 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/anz-bank/sysl-go/jwtauth"
+	"github.com/anz-bank/sysl-go/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func incomingHeaders() http.Header {
+	return http.Header{
+		"Authorization": {"Bearer abc"},
+		"Cookie":        {"session=1"},
+		"X-Tenant":      {"acme"},
+		"X-Channel":     {"web"},
+	}
+}
+
+func TestHeaderPolicyNilSendsAll(t *testing.T) {
+	var p *HeaderPolicy
+	require.Equal(t, incomingHeaders(), p.Apply(context.Background(), incomingHeaders()))
+
+	p, err := NewHeaderPolicy(nil)
+	require.NoError(t, err)
+	require.Nil(t, p)
+}
+
+func TestHeaderPolicyNeverSendsLogLevel(t *testing.T) {
+	incoming := incomingHeaders()
+	incoming.Set("Sysl-Log-Level", "debug:1700000000:signature")
+	var p *HeaderPolicy
+	require.Equal(t, incomingHeaders(), p.Apply(context.Background(), incoming))
+
+	p, err := NewHeaderPolicy(&config.HeaderPropagationConfig{Allow: []string{"Sysl-Log-Level", "X-Tenant"}})
+	require.NoError(t, err)
+	require.Equal(t, http.Header{"X-Tenant": {"acme"}}, p.Apply(context.Background(), incoming))
+}
+
+func TestHeaderPolicyDeny(t *testing.T) {
+	p, err := NewHeaderPolicy(&config.HeaderPropagationConfig{Deny: []string{"authorization", "COOKIE"}})
+	require.NoError(t, err)
+
+	result := p.Apply(context.Background(), incomingHeaders())
+	require.Equal(t, http.Header{"X-Tenant": {"acme"}, "X-Channel": {"web"}}, result)
+}
+
+func TestHeaderPolicyAllowAndRename(t *testing.T) {
+	p, err := NewHeaderPolicy(&config.HeaderPropagationConfig{
+		Allow:  []string{"x-tenant", "x-channel", "authorization"},
+		Deny:   []string{"authorization"},
+		Rename: map[string]string{"x-channel": "X-Source-Channel"},
+	})
+	require.NoError(t, err)
+
+	result := p.Apply(context.Background(), incomingHeaders())
+	require.Equal(t, http.Header{"X-Tenant": {"acme"}, "X-Source-Channel": {"web"}}, result)
+}
+
+func TestHeaderPolicySetFromTemplate(t *testing.T) {
+	p, err := NewHeaderPolicy(&config.HeaderPropagationConfig{
+		Allow: []string{"x-none"},
+		Set: map[string]string{
+			"x-user":    "{{.Claims.sub}}",
+			"x-tenant":  `{{.Header.Get "X-Tenant"}}`,
+			"x-missing": "{{.Claims.missing}}",
+		},
+	})
+	require.NoError(t, err)
+
+	ctx, _ := testutil.NewTestContextWithLogger()
+	ctx = jwtauth.AddClaimsToContext(ctx, jwtauth.Claims{"sub": "user-1"})
+	result := p.Apply(ctx, incomingHeaders())
+	require.Equal(t, http.Header{"X-User": {"user-1"}, "X-Tenant": {"acme"}}, result)
+}
+
+func TestHeaderPolicyInvalidTemplate(t *testing.T) {
+	_, err := NewHeaderPolicy(&config.HeaderPropagationConfig{Set: map[string]string{"x-user": "{{.Claims"}})
+	require.Error(t, err)
+}
@@ -80,8 +80,14 @@ func (r *nopLogger) ResponseWriter(base http.ResponseWriter) http.ResponseWriter
 func (r *nopLogger) FlushLog()                                                   {}
 
 func NewRequestLogger(ctx context.Context, req *http.Request) (RequestLogger, context.Context) {
-	cfg := config.GetDefaultConfig(ctx)
-	if cfg != nil && cfg.Library.Log.LogPayload {
+	// the logging of payloads can be changed at runtime through the level controller
+	logPayload := false
+	if c := log.GetLevelController(ctx); c != nil {
+		logPayload = c.LogPayload()
+	} else if cfg := config.GetDefaultConfig(ctx); cfg != nil {
+		logPayload = cfg.Library.Log.LogPayload
+	}
+	if logPayload {
 		l := &requestLogger{
 			ctx:        InitFieldsFromRequest(ctx, req),
 			protoMajor: req.ProtoMajor,
@@ -0,0 +1,216 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/anz-bank/sysl-go/log"
+)
+
+// BalancingRoundTripper sends each request to one of several endpoints of a downstream. The
+// scheme and host of the request URL are replaced with those of the selected endpoint.
+type BalancingRoundTripper struct {
+	base      http.RoundTripper
+	policy    config.LoadBalancingPolicy
+	ejection  *config.OutlierEjectionConfig
+	endpoints []*balancedEndpoint
+
+	mu   sync.Mutex
+	next int
+}
+
+type balancedEndpoint struct {
+	url    *url.URL
+	weight int
+
+	// the fields below are guarded by BalancingRoundTripper.mu
+	outstanding  int
+	failures     int
+	ejectedUntil time.Time
+	unhealthy    bool
+	current      int // smooth weighted round-robin state
+}
+
+// idleConnectionCloser is implemented by *http.Transport.
+type idleConnectionCloser interface {
+	CloseIdleConnections()
+}
+
+// NewBalancingRoundTripper returns a round-tripper that balances requests across the endpoints
+// of cfg using base to send them. Health probing and DNS re-resolution, when configured, run
+// until ctx is done.
+func NewBalancingRoundTripper(ctx context.Context, cfg *config.LoadBalancingConfig, base http.RoundTripper) (*BalancingRoundTripper, error) {
+	if len(cfg.Endpoints) == 0 {
+		return nil, fmt.Errorf("load balancing requires at least one endpoint")
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	b := &BalancingRoundTripper{
+		base:     base,
+		policy:   cfg.Policy,
+		ejection: cfg.OutlierEjection,
+	}
+	for _, e := range cfg.Endpoints {
+		u, err := url.Parse(e.Address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint %s: %w", e.Address, err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return nil, fmt.Errorf("invalid endpoint %s: expected an absolute URL", e.Address)
+		}
+		weight := e.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		b.endpoints = append(b.endpoints, &balancedEndpoint{url: u, weight: weight})
+	}
+
+	if cfg.HealthCheck != nil {
+		go b.probe(ctx, cfg.HealthCheck)
+	}
+	if closer, ok := base.(idleConnectionCloser); ok && cfg.ResolveInterval > 0 {
+		// Connections are kept alive, so DNS is only resolved again when a new connection
+		// is made. Dropping idle connections regularly makes sure that happens.
+		go every(ctx, cfg.ResolveInterval, closer.CloseIdleConnections)
+	}
+	return b, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (b *BalancingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	e := b.pick()
+	defer b.done(e)
+
+	r := req.Clone(req.Context())
+	r.URL.Scheme = e.url.Scheme
+	r.URL.Host = e.url.Host
+	r.Host = ""
+
+	resp, err := b.base.RoundTrip(r)
+	b.record(e, err == nil && resp.StatusCode < http.StatusInternalServerError)
+	return resp, err
+}
+
+// pick selects the endpoint for the next request and counts it as outstanding.
+func (b *BalancingRoundTripper) pick() *balancedEndpoint {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	available := make([]*balancedEndpoint, 0, len(b.endpoints))
+	for _, e := range b.endpoints {
+		if !e.unhealthy && !now.Before(e.ejectedUntil) {
+			available = append(available, e)
+		}
+	}
+	if len(available) == 0 {
+		// Better to try an endpoint that might be down than to fail every request.
+		available = b.endpoints
+	}
+
+	var chosen *balancedEndpoint
+	switch b.policy {
+	case config.LeastOutstanding:
+		start := b.next % len(available)
+		for i := range available {
+			e := available[(start+i)%len(available)]
+			if chosen == nil || e.outstanding < chosen.outstanding {
+				chosen = e
+			}
+		}
+		b.next++
+	case config.Weighted:
+		total := 0
+		for _, e := range available {
+			e.current += e.weight
+			total += e.weight
+			if chosen == nil || e.current > chosen.current {
+				chosen = e
+			}
+		}
+		chosen.current -= total
+	default:
+		chosen = available[b.next%len(available)]
+		b.next++
+	}
+	chosen.outstanding++
+	return chosen
+}
+
+func (b *BalancingRoundTripper) done(e *balancedEndpoint) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	e.outstanding--
+}
+
+// record updates the outlier ejection state of the endpoint with the outcome of a request.
+func (b *BalancingRoundTripper) record(e *balancedEndpoint, ok bool) {
+	if b.ejection == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ok {
+		e.failures = 0
+		return
+	}
+	e.failures++
+	if e.failures >= b.ejection.GetConsecutiveFailures() {
+		e.failures = 0
+		e.ejectedUntil = time.Now().Add(b.ejection.GetEjectionTime())
+	}
+}
+
+func (b *BalancingRoundTripper) probe(ctx context.Context, cfg *config.EndpointHealthCheckConfig) {
+	check := func() {
+		for _, e := range b.endpoints {
+			healthy := b.probeEndpoint(ctx, e, cfg)
+			b.mu.Lock()
+			changed := e.unhealthy == healthy
+			e.unhealthy = !healthy
+			b.mu.Unlock()
+			if changed {
+				log.Infof(ctx, "endpoint %s is now healthy=%t", e.url.Host, healthy)
+			}
+		}
+	}
+	check()
+	every(ctx, cfg.GetInterval(), check)
+}
+
+func (b *BalancingRoundTripper) probeEndpoint(ctx context.Context, e *balancedEndpoint, cfg *config.EndpointHealthCheckConfig) bool {
+	ctx, cancel := context.WithTimeout(ctx, cfg.GetTimeout())
+	defer cancel()
+	target := *e.url
+	target.Path = cfg.Path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return false
+	}
+	resp, err := b.base.RoundTrip(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode >= 200 && resp.StatusCode < 400
+}
+
+// every calls fn at the given interval until ctx is done.
+func every(ctx context.Context, interval time.Duration, fn func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			fn()
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/anz-bank/sysl-go/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newCountingServer(t *testing.T, status int, hits *atomic.Int32) *httptest.Server {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func doBalanced(t *testing.T, client *http.Client, n int) {
+	for i := 0; i < n; i++ {
+		resp, err := client.Get("http://downstream/path")
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+}
+
+func TestBalancingRoundTripper_RoundRobin(t *testing.T) {
+	var a, b atomic.Int32
+	sa := newCountingServer(t, http.StatusOK, &a)
+	sb := newCountingServer(t, http.StatusOK, &b)
+
+	rt, err := NewBalancingRoundTripper(context.Background(), &config.LoadBalancingConfig{
+		Endpoints: []config.EndpointConfig{{Address: sa.URL}, {Address: sb.URL}},
+	}, http.DefaultTransport)
+	require.NoError(t, err)
+
+	doBalanced(t, &http.Client{Transport: rt}, 4)
+	require.Equal(t, int32(2), a.Load())
+	require.Equal(t, int32(2), b.Load())
+}
+
+func TestBalancingRoundTripper_Weighted(t *testing.T) {
+	var a, b atomic.Int32
+	sa := newCountingServer(t, http.StatusOK, &a)
+	sb := newCountingServer(t, http.StatusOK, &b)
+
+	rt, err := NewBalancingRoundTripper(context.Background(), &config.LoadBalancingConfig{
+		Policy:    config.Weighted,
+		Endpoints: []config.EndpointConfig{{Address: sa.URL, Weight: 3}, {Address: sb.URL, Weight: 1}},
+	}, http.DefaultTransport)
+	require.NoError(t, err)
+
+	doBalanced(t, &http.Client{Transport: rt}, 8)
+	require.Equal(t, int32(6), a.Load())
+	require.Equal(t, int32(2), b.Load())
+}
+
+func TestBalancingRoundTripper_OutlierEjection(t *testing.T) {
+	var a, b atomic.Int32
+	sa := newCountingServer(t, http.StatusInternalServerError, &a)
+	sb := newCountingServer(t, http.StatusOK, &b)
+
+	rt, err := NewBalancingRoundTripper(context.Background(), &config.LoadBalancingConfig{
+		Endpoints:       []config.EndpointConfig{{Address: sa.URL}, {Address: sb.URL}},
+		OutlierEjection: &config.OutlierEjectionConfig{ConsecutiveFailures: 1, EjectionTime: time.Minute},
+	}, http.DefaultTransport)
+	require.NoError(t, err)
+
+	doBalanced(t, &http.Client{Transport: rt}, 5)
+	require.Equal(t, int32(1), a.Load())
+	require.Equal(t, int32(4), b.Load())
+}
+
+func TestBalancingRoundTripper_HealthCheck(t *testing.T) {
+	ctx, cancel := context.WithCancel(testutil.NewTestContext())
+	defer cancel()
+
+	var a, b atomic.Int32
+	sa := newCountingServer(t, http.StatusServiceUnavailable, &a)
+	sb := newCountingServer(t, http.StatusOK, &b)
+
+	rt, err := NewBalancingRoundTripper(ctx, &config.LoadBalancingConfig{
+		Endpoints:   []config.EndpointConfig{{Address: sa.URL}, {Address: sb.URL}},
+		HealthCheck: &config.EndpointHealthCheckConfig{Path: "/-/ready", Interval: time.Hour},
+	}, http.DefaultTransport)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		rt.mu.Lock()
+		defer rt.mu.Unlock()
+		return rt.endpoints[0].unhealthy
+	}, time.Second, 10*time.Millisecond)
+
+	a.Store(0)
+	doBalanced(t, &http.Client{Transport: rt}, 3)
+	require.Equal(t, int32(0), a.Load())
+}
+
+func TestNewBalancingRoundTripper_InvalidEndpoint(t *testing.T) {
+	_, err := NewBalancingRoundTripper(context.Background(), &config.LoadBalancingConfig{
+		Endpoints: []config.EndpointConfig{{Address: "localhost:8080"}},
+	}, nil)
+	require.Error(t, err)
+}
@@ -53,6 +53,15 @@ func TryGetTraceIDFromContext(ctx context.Context) (uuid.UUID, bool) {
 	return uuid.New(), false
 }
 
+// LookupTraceIDFromContext returns the trace ID of the context and whether the context has one.
+func LookupTraceIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	val, ok := ctx.Value(traceabilityContextKey{}).(*requestID)
+	if ok {
+		return val.id, true
+	}
+	return uuid.UUID{}, false
+}
+
 func AddTraceIDToContext(ctx context.Context, id uuid.UUID, wasProvided bool) context.Context {
 	return context.WithValue(ctx, traceabilityContextKey{}, &requestID{id, wasProvided})
 }
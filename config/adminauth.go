@@ -0,0 +1,57 @@
+package config
+
+// The groups of admin routes, each of which can have its own AdminAuthRule.
+const (
+	AdminRouteStatus    = "status"    // /-/status and /-/version
+	AdminRouteMetrics   = "metrics"   // /-/metrics
+	AdminRouteHealth    = "health"    // the health endpoints
+	AdminRouteProfiling = "profiling" // the pprof handlers
+	AdminRouteConfig    = "config"    // /-/config
+	AdminRouteLog       = "log"       // /-/log
+	AdminRouteTemporal  = "temporal"  // /-/temporal
+	AdminRouteInventory = "inventory" // /-/routes and /-/downstreams
+	AdminRouteDebug     = "debug"     // /-/debug
+)
+
+// AdminAuthConfig configures the authorization of the requests to the admin server.
+//
+// Each group of admin routes is authorized by its rule in Routes, or else by the Default rule.
+// The groups without either rule keep their behaviour without admin.auth: config, log,
+// inventory and debug are only served to the requests authorized by
+// Hooks.AuthorizeAdminRequest, and the others are open.
+type AdminAuthConfig struct {
+	Default *AdminAuthRule `yaml:"default" mapstructure:"default"`
+	// Routes are the rules by group of admin routes, e.g. status, metrics, health, profiling,
+	// config, log, temporal, inventory and debug.
+	Routes map[string]AdminAuthRule `yaml:"routes" mapstructure:"routes" validate:"dive,keys,oneof=status metrics health profiling config log temporal inventory debug,endkeys"`
+}
+
+// AdminAuthRule authorizes a request if it is Open, or if it is authenticated by any of the
+// configured methods, or by Hooks.AuthorizeAdminRequest if it is set. A rule that is neither
+// open nor has a method only serves the requests authorized by the hook.
+type AdminAuthRule struct {
+	// Open serves every request, without authentication.
+	Open bool `yaml:"open" mapstructure:"open"`
+	// MTLS authenticates the verified TLS client certificates of the requests, which needs
+	// admin.http.common.tls.clientAuth to verify them.
+	MTLS *AdminMTLSAuth `yaml:"mtls" mapstructure:"mtls"`
+	// JWT authenticates the bearer tokens of the requests with library.authentication.jwtauth
+	// and authorizes their claims with an authorization rule expression, e.g.
+	// jwtHasScope("admin").
+	JWT string `yaml:"jwt" mapstructure:"jwt"`
+	// Basic authenticates the basic auth credentials of the requests.
+	Basic *AdminBasicAuth `yaml:"basic" mapstructure:"basic"`
+}
+
+// AdminMTLSAuth authenticates the TLS client certificates of requests.
+type AdminMTLSAuth struct {
+	// Identities are the subject common names, DNS names or URIs of the client certificates
+	// that are authenticated, all of them if empty.
+	Identities []string `yaml:"identities" mapstructure:"identities"`
+}
+
+// AdminBasicAuth authenticates the basic auth credentials of requests.
+type AdminBasicAuth struct {
+	Username string          `yaml:"username" mapstructure:"username" validate:"required"`
+	Password SensitiveString `yaml:"password" mapstructure:"password" validate:"required"`
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/anz-bank/sysl-go/validator"
+)
+
+func TestAdminAuthConfigValidation(t *testing.T) {
+	t.Parallel()
+
+	valid := AdminAuthConfig{Routes: map[string]AdminAuthRule{
+		AdminRouteMetrics: {Open: true},
+		AdminRouteConfig:  {Basic: &AdminBasicAuth{Username: "admin", Password: NewSensitiveString("secret")}},
+	}}
+	require.NoError(t, validator.Validate(valid))
+
+	unknown := AdminAuthConfig{Routes: map[string]AdminAuthRule{"pprof": {Open: true}}}
+	require.Error(t, validator.Validate(unknown))
+
+	noUsername := AdminAuthConfig{Default: &AdminAuthRule{Basic: &AdminBasicAuth{Password: NewSensitiveString("secret")}}}
+	require.Error(t, validator.Validate(noUsername))
+
+	noPassword := AdminAuthConfig{Default: &AdminAuthRule{Basic: &AdminBasicAuth{Username: "admin"}}}
+	require.Error(t, validator.Validate(noPassword))
+}
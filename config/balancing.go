@@ -0,0 +1,108 @@
+package config
+
+import (
+	"time"
+)
+
+// LoadBalancingPolicy names the way an endpoint is selected for each request.
+type LoadBalancingPolicy string
+
+const (
+	// RoundRobin sends requests to each endpoint in turn. It is the default policy.
+	RoundRobin LoadBalancingPolicy = "roundRobin"
+	// LeastOutstanding sends requests to the endpoint with the fewest requests in flight.
+	LeastOutstanding LoadBalancingPolicy = "leastOutstanding"
+	// Weighted sends requests to each endpoint in proportion to its weight. It is not supported
+	// for gRPC downstreams.
+	Weighted LoadBalancingPolicy = "weighted"
+)
+
+// LoadBalancingConfig spreads the requests to a downstream across several endpoints.
+// When set, the endpoints take the place of the single serviceURL (HTTP) or serviceAddress
+// (gRPC) of the downstream.
+type LoadBalancingConfig struct {
+	// Endpoints lists the endpoints of the downstream. For HTTP downstreams each address is a
+	// base URL such as https://host:port, for gRPC downstreams it is host:port.
+	Endpoints []EndpointConfig `yaml:"endpoints" mapstructure:"endpoints" validate:"min=1,dive"`
+
+	// Policy is one of roundRobin (default), leastOutstanding or weighted (HTTP only).
+	Policy LoadBalancingPolicy `yaml:"policy" mapstructure:"policy" validate:"omitempty,oneof=roundRobin leastOutstanding weighted"`
+
+	// OutlierEjection temporarily stops sending requests to endpoints that keep failing (HTTP
+	// only). gRPC downstreams skip endpoints while their connection is down instead.
+	OutlierEjection *OutlierEjectionConfig `yaml:"outlierEjection" mapstructure:"outlierEjection"`
+
+	// HealthCheck actively probes the endpoints and skips those that are unhealthy.
+	HealthCheck *EndpointHealthCheckConfig `yaml:"healthCheck" mapstructure:"healthCheck"`
+
+	// ResolveInterval is how often the host names of the endpoints are resolved again, so that
+	// DNS changes are picked up. Zero leaves it to the transport.
+	ResolveInterval time.Duration `yaml:"resolveInterval" mapstructure:"resolveInterval" validate:"min=0"`
+}
+
+// EndpointConfig is a single endpoint of a downstream.
+type EndpointConfig struct {
+	Address string `yaml:"address" mapstructure:"address" validate:"required"`
+	// Weight is used by the weighted policy. Defaults to 1.
+	Weight int `yaml:"weight" mapstructure:"weight" validate:"min=0"`
+}
+
+// OutlierEjectionConfig configures passive outlier ejection. An endpoint is ejected after a
+// number of consecutive failures (transport errors or 5xx responses) and is used again once
+// the ejection time has passed.
+type OutlierEjectionConfig struct {
+	// ConsecutiveFailures defaults to 5.
+	ConsecutiveFailures int `yaml:"consecutiveFailures" mapstructure:"consecutiveFailures" validate:"min=0"`
+	// EjectionTime defaults to 30s.
+	EjectionTime time.Duration `yaml:"ejectionTime" mapstructure:"ejectionTime" validate:"min=0"`
+}
+
+// EndpointHealthCheckConfig configures active health probing of endpoints.
+type EndpointHealthCheckConfig struct {
+	// Path is the path probed on each HTTP endpoint, e.g. /-/ready. For gRPC downstreams it is
+	// the service name passed to the standard grpc health service (empty for the whole server).
+	Path string `yaml:"path" mapstructure:"path"`
+	// Interval defaults to 10s (HTTP only).
+	Interval time.Duration `yaml:"interval" mapstructure:"interval" validate:"min=0"`
+	// Timeout defaults to 2s (HTTP only).
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout" validate:"min=0"`
+}
+
+const (
+	defaultConsecutiveFailures = 5
+	defaultEjectionTime        = 30 * time.Second
+	defaultHealthCheckInterval = 10 * time.Second
+	defaultHealthCheckTimeout  = 2 * time.Second
+)
+
+// GetConsecutiveFailures returns the number of consecutive failures that eject an endpoint.
+func (c *OutlierEjectionConfig) GetConsecutiveFailures() int {
+	if c.ConsecutiveFailures <= 0 {
+		return defaultConsecutiveFailures
+	}
+	return c.ConsecutiveFailures
+}
+
+// GetEjectionTime returns how long an endpoint stays ejected.
+func (c *OutlierEjectionConfig) GetEjectionTime() time.Duration {
+	if c.EjectionTime <= 0 {
+		return defaultEjectionTime
+	}
+	return c.EjectionTime
+}
+
+// GetInterval returns the time between health probes.
+func (c *EndpointHealthCheckConfig) GetInterval() time.Duration {
+	if c.Interval <= 0 {
+		return defaultHealthCheckInterval
+	}
+	return c.Interval
+}
+
+// GetTimeout returns the time allowed for a single health probe.
+func (c *EndpointHealthCheckConfig) GetTimeout() time.Duration {
+	if c.Timeout <= 0 {
+		return defaultHealthCheckTimeout
+	}
+	return c.Timeout
+}
@@ -2,21 +2,19 @@ package config
 
 import (
 	"context"
-
-	"github.com/anz-bank/sysl-go/validator"
 )
 
 type defaultConfigKey struct{}
 
 type DefaultConfig struct {
-	Library LibraryConfig `yaml:"library" mapstructure:"library"`
+	Library LibraryConfig `yaml:"library" mapstructure:"library" description:"The config of the library features of the service."`
 
 	// config used for setting up the sysl-go admin server
-	Admin   *AdminConfig  `yaml:"admin" mapstructure:"admin"`
-	GenCode GenCodeConfig `yaml:"genCode" mapstructure:"genCode"`
+	Admin   *AdminConfig  `yaml:"admin" mapstructure:"admin" description:"The sysl-go admin server, which is not started if it is not set."`
+	GenCode GenCodeConfig `yaml:"genCode" mapstructure:"genCode" description:"The upstream servers and downstream clients of the generated code."`
 
 	// development config can be used to set some config options only appropriate for dev/test environments.
-	Development *DevelopmentConfig `yaml:"development" mapstructure:"development"`
+	Development *DevelopmentConfig `yaml:"development" mapstructure:"development" description:"Options only appropriate for development and test environments."`
 }
 
 // GetDefaultConfig retrieves the externally-provided config from the context.
@@ -37,26 +35,20 @@ func PutDefaultConfig(ctx context.Context, config *DefaultConfig) context.Contex
 // defaultConfig: a pointer to the default config struct to populate
 // customConfig: a pointer to the custom config struct to populate.
 func LoadConfig(file string, defaultConfig *DefaultConfig, customConfig interface{}) error {
-	b := NewConfigReaderBuilder().WithConfigFile(file).WithDefaults(SetDefaults)
-	err := b.Build().Unmarshal(defaultConfig)
+	reader, err := NewConfigReaderBuilder().WithConfigFile(file).WithDefaults(SetDefaults).Build()
 	if err != nil {
 		return err
 	}
-	err = b.Build().Unmarshal(customConfig)
-	if err != nil {
+	if err = reader.Unmarshal(defaultConfig); err != nil {
 		return err
 	}
-	err = validator.Validate(defaultConfig)
-	if err != nil {
+	if err = reader.Unmarshal(customConfig); err != nil {
 		return err
 	}
-
-	err = validator.Validate(customConfig)
-	if err != nil {
+	if err = reader.Validate(defaultConfig); err != nil {
 		return err
 	}
-
-	return err
+	return reader.Validate(customConfig)
 }
 
 func SetDefaults(setter func(key string, value interface{})) {
@@ -73,6 +73,8 @@ func TestLoadInvalidConfig(t *testing.T) {
 	myConfig := TestMyConfig{}
 	err := LoadConfig("testdata/config_invalid.yaml", &defaultConfig, &myConfig)
 
-	_, ok := err.(validator.ValidationErrors)
-	require.True(t, ok)
+	var fieldErr validator.FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	require.EqualError(t, err, "invalid configuration: testdata/config_invalid.yaml:34:7: "+
+		"genCode.downstream.foo.clientTimeout: failed on the 'timeout=1ms:60s' validation")
 }
@@ -11,6 +11,12 @@ type ConfigReader interface { //nolint:revive
 
 	// Unmarshal deserializes the loaded cofig into a struct.
 	Unmarshal(config interface{}) error
+
+	// Validate validates the config deserialized by Unmarshal, locating each error at its key.
+	Validate(config interface{}) error
+
+	// Source returns where the value of the key comes from, or false if the key has no value.
+	Source(key string) (Source, bool)
 }
 
 // NilValueError is raised when the key value is nil.
@@ -1,7 +1,6 @@
 package config
 
 import (
-	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -56,7 +55,8 @@ func TestGetStringFromFile(t *testing.T) {
 	t.Parallel()
 
 	b := NewConfigReaderBuilder()
-	reader := b.AttachEnvPrefix("simpleApp").WithConfigFile("testdata/config.yaml").Build()
+	reader, err := b.AttachEnvPrefix("simpleApp").WithConfigFile("testdata/config.yaml").Build()
+	require.NoError(t, err)
 	fooURL, err := reader.GetString("genCode.downstream.foo.serviceURL")
 	require.NoError(t, err)
 	assert.Equal(t, "https://foo.example.com", fooURL)
@@ -66,7 +66,8 @@ func TestGetStringErr(t *testing.T) {
 	t.Parallel()
 
 	b := NewConfigReaderBuilder()
-	reader := b.AttachEnvPrefix("simpleApp").WithConfigFile("testdata/config.yaml").Build()
+	reader, err := b.AttachEnvPrefix("simpleApp").WithConfigFile("testdata/config.yaml").Build()
+	require.NoError(t, err)
 	s, err := reader.GetString("genCode.downstream.foo")
 	require.NotNil(t, err)
 	assert.Equal(t, "", s)
@@ -76,7 +77,8 @@ func TestGetStringFromEnv(t *testing.T) {
 	t.Parallel()
 
 	b := NewConfigReaderBuilder()
-	reader := b.AttachEnvPrefix("simple").WithConfigFile("testdata/config.yaml").Build()
+	reader, err := b.AttachEnvPrefix("simple").WithConfigFile("testdata/config.yaml").Build()
+	require.NoError(t, err)
 	os.Setenv("SIMPLE_GENCODE_DOWNSTREAM_FOO_SERVICEURL", "https://env.foo.example.com")
 	fooURL, err := reader.GetString("genCode.downstream.foo.serviceURL")
 	require.NoError(t, err)
@@ -87,7 +89,8 @@ func TestGetStringFrom2ndSource(t *testing.T) {
 	t.Parallel()
 
 	b := NewConfigReaderBuilder()
-	reader := b.AttachEnvPrefix("simple").WithConfigFile("testdata/config.yaml").Build()
+	reader, err := b.AttachEnvPrefix("simple").WithConfigFile("testdata/config.yaml").Build()
+	require.NoError(t, err)
 	os.Setenv("SIMPLE_GENCODE_DOWNSTREAM_BAR_SERVICEURL", "")
 	barURL, err := reader.GetString("genCode.downstream.bar.serviceURL")
 	require.NoError(t, err)
@@ -98,8 +101,9 @@ func TestGetMultipleConfigFiles(t *testing.T) {
 	t.Parallel()
 
 	b := NewConfigReaderBuilder()
-	reader := b.AttachEnvPrefix("simple").WithConfigFile("testdata/config.yaml").WithConfigName(
+	reader, err := b.AttachEnvPrefix("simple").WithConfigFile("testdata/config.yaml").WithConfigName(
 		"config_log", "./", "testdata").Build()
+	require.NoError(t, err)
 	calleeLog, err := reader.GetString("library.log.callee")
 	require.NoError(t, err)
 	assert.Equal(t, "true", calleeLog)
@@ -110,13 +114,15 @@ func TestUnmarshalFromFileWithPrefix(t *testing.T) {
 
 	conf := config{}
 	b := NewConfigReaderBuilder().WithFs(afero.NewOsFs()).WithConfigFile("testdata/config.yaml")
-	fooURL, err := b.Build().GetString("genCode.downstream.foo.serviceURL")
+	reader, err := b.Build()
+	require.NoError(t, err)
+	fooURL, err := reader.GetString("genCode.downstream.foo.serviceURL")
 	require.NoError(t, err)
 	assert.Equal(t, "https://foo.example.com", fooURL)
 	os.Setenv("ENV_GENCODE_DOWNSTREAM_FOO_SERVICEURL", "https://env.foo.example.com")
 	os.Setenv("ENV_GENCODE_DOWNSTREAM_BAR_SERVICEURL", "https://env.bar.example.com")
 	b.AttachEnvPrefix("env")
-	err = b.Build().Unmarshal(&conf)
+	err = reader.Unmarshal(&conf)
 	require.NoError(t, err)
 	assert.Equal(t, "https://env.foo.example.com", conf.Gencode.Downstream.Foo.ServiceURL)
 	assert.Equal(t, "https://env.bar.example.com", conf.Gencode.Downstream.Bar.ServiceURL)
@@ -127,8 +133,9 @@ func TestUnmarshalFromFile(t *testing.T) {
 
 	conf := config{}
 	b := NewConfigReaderBuilder()
-	reader := b.WithFs(afero.NewOsFs()).WithConfigFile("testdata/config.yaml").Build()
-	err := reader.Unmarshal(&conf)
+	reader, err := b.WithFs(afero.NewOsFs()).WithConfigFile("testdata/config.yaml").Build()
+	require.NoError(t, err)
+	err = reader.Unmarshal(&conf)
 	require.NoError(t, err)
 	assert.Equal(t, "https://foo.example.com", conf.Gencode.Downstream.Foo.ServiceURL)
 	assert.Equal(t, "https://bar.example.com", conf.Gencode.Downstream.Bar.ServiceURL)
@@ -149,7 +156,8 @@ func TestUnmarshalSensitiveStringFromFile(t *testing.T) {
 		[]byte("path: testdata\npassword1: pwd1\npassword2: pwd2"), 0644)
 	require.NoError(t, err)
 	b := NewConfigReaderBuilder()
-	reader := b.WithFs(fs).WithConfigFile("sensitive_string_config.yaml").Build()
+	reader, err := b.WithFs(fs).WithConfigFile("sensitive_string_config.yaml").Build()
+	require.NoError(t, err)
 	err = reader.Unmarshal(&conf)
 	require.NoError(t, err)
 	assert.Equal(t, "testdata", *conf.Path)
@@ -158,6 +166,24 @@ func TestUnmarshalSensitiveStringFromFile(t *testing.T) {
 	assert.Equal(t, "pwd2", conf.Password2.Value())
 }
 
+func TestUnmarshalLogLevelNamesOfOtherTypes(t *testing.T) {
+	t.Parallel()
+
+	conf := struct {
+		Level  log.Level       `mapstructure:"level"`
+		Name   string          `mapstructure:"name"`
+		Routes map[string]bool `mapstructure:"routes"`
+	}{}
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "config.yaml", []byte("level: debug\nname: info\nroutes:\n  debug: true"), 0644))
+	reader, err := NewConfigReaderBuilder().WithFs(fs).WithConfigFile("config.yaml").Build()
+	require.NoError(t, err)
+	require.NoError(t, reader.Unmarshal(&conf))
+	assert.Equal(t, log.DebugLevel, conf.Level)
+	assert.Equal(t, "info", conf.Name)
+	assert.Contains(t, conf.Routes, "debug")
+}
+
 func TestUnmarshalFromFileWithStrictMode(t *testing.T) {
 	t.Parallel()
 
@@ -193,7 +219,7 @@ func TestUnmarshalFromFileWithStrictMode(t *testing.T) {
 			name:           "strict-mode-enabled",
 			b:              NewConfigReaderBuilder().WithStrictMode(true),
 			expectedConfig: DemoConfig{Barr: 456},
-			expectedErr:    fmt.Errorf("Misconfiguration error: found unexpected config key(s): foo"),
+			expectedErr:    Errors{&Error{Key: "foo", File: "a.yaml", Line: 1, Column: 1, Err: ErrUnexpectedKey}},
 		},
 		{
 			name:           "strict-mode-enabled-with-exception-ignored",
@@ -211,7 +237,7 @@ func TestUnmarshalFromFileWithStrictMode(t *testing.T) {
 			name:           "strict-mode-enabled-with-some-other-exception-ignored",
 			b:              NewConfigReaderBuilder().WithStrictMode(true, "fib"),
 			expectedConfig: DemoConfig{Barr: 456},
-			expectedErr:    fmt.Errorf("Misconfiguration error: found unexpected config key(s): foo"),
+			expectedErr:    Errors{&Error{Key: "foo", File: "a.yaml", Line: 1, Column: 1, Err: ErrUnexpectedKey}},
 		},
 	}
 
@@ -221,11 +247,159 @@ func TestUnmarshalFromFileWithStrictMode(t *testing.T) {
 			t.Parallel()
 
 			conf := DemoConfig{}
-			reader := s.b.WithFs(fs).WithConfigFile("a.yaml").Build()
-			err := reader.Unmarshal(&conf)
+			reader, err := s.b.WithFs(fs).WithConfigFile("a.yaml").Build()
+			require.NoError(t, err)
+			err = reader.Unmarshal(&conf)
 
 			require.Equal(t, s.expectedConfig, conf)
 			require.Equal(t, s.expectedErr, err)
 		})
 	}
 }
+
+func newLayeredConfigFs(t *testing.T) afero.Fs {
+	fs := afero.NewMemMapFs()
+	for name, content := range map[string]string{
+		"conf/base.yaml":       "include: common/tls.yaml\nfoo: base\nbar: base\nbaz: base",
+		"conf/common/tls.yaml": "foo: tls\ntls: common",
+		"conf/base.prod.yaml":  "bar: prod",
+		"conf/local.yaml":      "baz: local",
+		"conf/local.dev.yaml":  "baz: dev",
+		"conf/profiled.yaml":   "profile: prod\nfoo: profiled",
+	} {
+		require.NoError(t, afero.WriteFile(fs, name, []byte(content), 0644))
+	}
+	return fs
+}
+
+func TestUnmarshalLayeredConfigFiles(t *testing.T) {
+	t.Parallel()
+
+	type DemoConfig struct {
+		Foo string `mapstructure:"foo"`
+		Bar string `mapstructure:"bar"`
+		Baz string `mapstructure:"baz"`
+		TLS string `mapstructure:"tls"`
+	}
+
+	fs := newLayeredConfigFs(t)
+	scenarios := []struct {
+		name     string
+		b        ConfigReaderBuilder
+		expected DemoConfig
+	}{
+		{
+			name:     "include",
+			b:        NewConfigReaderBuilder().WithFs(fs).WithConfigFiles("conf/base.yaml"),
+			expected: DemoConfig{Foo: "base", Bar: "base", Baz: "base", TLS: "common"},
+		},
+		{
+			name:     "overlay",
+			b:        NewConfigReaderBuilder().WithFs(fs).WithConfigFiles("conf/base.yaml", "conf/local.yaml"),
+			expected: DemoConfig{Foo: "base", Bar: "base", Baz: "local", TLS: "common"},
+		},
+		{
+			name:     "profiles",
+			b:        NewConfigReaderBuilder().WithFs(fs).WithConfigFiles("conf/base.yaml", "conf/local.yaml").WithProfiles("prod,dev"),
+			expected: DemoConfig{Foo: "base", Bar: "prod", Baz: "dev", TLS: "common"},
+		},
+		{
+			name:     "profile-key",
+			b:        NewConfigReaderBuilder().WithFs(fs).WithConfigFiles("conf/base.yaml", "conf/profiled.yaml").WithProfiles(),
+			expected: DemoConfig{Foo: "profiled", Bar: "prod", Baz: "base", TLS: "common"},
+		},
+		{
+			name: "defaults-and-overrides",
+			b: NewConfigReaderBuilder().WithFs(fs).WithDefaults(func(set func(string, interface{})) {
+				set("foo", "default")
+				set("qux", "default")
+			}).WithConfigFiles("conf/local.yaml").WithOverride("baz", "override"),
+			expected: DemoConfig{Foo: "default", Baz: "override"},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := DemoConfig{}
+			reader, err := s.b.Build()
+			require.NoError(t, err)
+			require.NoError(t, reader.Unmarshal(&conf))
+			require.Equal(t, s.expected, conf)
+		})
+	}
+}
+
+func TestUnmarshalLayeredConfigFilesWithStrictMode(t *testing.T) {
+	t.Parallel()
+
+	type DemoConfig struct {
+		Foo string `mapstructure:"foo"`
+		Bar string `mapstructure:"bar"`
+		Baz string `mapstructure:"baz"`
+	}
+
+	// the include key is not part of the config, unlike the unknown key of the included file
+	fs := newLayeredConfigFs(t)
+	conf := DemoConfig{}
+	reader, err := NewConfigReaderBuilder().WithFs(fs).WithStrictMode(true).WithConfigFiles("conf/base.yaml", "conf/local.yaml").Build()
+	require.NoError(t, err)
+	require.EqualError(t, reader.Unmarshal(&conf), "invalid configuration: conf/common/tls.yaml:2:1: tls: unexpected config key")
+
+	reader, err = NewConfigReaderBuilder().WithFs(fs).WithStrictMode(true, "tls").WithConfigFiles("conf/base.yaml", "conf/local.yaml").Build()
+	require.NoError(t, err)
+	require.NoError(t, reader.Unmarshal(&conf))
+}
+
+func TestMergeLayerErrors(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "a.yaml", []byte("include: [b.yaml]"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "b.yaml", []byte("include: a.yaml"), 0644))
+
+	r := NewConfigReaderBuilder().evarReader
+	require.EqualError(t, mergeLayer(r.envVars, r.sources, fs, "a.yaml", nil), "config include cycle: a.yaml -> b.yaml -> a.yaml")
+	require.ErrorContains(t, mergeLayer(r.envVars, r.sources, fs, "missing.yaml", nil), "failed to read config file missing.yaml")
+	require.EqualError(t, mergeProfiles(r.envVars, r.sources, fs, []string{"a.yaml"}, []string{"prod"}), `no config file for profile "prod"`)
+}
+
+func TestUnmarshalAndValidateErrors(t *testing.T) {
+	t.Parallel()
+
+	type Backend struct {
+		URL     string        `mapstructure:"url" validate:"required"`
+		Timeout time.Duration `mapstructure:"timeout"`
+	}
+	type DemoConfig struct {
+		Backends map[string]Backend `mapstructure:"backends" validate:"dive"`
+		Ports    []int              `mapstructure:"ports" validate:"dive,min=1"`
+	}
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "a.yaml", []byte("backends:\n  foo:\n    timeout: soon\nretries: 3\nports: [0, 80]"), 0644))
+	reader, err := NewConfigReaderBuilder().WithFs(fs).WithStrictMode(true).WithConfigFile("a.yaml").Build()
+	require.NoError(t, err)
+
+	conf := DemoConfig{}
+	err = reader.Unmarshal(&conf)
+	var errs Errors
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 2)
+	require.Equal(t, &Error{Key: "backends.foo.timeout", File: "a.yaml", Line: 3, Column: 5, Err: errs[0].(*Error).Err}, errs[0])
+	require.Equal(t, &Error{Key: "retries", File: "a.yaml", Line: 4, Column: 1, Err: ErrUnexpectedKey}, errs[1])
+
+	require.NoError(t, afero.WriteFile(fs, "b.yaml", []byte("backends:\n  foo:\n    timeout: 1s\nports: [0, 80]"), 0644))
+	reader, err = NewConfigReaderBuilder().WithFs(fs).WithConfigFile("b.yaml").Build()
+	require.NoError(t, err)
+	conf = DemoConfig{}
+	require.NoError(t, reader.Unmarshal(&conf))
+	require.EqualError(t, reader.Validate(&conf), "invalid configuration, 2 errors:\n"+
+		"\tb.yaml:2:3: backends.foo.url: failed on the 'required' validation\n"+
+		"\tb.yaml:4:9: ports.0: failed on the 'min=1' validation")
+
+	_, err = NewConfigReaderBuilder().WithFs(fs).WithConfigFiles("a.yaml", "missing.yaml", "other.yaml").Build()
+	require.ErrorAs(t, err, &errs)
+	require.Len(t, errs, 2)
+}
@@ -1,7 +1,7 @@
 package config
 
 import (
-	"log"
+	"slices"
 	"strings"
 
 	"github.com/spf13/afero"
@@ -10,17 +10,33 @@ import (
 
 // ConfigReaderBuilder exposes the builder api for configReaderImpl.
 // Use NewConfigReaderBuilder() and AttachEnvPrefix() to Build a ConfigReaderBuilder. Follow it up one or more calls
-// to WithConfigFile() and/or WithConfigName() and finally use Build() to Build the configReaderImpl.
+// to WithConfigFile(), WithConfigFiles() and/or WithConfigName() and finally use Build() to Build the configReaderImpl.
+// The errors of the config files are collected as they are attached and returned by Build().
+//
+// Values are resolved in the following order of precedence, from lowest to highest:
+//
+//  1. defaults, see WithDefaults()
+//  2. config files, in the order they are given, each one after the files it includes
+//  3. profile overlays, see WithProfiles()
+//  4. environment variables, see AttachEnvPrefix()
+//  5. overrides, such as command-line flags, see WithOverride()
 type ConfigReaderBuilder struct { //nolint:revive
 	evarReader configReaderImpl
+	fs         afero.Fs
+	files      []string
+	errs       []error
 }
 
 // NewConfigReaderBuilder builds a new ConfigReaderBuilder.
 func NewConfigReaderBuilder() ConfigReaderBuilder {
 	b := ConfigReaderBuilder{
 		evarReader: configReaderImpl{
-			envVars: viper.New(),
+			envVars:   viper.New(),
+			sources:   map[string]keySource{},
+			defaults:  map[string]struct{}{},
+			overrides: map[string]struct{}{},
 		},
+		fs: afero.NewOsFs(),
 	}
 	return b
 }
@@ -28,6 +44,7 @@ func NewConfigReaderBuilder() ConfigReaderBuilder {
 // AttachEnvPrefix attaches appName as prefix.
 func (b ConfigReaderBuilder) AttachEnvPrefix(appName string) ConfigReaderBuilder {
 	b.evarReader.envVars.SetEnvPrefix(appName)
+	b.evarReader.envPrefix = appName
 	b.evarReader.envVars.AutomaticEnv()
 	b.evarReader.envVars.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	return b
@@ -35,10 +52,41 @@ func (b ConfigReaderBuilder) AttachEnvPrefix(appName string) ConfigReaderBuilder
 
 // WithConfigFile attaches the passed config file.
 func (b ConfigReaderBuilder) WithConfigFile(configFile string) ConfigReaderBuilder {
-	b.evarReader.envVars.SetConfigFile(configFile)
-	if err := b.evarReader.envVars.MergeInConfig(); err != nil {
-		log.Fatalln(err)
+	return b.WithConfigFiles(configFile)
+}
+
+// WithConfigFiles merges the passed config files in order, so that the values of a file take
+// precedence over those of the files before it. The files listed under the include key of a
+// file, relative to it, are merged just before that file.
+func (b ConfigReaderBuilder) WithConfigFiles(configFiles ...string) ConfigReaderBuilder {
+	for _, file := range configFiles {
+		if err := mergeLayer(b.evarReader.envVars, b.evarReader.sources, b.fs, file, nil); err != nil {
+			b.errs = append(slices.Clip(b.errs), err)
+		}
 	}
+	b.files = append(slices.Clip(b.files), configFiles...)
+	return b
+}
+
+// WithProfiles merges the overlays of the config files attached so far for each of the passed
+// profiles, in order. The overlay of config.yaml for the prod profile is config.prod.yaml, and
+// each profile must have an overlay for at least one of the files. If no profile is passed, the
+// comma separated profiles of the profile key of the config files, if any, are used instead.
+func (b ConfigReaderBuilder) WithProfiles(profiles ...string) ConfigReaderBuilder {
+	profiles = splitProfiles(profiles...)
+	if len(profiles) == 0 {
+		profiles = splitProfiles(b.evarReader.envVars.GetStringSlice(ProfileKey)...)
+	}
+	if err := mergeProfiles(b.evarReader.envVars, b.evarReader.sources, b.fs, b.files, profiles); err != nil {
+		b.errs = append(slices.Clip(b.errs), err)
+	}
+	return b
+}
+
+// WithOverride sets the value of the passed key, taking precedence over every other source.
+func (b ConfigReaderBuilder) WithOverride(key string, value interface{}) ConfigReaderBuilder {
+	b.evarReader.envVars.Set(key, value)
+	b.evarReader.overrides[strings.ToLower(key)] = struct{}{}
 	return b
 }
 
@@ -49,7 +97,9 @@ func (b ConfigReaderBuilder) WithConfigName(configName string, configPath ...str
 		b.evarReader.envVars.AddConfigPath(path)
 	}
 	if err := b.evarReader.envVars.MergeInConfig(); err != nil {
-		log.Fatalln(err)
+		b.errs = append(slices.Clip(b.errs), err)
+	} else {
+		recordKeySources(b.evarReader.sources, b.fs, b.evarReader.envVars.ConfigFileUsed())
 	}
 	return b
 }
@@ -57,6 +107,7 @@ func (b ConfigReaderBuilder) WithConfigName(configName string, configPath ...str
 // WithFs attaches the file system to use.
 func (b ConfigReaderBuilder) WithFs(fs afero.Fs) ConfigReaderBuilder {
 	b.evarReader.envVars.SetFs(fs)
+	b.fs = fs
 	return b
 }
 
@@ -76,17 +127,21 @@ func (b ConfigReaderBuilder) WithStrictMode(strict bool, ignoredKeys ...string)
 	return b
 }
 
-// Build Builds and returns the ConfigReader.
-func (b ConfigReaderBuilder) Build() ConfigReader {
-	if err := b.evarReader.envVars.ReadInConfig(); err != nil {
-		log.Fatalln(err)
+// Build Builds and returns the ConfigReader, or the errors of the config files as Errors.
+// The config files are merged as they are attached, Build does not read them again.
+func (b ConfigReaderBuilder) Build() (ConfigReader, error) {
+	if len(b.errs) > 0 {
+		return nil, Errors(slices.Clone(b.errs))
 	}
-	return b.evarReader
+	return b.evarReader, nil
 }
 
 // WithDefaults takes a function than can be called to set default values.
 func (b ConfigReaderBuilder) WithDefaults(setDefaults func(func(key string, value interface{}))) ConfigReaderBuilder {
-	setDefaults(b.evarReader.envVars.SetDefault)
+	setDefaults(func(key string, value interface{}) {
+		b.evarReader.envVars.SetDefault(key, value)
+		b.evarReader.defaults[strings.ToLower(key)] = struct{}{}
+	})
 
 	return b
 }
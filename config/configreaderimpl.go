@@ -4,10 +4,12 @@ import (
 	"fmt"
 	rawlog "log"
 	"reflect"
+	"regexp"
 	"strings"
 
 	"github.com/anz-bank/sysl-go/jsontime"
 	"github.com/anz-bank/sysl-go/log"
+	"github.com/anz-bank/sysl-go/validator"
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/pkg/errors"
 	"github.com/spf13/cast"
@@ -19,6 +21,12 @@ type configReaderImpl struct {
 	envVars               *viper.Viper
 	strictMode            bool
 	strictModeIgnoredKeys []string
+	// sources are the positions of the keys in the config files, by lower case key.
+	sources map[string]keySource
+	// defaults and overrides are the lower case keys that have a default value or an override.
+	defaults  map[string]struct{}
+	overrides map[string]struct{}
+	envPrefix string
 }
 
 // Get returns an interface{}.
@@ -51,21 +59,21 @@ func (m configReaderImpl) GetString(key string) (string, error) {
 }
 
 // Unmarshal deserializes the loaded cofig into a struct.
+// The decoding errors and, in strict mode, the unexpected keys are all returned as Errors.
 func (m configReaderImpl) Unmarshal(config interface{}) error {
 	opts := []viper.DecoderConfigOption{}
 
-	metadata := &mapstructure.Metadata{}
-
 	// If "strict mode" is set then regard unused config keys
 	// -- that is, config keys that don't correspond to any known
 	// config field -- as errors. Unless we are configured to explicitly
 	// ignore them.
 	if m.strictMode {
 		opts = append(opts, func(cfg *mapstructure.DecoderConfig) {
-			// Instead of setting cfg.ErrorUnused we instead collect Metadata,
-			// as this gives us a way to suppress and ignore some unused
-			// config keys.
-			cfg.Metadata = metadata
+			// The unused keys are reported as errors along with the other
+			// decoding errors, unlike the Metadata that is not collected for
+			// a struct with decoding errors. The ignored keys are filtered
+			// away from these errors.
+			cfg.ErrorUnused = true
 		})
 	}
 
@@ -74,20 +82,46 @@ func (m configReaderImpl) Unmarshal(config interface{}) error {
 	opts = append(opts, decodeHook)
 
 	if err := m.envVars.Unmarshal(config, opts...); err != nil {
-		return fmt.Errorf("Unable to decode into struct %s", err)
+		var errs []error
+		for _, err := range decodeErrors(m.sources, err) {
+			if e, ok := err.(*Error); ok {
+				if name, keys, unused := unusedKeys(e.Err); unused {
+					errs = append(errs, m.unusedKeyErrors(name, keys)...)
+					continue
+				}
+			}
+			errs = append(errs, err)
+		}
+		return errorsOrNil(errs)
 	}
 
-	if m.strictMode {
-		return m.validateNoUnusedKeys(metadata)
-	}
+	return nil
+}
 
+// Validate validates the config decoded by Unmarshal with validator.Validate and returns every
+// validation error as Errors.
+func (m configReaderImpl) Validate(config interface{}) error {
+	if err := validator.Validate(config); err != nil {
+		return errorsOrNil(validationErrors(m.sources, config, err))
+	}
 	return nil
 }
 
-func (m configReaderImpl) validateNoUnusedKeys(metadata *mapstructure.Metadata) error {
+var unusedKeysError = regexp.MustCompile(`^'([^']*)' has invalid keys: (.*)$`)
+
+// unusedKeys returns the name and the unused keys of the struct of an unused keys error.
+func unusedKeys(err error) (string, []string, bool) {
+	m := unusedKeysError.FindStringSubmatch(err.Error())
+	if m == nil {
+		return "", nil, false
+	}
+	return m[1], strings.Split(m[2], ", "), true
+}
+
+func (m configReaderImpl) unusedKeyErrors(name string, keys []string) []error {
 	// Filter away any unused keys that should be ignored.
 	// Beware: for nested keys, mapstructure will not
-	// necessarily report the full key in metadata.Unused:
+	// necessarily report the full key as unused:
 	// For example, if we unmarshal into a config structure
 	// with no "fizz" key, and there is a nested key named
 	// "fizz.buzz" in the input, then mapstructure will report
@@ -100,25 +134,26 @@ func (m configReaderImpl) validateNoUnusedKeys(metadata *mapstructure.Metadata)
 		k = strings.ToLower(k)
 		toIgnore[k] = struct{}{}
 	}
-	unusedNotIgnored := make([]string, 0)
-	for _, unusedKey := range metadata.Unused {
+	var errs []error
+	for _, unusedKey := range keys {
+		if name != "" {
+			unusedKey = name + "." + unusedKey
+		}
 		_, ok := toIgnore[unusedKey]
 		if ok {
 			continue
 		}
-		unusedNotIgnored = append(unusedNotIgnored, unusedKey)
-	}
-	if len(unusedNotIgnored) > 0 {
-		return fmt.Errorf("Misconfiguration error: found unexpected config key(s): %s", strings.Join(unusedNotIgnored, ","))
+		errs = append(errs, keyError(m.sources, unusedKey, ErrUnexpectedKey))
 	}
-	return nil
+	return errs
 }
 
 func makeDefaultDecodeHook() mapstructure.DecodeHookFunc {
 	return mapstructure.ComposeDecodeHookFunc(
-		// Function to accommodate for log level.
+		// Function to accommodate for log level. Other strings, such as the admin.auth.routes
+		// key debug, are left as they are.
 		func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
-			if f.Kind() != reflect.String {
+			if f.Kind() != reflect.String || t != logLevelType {
 				return data, nil
 			}
 
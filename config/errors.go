@@ -0,0 +1,252 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	vv10 "github.com/go-playground/validator/v10"
+)
+
+// Error is an error of the config value at Key, located in the config file that set it, or its
+// closest parent key, when it is known.
+type Error struct {
+	Key    string
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *Error) Error() string {
+	var b strings.Builder
+	if e.File != "" {
+		b.WriteString(e.File)
+		if e.Line > 0 {
+			fmt.Fprintf(&b, ":%d:%d", e.Line, e.Column)
+		}
+		b.WriteString(": ")
+	}
+	if e.Key != "" {
+		b.WriteString(e.Key)
+		b.WriteString(": ")
+	}
+	b.WriteString(e.Err.Error())
+	return b.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Errors are all the errors found in a config, so that they can be fixed at once.
+type Errors []error
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return "invalid configuration: " + e[0].Error()
+	}
+	msgs := make([]string, 0, len(e)+1)
+	msgs = append(msgs, fmt.Sprintf("invalid configuration, %d errors:", len(e)))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n\t")
+}
+
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// JoinErrors returns the errors of errs, flattening Errors, as Errors, or nil if there are none.
+func JoinErrors(errs ...error) error {
+	var all []error
+	for _, err := range errs {
+		if e, ok := err.(Errors); ok {
+			all = append(all, e...)
+		} else if err != nil {
+			all = append(all, err)
+		}
+	}
+	return errorsOrNil(all)
+}
+
+// errorsOrNil returns errs as Errors, or nil if there are none.
+func errorsOrNil(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return Errors(errs)
+}
+
+// ErrUnexpectedKey is the error of a config key that does not correspond to any config field
+// in strict mode.
+var ErrUnexpectedKey = errors.New("unexpected config key")
+
+// keySource is where a config key is set.
+type keySource struct {
+	File   string
+	Line   int
+	Column int
+}
+
+// keyError returns the error of the config key, located with sources.
+func keyError(sources map[string]keySource, key string, err error) *Error {
+	// mapstructure names the map and slice elements like the validator namespace
+	key = namespaceIndex.ReplaceAllString(key, ".$1")
+	e := &Error{Key: key, Err: err}
+	// a key that is not set, such as a missing required key, is located at its closest parent
+	for k := strings.ToLower(key); k != ""; {
+		if source, has := sources[k]; has {
+			e.File, e.Line, e.Column = source.File, source.Line, source.Column
+			break
+		}
+		i := strings.LastIndex(k, ".")
+		if i < 0 {
+			break
+		}
+		k = k[:i]
+	}
+	return e
+}
+
+// leafErrors returns the errors joined in err, dropping the messages that only introduce them
+// such as the "decoding failed due to the following error(s)" of mapstructure.
+func leafErrors(err error) []error {
+	if wrapper, ok := err.(interface{ Unwrap() error }); ok {
+		if _, joined := wrapper.Unwrap().(interface{ Unwrap() []error }); joined {
+			return leafErrors(wrapper.Unwrap())
+		}
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []error
+		for _, err := range joined.Unwrap() {
+			errs = append(errs, leafErrors(err)...)
+		}
+		return errs
+	}
+	return []error{err}
+}
+
+var decodeErrorKey = regexp.MustCompile(`'([^']*)'`)
+
+// decodeErrors returns the errors of a decode error, located at the key that they quote.
+func decodeErrors(sources map[string]keySource, err error) []error {
+	var errs []error
+	for _, err := range leafErrors(err) {
+		key := ""
+		if m := decodeErrorKey.FindStringSubmatch(err.Error()); m != nil {
+			key = m[1]
+		}
+		errs = append(errs, keyError(sources, key, err))
+	}
+	return errs
+}
+
+// validationErrors returns the errors of a validation error of the value v, located at the key of
+// the field that failed.
+func validationErrors(sources map[string]keySource, v interface{}, err error) []error {
+	var errs []error
+	for _, err := range leafErrors(err) {
+		var fieldErrs vv10.ValidationErrors
+		if !errors.As(err, &fieldErrs) {
+			errs = append(errs, err)
+			continue
+		}
+		for _, fe := range fieldErrs {
+			key := keyOfNamespace(reflect.ValueOf(v), fe.StructNamespace())
+			errs = append(errs, keyError(sources, key, fieldError{fe}))
+		}
+	}
+	return errs
+}
+
+// fieldError is a validation error without the struct namespace, which is replaced by the key.
+type fieldError struct {
+	vv10.FieldError
+}
+
+func (e fieldError) Error() string {
+	if e.Param() != "" {
+		return fmt.Sprintf("failed on the '%s=%s' validation", e.ActualTag(), e.Param())
+	}
+	return fmt.Sprintf("failed on the '%s' validation", e.ActualTag())
+}
+
+func (e fieldError) Unwrap() error {
+	return e.FieldError
+}
+
+var namespaceIndex = regexp.MustCompile(`\[([^]]*)\]`)
+
+// keyOfNamespace returns the config key of the field of v at the validator namespace, such as
+// DefaultConfig.Library.Log.Level, by following the config names of the fields. The value is
+// followed rather than its type to find the fields of interfaces, such as the downstream config.
+func keyOfNamespace(v reflect.Value, namespace string) string {
+	// the namespace starts with the name of the validated type, unless it is anonymous
+	t := v.Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Name() != "" {
+		_, namespace, _ = strings.Cut(namespace, ".")
+	}
+	namespace = namespaceIndex.ReplaceAllString(namespace, ".$1")
+
+	var keys []string
+	for _, segment := range strings.Split(namespace, ".") {
+		for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				if v.Kind() == reflect.Interface {
+					return strings.Join(append(keys, segment), ".")
+				}
+				v = reflect.Zero(v.Type().Elem())
+			} else {
+				v = v.Elem()
+			}
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			f, has := v.Type().FieldByName(segment)
+			if !has {
+				return strings.Join(append(keys, segment), ".")
+			}
+			// the fields of squashed structs are found through their parent
+			for i := range f.Index {
+				ff := v.Type().FieldByIndex(f.Index[:i+1])
+				if name, squash := configFieldName(ff); !squash {
+					keys = append(keys, name)
+				}
+			}
+			fv, err := v.FieldByIndexErr(f.Index)
+			if err != nil {
+				fv = reflect.Zero(f.Type)
+			}
+			v = fv
+		case reflect.Slice, reflect.Array:
+			keys = append(keys, segment)
+			i, err := strconv.Atoi(segment)
+			if err != nil || i >= v.Len() {
+				v = reflect.Zero(v.Type().Elem())
+			} else {
+				v = v.Index(i)
+			}
+		case reflect.Map:
+			keys = append(keys, segment)
+			var elem reflect.Value
+			if v.Type().Key().Kind() == reflect.String {
+				elem = v.MapIndex(reflect.ValueOf(segment).Convert(v.Type().Key()))
+			}
+			if !elem.IsValid() {
+				elem = reflect.Zero(v.Type().Elem())
+			}
+			v = elem
+		default:
+			keys = append(keys, segment)
+		}
+	}
+	return strings.Join(keys, ".")
+}
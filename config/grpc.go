@@ -2,25 +2,90 @@ package config
 
 import (
 	"context"
+	"time"
 
+	vv10 "github.com/go-playground/validator/v10"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+
+	// Register the gzip compressor so that servers accept gzip compressed requests and
+	// clients can enable it with compression: gzip.
+	_ "google.golang.org/grpc/encoding/gzip"
+
+	"github.com/anz-bank/sysl-go/validator"
 )
 
+// GRPCServerKeepaliveConfig configures the keepalive behaviour of a gRPC server.
+type GRPCServerKeepaliveConfig struct {
+	MaxConnectionIdle     time.Duration `yaml:"maxConnectionIdle" mapstructure:"maxConnectionIdle"`
+	MaxConnectionAge      time.Duration `yaml:"maxConnectionAge" mapstructure:"maxConnectionAge"`
+	MaxConnectionAgeGrace time.Duration `yaml:"maxConnectionAgeGrace" mapstructure:"maxConnectionAgeGrace"`
+	Time                  time.Duration `yaml:"time" mapstructure:"time"`
+	Timeout               time.Duration `yaml:"timeout" mapstructure:"timeout"`
+
+	// MinTime and PermitWithoutStream make up the enforcement policy applied to the pings of clients.
+	MinTime             time.Duration `yaml:"minTime" mapstructure:"minTime"`
+	PermitWithoutStream bool          `yaml:"permitWithoutStream" mapstructure:"permitWithoutStream"`
+}
+
+// GRPCClientKeepaliveConfig configures the keepalive pings sent by a gRPC client.
+type GRPCClientKeepaliveConfig struct {
+	Time                time.Duration `yaml:"time" mapstructure:"time"`
+	Timeout             time.Duration `yaml:"timeout" mapstructure:"timeout"`
+	PermitWithoutStream bool          `yaml:"permitWithoutStream" mapstructure:"permitWithoutStream"`
+}
+
 func ExtractGrpcServerOptions(ctx context.Context, cfg *GRPCServerConfig) ([]grpc.ServerOption, error) {
-	if cfg == nil || cfg.TLS == nil {
+	if cfg == nil {
 		return []grpc.ServerOption{}, nil
 	}
 
-	tlsConfig, err := MakeTLSConfig(ctx, cfg.TLS)
-	if err != nil {
-		return nil, err
+	opts := []grpc.ServerOption{}
+	if cfg.TLS != nil {
+		tlsConfig, err := MakeTLSConfig(ctx, cfg.TLS)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	if cfg.Keepalive != nil {
+		opts = append(opts,
+			grpc.KeepaliveParams(keepalive.ServerParameters{
+				MaxConnectionIdle:     cfg.Keepalive.MaxConnectionIdle,
+				MaxConnectionAge:      cfg.Keepalive.MaxConnectionAge,
+				MaxConnectionAgeGrace: cfg.Keepalive.MaxConnectionAgeGrace,
+				Time:                  cfg.Keepalive.Time,
+				Timeout:               cfg.Keepalive.Timeout,
+			}),
+			grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+				MinTime:             cfg.Keepalive.MinTime,
+				PermitWithoutStream: cfg.Keepalive.PermitWithoutStream,
+			}),
+		)
+	}
+	if cfg.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(cfg.MaxRecvMsgSize))
+	}
+	if cfg.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(cfg.MaxSendMsgSize))
+	}
+	if cfg.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(cfg.MaxConcurrentStreams))
+	}
+	if cfg.ConnectionTimeout > 0 {
+		opts = append(opts, grpc.ConnectionTimeout(cfg.ConnectionTimeout))
+	}
+	if cfg.InitialWindowSize > 0 {
+		opts = append(opts, grpc.InitialWindowSize(cfg.InitialWindowSize))
+	}
+	if cfg.InitialConnWindowSize > 0 {
+		opts = append(opts, grpc.InitialConnWindowSize(cfg.InitialConnWindowSize))
 	}
 
-	creds := credentials.NewTLS(tlsConfig)
-
-	return []grpc.ServerOption{grpc.Creds(creds)}, nil
+	return opts, nil
 }
 
 // CommonGRPCDownstreamData collects all the client gRPC configuration.
@@ -28,12 +93,52 @@ type CommonGRPCDownstreamData struct {
 	ServiceAddress string     `yaml:"serviceAddress" mapstructure:"serviceAddress"`
 	TLS            *TLSConfig `yaml:"tls" mapstructure:"tls"`
 	WithBlock      bool       `yaml:"withBlock" mapstructure:"withBlock"`
+
+	// Keepalive configures the keepalive pings sent to the downstream.
+	Keepalive *GRPCClientKeepaliveConfig `yaml:"keepalive" mapstructure:"keepalive"`
+	// MaxRecvMsgSize and MaxSendMsgSize limit the size of messages in bytes. Zero uses the grpc defaults.
+	MaxRecvMsgSize int `yaml:"maxRecvMsgSize" mapstructure:"maxRecvMsgSize" validate:"min=0"`
+	MaxSendMsgSize int `yaml:"maxSendMsgSize" mapstructure:"maxSendMsgSize" validate:"min=0"`
+	// Compression is the name of the compressor used for requests, e.g. gzip. Empty means no compression.
+	Compression string `yaml:"compression" mapstructure:"compression"`
+	// ConnectTimeout is the minimum time allowed to establish a connection.
+	ConnectTimeout time.Duration `yaml:"connectTimeout" mapstructure:"connectTimeout" validate:"min=0"`
+	// InitialWindowSize and InitialConnWindowSize set the flow control windows of streams and
+	// connections respectively. Values below 64KiB are ignored by grpc.
+	InitialWindowSize     int32 `yaml:"initialWindowSize" mapstructure:"initialWindowSize" validate:"min=0"`
+	InitialConnWindowSize int32 `yaml:"initialConnWindowSize" mapstructure:"initialConnWindowSize" validate:"min=0"`
+
+	// Propagation controls which incoming headers are sent to the downstream as gRPC metadata.
+	Propagation *HeaderPropagationConfig `yaml:"propagation" mapstructure:"propagation"`
+
+	// LoadBalancing spreads requests across several endpoints of the downstream.
+	LoadBalancing *LoadBalancingConfig `yaml:"loadBalancing" mapstructure:"loadBalancing"`
 }
 
 func NewDefaultCommonGRPCDownstreamData() *CommonGRPCDownstreamData {
 	return &CommonGRPCDownstreamData{}
 }
 
+// grpcDownstreamValidator rejects the load balancing options that only HTTP downstreams support,
+// so that they fail when the config is loaded rather than when the client is built or not at all.
+func grpcDownstreamValidator(sl vv10.StructLevel) {
+	lb := sl.Current().Interface().(CommonGRPCDownstreamData).LoadBalancing
+	if lb == nil {
+		return
+	}
+	if lb.Policy == Weighted {
+		sl.ReportError(lb.Policy, "LoadBalancing.Policy", "LoadBalancing.Policy", "oneof", "roundRobin leastOutstanding")
+	}
+	if lb.OutlierEjection != nil {
+		sl.ReportError(lb.OutlierEjection, "LoadBalancing.OutlierEjection", "LoadBalancing.OutlierEjection", "httponly", "")
+	}
+}
+
+//nolint:gochecknoinits // We must use init here to setup a custom validator
+func init() {
+	validator.RegisterStructLevel(grpcDownstreamValidator, CommonGRPCDownstreamData{})
+}
+
 // DefaultGRPDialOptions creates []grpc.DialOption from the given config.
 // If cfg is nil then NewDefaultCommonGRPCDownstreamData will be used to define
 // the dial options.
@@ -55,5 +160,37 @@ func DefaultGrpcDialOptions(ctx context.Context, cfg *CommonGRPCDownstreamData)
 	if cfg.WithBlock {
 		opts = append(opts, grpc.WithBlock())
 	}
+	if cfg.Keepalive != nil {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                cfg.Keepalive.Time,
+			Timeout:             cfg.Keepalive.Timeout,
+			PermitWithoutStream: cfg.Keepalive.PermitWithoutStream,
+		}))
+	}
+	var callOpts []grpc.CallOption
+	if cfg.MaxRecvMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(cfg.MaxRecvMsgSize))
+	}
+	if cfg.MaxSendMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(cfg.MaxSendMsgSize))
+	}
+	if cfg.Compression != "" {
+		callOpts = append(callOpts, grpc.UseCompressor(cfg.Compression))
+	}
+	if len(callOpts) > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(callOpts...))
+	}
+	if cfg.ConnectTimeout > 0 {
+		opts = append(opts, grpc.WithConnectParams(grpc.ConnectParams{
+			Backoff:           backoff.DefaultConfig,
+			MinConnectTimeout: cfg.ConnectTimeout,
+		}))
+	}
+	if cfg.InitialWindowSize > 0 {
+		opts = append(opts, grpc.WithInitialWindowSize(cfg.InitialWindowSize))
+	}
+	if cfg.InitialConnWindowSize > 0 {
+		opts = append(opts, grpc.WithInitialConnWindowSize(cfg.InitialConnWindowSize))
+	}
 	return opts, nil
 }
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractGrpcServerOptions_Empty(t *testing.T) {
+	opts, err := ExtractGrpcServerOptions(context.Background(), &GRPCServerConfig{})
+	require.NoError(t, err)
+	require.Empty(t, opts)
+}
+
+func TestExtractGrpcServerOptions_Tuning(t *testing.T) {
+	cfg := &GRPCServerConfig{
+		Keepalive: &GRPCServerKeepaliveConfig{
+			Time:    time.Minute,
+			MinTime: 10 * time.Second,
+		},
+		MaxRecvMsgSize:        1 << 20,
+		MaxSendMsgSize:        1 << 20,
+		MaxConcurrentStreams:  100,
+		ConnectionTimeout:     5 * time.Second,
+		InitialWindowSize:     1 << 20,
+		InitialConnWindowSize: 1 << 20,
+	}
+	opts, err := ExtractGrpcServerOptions(context.Background(), cfg)
+	require.NoError(t, err)
+	require.Len(t, opts, 8)
+}
+
+func TestDefaultGrpcDialOptions_Tuning(t *testing.T) {
+	cfg := &CommonGRPCDownstreamData{
+		ServiceAddress: "localhost:8080",
+		Keepalive: &GRPCClientKeepaliveConfig{
+			Time: time.Minute,
+		},
+		MaxRecvMsgSize:        1 << 20,
+		Compression:           "gzip",
+		ConnectTimeout:        5 * time.Second,
+		InitialWindowSize:     1 << 20,
+		InitialConnWindowSize: 1 << 20,
+	}
+	opts, err := DefaultGrpcDialOptions(context.Background(), cfg)
+	require.NoError(t, err)
+	// credentials, keepalive, default call options, connect params and the two window sizes
+	require.Len(t, opts, 6)
+}
+
+func TestValidateGrpcDownstreamLoadBalancing(t *testing.T) {
+	t.Parallel()
+
+	type downstreamConfig struct {
+		Backend CommonGRPCDownstreamData `mapstructure:"backend"`
+	}
+	type testConfig struct {
+		Downstream interface{} `mapstructure:"downstream"`
+	}
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "config.yaml", []byte(`
+downstream:
+  backend:
+    loadBalancing:
+      policy: weighted
+      endpoints:
+        - address: localhost:8080
+      outlierEjection:
+        consecutiveFailures: 3
+`), 0644))
+	reader, err := NewConfigReaderBuilder().WithFs(fs).WithConfigFile("config.yaml").Build()
+	require.NoError(t, err)
+	downstream := &downstreamConfig{}
+	cfg := testConfig{Downstream: downstream}
+	require.NoError(t, reader.Unmarshal(&cfg))
+	require.EqualError(t, reader.Validate(&cfg), "invalid configuration, 2 errors:\n"+
+		"\tconfig.yaml:5:7: downstream.backend.loadBalancing.policy: failed on the 'oneof=roundRobin leastOutstanding' validation\n"+
+		"\tconfig.yaml:8:7: downstream.backend.loadBalancing.outlierEjection: failed on the 'httponly' validation")
+
+	downstream.Backend.LoadBalancing.Policy = LeastOutstanding
+	downstream.Backend.LoadBalancing.OutlierEjection = nil
+	require.NoError(t, reader.Validate(&cfg))
+}
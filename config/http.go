@@ -35,6 +35,12 @@ type CommonDownstreamData struct {
 	ClientTransport Transport           `yaml:"clientTransport" mapstructure:"clientTransport"`
 	ClientTimeout   time.Duration       `yaml:"clientTimeout" mapstructure:"clientTimeout" validate:"timeout=1ms:60s"`
 	Headers         map[string][]string `yaml:"headers" mapstructure:"headers"`
+
+	// Propagation controls which incoming headers are sent to the downstream.
+	Propagation *HeaderPropagationConfig `yaml:"propagation" mapstructure:"propagation"`
+
+	// LoadBalancing spreads requests across several endpoints of the downstream.
+	LoadBalancing *LoadBalancingConfig `yaml:"loadBalancing" mapstructure:"loadBalancing"`
 }
 
 // Transport is used to initialise DefaultHTTPTransport.
@@ -70,9 +76,9 @@ func (g *CommonDownstreamData) Validate() error {
 }
 
 type CommonServerConfig struct {
-	HostName string     `yaml:"hostName" mapstructure:"hostName"`
-	Port     int        `yaml:"port" mapstructure:"port" validate:"min=0,max=65534"`
-	TLS      *TLSConfig `yaml:"tls" mapstructure:"tls"`
+	HostName string     `yaml:"hostName" mapstructure:"hostName" description:"The host name or address the server listens on."`
+	Port     int        `yaml:"port" mapstructure:"port" validate:"min=0,max=65534" description:"The port the server listens on."`
+	TLS      *TLSConfig `yaml:"tls" mapstructure:"tls" description:"Serves over TLS, otherwise the server is plain text."`
 }
 
 // TODO: Inline CommonServerConfig
@@ -86,11 +92,57 @@ type CommonHTTPServerConfig struct {
 	BasePath     string             `yaml:"basePath" mapstructure:"basePath" validate:"omitempty,startswith=/"`
 	ReadTimeout  time.Duration      `yaml:"readTimeout" mapstructure:"readTimeout" validate:"nonnil"`
 	WriteTimeout time.Duration      `yaml:"writeTimeout" mapstructure:"writeTimeout" validate:"nonnil"`
+
+	// ReadHeaderTimeout is the time allowed to read the request headers. Defaults to 10s.
+	ReadHeaderTimeout time.Duration `yaml:"readHeaderTimeout" mapstructure:"readHeaderTimeout" validate:"min=0"`
+	// IdleTimeout is how long an idle keep-alive connection is kept open. Defaults to 5s.
+	IdleTimeout time.Duration `yaml:"idleTimeout" mapstructure:"idleTimeout" validate:"min=0"`
+	// MaxHeaderBytes limits the size of the request headers. Defaults to http.DefaultMaxHeaderBytes.
+	MaxHeaderBytes int `yaml:"maxHeaderBytes" mapstructure:"maxHeaderBytes" validate:"min=0"`
+	// MaxRequestBodyBytes limits the size of request bodies. Reading a larger body fails, which
+	// handlers report as 413 Request Entity Too Large unless their MapError maps it otherwise.
+	// Zero means no limit.
+	MaxRequestBodyBytes int64 `yaml:"maxRequestBodyBytes" mapstructure:"maxRequestBodyBytes" validate:"min=0"`
+	// DisableKeepAlives closes each connection after a single request.
+	DisableKeepAlives bool `yaml:"disableKeepAlives" mapstructure:"disableKeepAlives"`
+	// HTTP2 configures HTTP/2 support of the server.
+	HTTP2 HTTP2ServerConfig `yaml:"http2" mapstructure:"http2"`
+}
+
+// HTTP2ServerConfig configures HTTP/2 support of an HTTP server. HTTP/2 is enabled by default
+// for TLS connections only.
+type HTTP2ServerConfig struct {
+	// Disable turns off HTTP/2 for TLS connections.
+	Disable bool `yaml:"disable" mapstructure:"disable"`
+	// H2C enables HTTP/2 over cleartext (prior knowledge) connections, e.g. behind a proxy that
+	// terminates TLS.
+	H2C bool `yaml:"h2c" mapstructure:"h2c"`
+
+	MaxConcurrentStreams          int           `yaml:"maxConcurrentStreams" mapstructure:"maxConcurrentStreams" validate:"min=0"`
+	MaxReadFrameSize              int           `yaml:"maxReadFrameSize" mapstructure:"maxReadFrameSize" validate:"min=0"`
+	MaxReceiveBufferPerConnection int           `yaml:"maxReceiveBufferPerConnection" mapstructure:"maxReceiveBufferPerConnection" validate:"min=0"`
+	MaxReceiveBufferPerStream     int           `yaml:"maxReceiveBufferPerStream" mapstructure:"maxReceiveBufferPerStream" validate:"min=0"`
+	SendPingTimeout               time.Duration `yaml:"sendPingTimeout" mapstructure:"sendPingTimeout" validate:"min=0"`
+	PingTimeout                   time.Duration `yaml:"pingTimeout" mapstructure:"pingTimeout" validate:"min=0"`
 }
 
 type GRPCServerConfig struct {
 	CommonServerConfig `yaml:",inline" mapstructure:",squash"`
 	EnableReflection   bool `yaml:"enableReflection" mapstructure:"enableReflection"`
+
+	// Keepalive configures keepalive pings and the keepalive enforcement policy.
+	Keepalive *GRPCServerKeepaliveConfig `yaml:"keepalive" mapstructure:"keepalive"`
+	// MaxRecvMsgSize and MaxSendMsgSize limit the size of messages in bytes. Zero uses the grpc defaults.
+	MaxRecvMsgSize int `yaml:"maxRecvMsgSize" mapstructure:"maxRecvMsgSize" validate:"min=0"`
+	MaxSendMsgSize int `yaml:"maxSendMsgSize" mapstructure:"maxSendMsgSize" validate:"min=0"`
+	// MaxConcurrentStreams limits the number of concurrent streams per connection. Zero means no limit.
+	MaxConcurrentStreams uint32 `yaml:"maxConcurrentStreams" mapstructure:"maxConcurrentStreams"`
+	// ConnectionTimeout is the time allowed for new connections to complete the handshake.
+	ConnectionTimeout time.Duration `yaml:"connectionTimeout" mapstructure:"connectionTimeout" validate:"min=0"`
+	// InitialWindowSize and InitialConnWindowSize set the flow control windows of streams and
+	// connections respectively. Values below 64KiB are ignored by grpc.
+	InitialWindowSize     int32 `yaml:"initialWindowSize" mapstructure:"initialWindowSize" validate:"min=0"`
+	InitialConnWindowSize int32 `yaml:"initialConnWindowSize" mapstructure:"initialConnWindowSize" validate:"min=0"`
 }
 
 func (c *CommonHTTPServerConfig) Validate() error {
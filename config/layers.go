@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// IncludeKey is the key of the files that a config file includes. The paths are relative to
+	// the including file, which is merged after them so that its own values take precedence.
+	IncludeKey = "include"
+
+	// ProfileKey is the key of the profiles selected by the config files when none is given
+	// to ConfigReaderBuilder.WithProfiles.
+	ProfileKey = "profile"
+)
+
+// mergeLayer merges the file at path into v, after the files it includes. The include key
+// itself is not merged. The position of each key of a YAML or JSON file is recorded in sources.
+// stack holds the files being included, to report include cycles.
+func mergeLayer(v *viper.Viper, sources map[string]keySource, fs afero.Fs, path string, stack []string) error {
+	for _, p := range stack {
+		if p == path {
+			return fmt.Errorf("config include cycle: %s -> %s", strings.Join(stack, " -> "), path)
+		}
+	}
+	stack = append(stack, path)
+
+	layer := viper.New()
+	layer.SetFs(fs)
+	layer.SetConfigFile(path)
+	if err := layer.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var includes []string
+	if layer.IsSet(IncludeKey) {
+		var err error
+		includes, err = cast.ToStringSliceE(layer.Get(IncludeKey))
+		if err != nil {
+			return fmt.Errorf("invalid %s in config file %s: %w", IncludeKey, path, err)
+		}
+	}
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		if err := mergeLayer(v, sources, fs, include, stack); err != nil {
+			return err
+		}
+	}
+
+	settings := layer.AllSettings()
+	delete(settings, IncludeKey)
+	if err := v.MergeConfigMap(settings); err != nil {
+		return err
+	}
+	recordKeySources(sources, fs, path)
+	return nil
+}
+
+// recordKeySources records the position of each key of the YAML or JSON file at path in sources,
+// by lower case key like viper. Other files are not located.
+func recordKeySources(sources map[string]keySource, fs afero.Fs, path string) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml", ".json":
+	default:
+		return
+	}
+	data, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return
+	}
+	var walk func(node *yaml.Node, prefix string)
+	walk = func(node *yaml.Node, prefix string) {
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				key := strings.ToLower(node.Content[i].Value)
+				if prefix == "" && key == IncludeKey {
+					continue
+				}
+				if prefix != "" {
+					key = prefix + "." + key
+				}
+				sources[key] = keySource{File: path, Line: node.Content[i].Line, Column: node.Content[i].Column}
+				walk(node.Content[i+1], key)
+			}
+		case yaml.SequenceNode:
+			for i, item := range node.Content {
+				key := fmt.Sprintf("%s.%d", prefix, i)
+				sources[key] = keySource{File: path, Line: item.Line, Column: item.Column}
+				walk(item, key)
+			}
+		}
+	}
+	walk(doc.Content[0], "")
+}
+
+// profileFile returns the overlay of the config file at path for the given profile, which is
+// the file with the profile inserted before the extension, e.g. config.prod.yaml.
+func profileFile(path, profile string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + profile + ext
+}
+
+// mergeProfiles merges the overlays of the given files for each profile, in the order of the
+// profiles. Each profile must have an overlay for at least one of the files.
+func mergeProfiles(v *viper.Viper, sources map[string]keySource, fs afero.Fs, files, profiles []string) error {
+	for _, profile := range profiles {
+		found := false
+		for _, file := range files {
+			overlay := profileFile(file, profile)
+			if exists, err := afero.Exists(fs, overlay); err != nil {
+				return err
+			} else if !exists {
+				continue
+			}
+			found = true
+			if err := mergeLayer(v, sources, fs, overlay, nil); err != nil {
+				return err
+			}
+		}
+		if !found {
+			return fmt.Errorf("no config file for profile %q", profile)
+		}
+	}
+	return nil
+}
+
+// splitProfiles splits a comma separated list of profiles.
+func splitProfiles(profiles ...string) []string {
+	var result []string
+	for _, p := range profiles {
+		for _, p := range strings.Split(p, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				result = append(result, p)
+			}
+		}
+	}
+	return result
+}
@@ -11,16 +11,41 @@ import (
 
 // LibraryConfig struct.
 type LibraryConfig struct {
-	Log            LogConfig             `yaml:"log" mapstructure:"log"`
-	Profiling      bool                  `yaml:"profiling" mapstructure:"profiling"`
-	Health         bool                  `yaml:"health" mapstructure:"health"`
-	Authentication *AuthenticationConfig `yaml:"authentication" mapstructure:"authentication"`
-	Trace          TraceConfig           `yaml:"trace" mapstructure:"trace"`
+	Log            LogConfig             `yaml:"log" mapstructure:"log" description:"The logging of the service."`
+	Profiling      bool                  `yaml:"profiling" mapstructure:"profiling" description:"Serves the pprof profiles on the admin server."`
+	Health         bool                  `yaml:"health" mapstructure:"health" description:"Serves the health checks of the service."`
+	Authentication *AuthenticationConfig `yaml:"authentication" mapstructure:"authentication" description:"The authentication of the requests to the service."`
+	Trace          TraceConfig           `yaml:"trace" mapstructure:"trace" description:"The tracing of the requests to the service."`
+	Deadline       DeadlineConfig        `yaml:"deadline" mapstructure:"deadline" description:"The propagation of the remaining time budget of requests."`
 }
 
 type AdminConfig struct {
 	ContextTimeout time.Duration          `yaml:"contextTimeout" mapstructure:"contextTimeout" validate:"nonnil"`
 	HTTP           CommonHTTPServerConfig `yaml:"http" mapstructure:"http"`
+	// Auth authorizes the requests to the admin server, see AdminAuthConfig.
+	Auth *AdminAuthConfig `yaml:"auth" mapstructure:"auth"`
+	// Debug enables the debugging endpoints of the admin server, which are meant for
+	// non-production environments.
+	Debug *AdminDebugConfig `yaml:"debug" mapstructure:"debug"`
+}
+
+// AdminDebugConfig configures the debugging endpoints of the admin server.
+type AdminDebugConfig struct {
+	// Requests records the latest inbound requests and their downstream calls, which are served
+	// at /-/debug/requests. Nothing is recorded if it is not set.
+	Requests *RequestRecorderConfig `yaml:"requests" mapstructure:"requests"`
+}
+
+// RequestRecorderConfig configures the recording of the latest inbound HTTP requests.
+type RequestRecorderConfig struct {
+	// Size is the number of the latest requests that are kept. Defaults to 100.
+	Size int `yaml:"size" mapstructure:"size" validate:"min=0"`
+	// MaxBodyBytes is the number of bytes of each request and response body that are kept.
+	// Defaults to 4096.
+	MaxBodyBytes int `yaml:"maxBodyBytes" mapstructure:"maxBodyBytes" validate:"min=0"`
+	// RedactHeaders are the headers whose values are redacted, in addition to Authorization,
+	// Proxy-Authorization, Cookie, Set-Cookie, X-Api-Key and Sysl-Log-Level.
+	RedactHeaders []string `yaml:"redactHeaders" mapstructure:"redactHeaders"`
 }
 
 // LogConfig struct.
@@ -31,6 +56,11 @@ type LogConfig struct {
 
 	// LogPayload logs the contents of request and response objects.
 	LogPayload bool `yaml:"logPayload" mapstructure:"logPayload"`
+
+	// LevelHeaderKey is the key that signs the Sysl-Log-Level header of a request, which then
+	// logs at the level of the header, see core.SignLogLevelHeader. The header is ignored if
+	// there is no key.
+	LevelHeaderKey *SensitiveString `yaml:"levelHeaderKey" mapstructure:"levelHeaderKey"`
 }
 
 // AuthenticationConfig struct.
@@ -43,6 +73,19 @@ type TraceConfig struct {
 	IncomingHeaderForID string `yaml:"incomingHeaderForID" mapstructure:"incomingHeaderForID"`
 }
 
+// DeadlineConfig struct.
+type DeadlineConfig struct {
+	// Header is the name of the header that carries the remaining time budget of the caller
+	// (e.g. X-Request-Timeout). The value is read from incoming requests and sent onwards to
+	// downstream requests. The value is either a duration (e.g. 1500ms) or a whole number of
+	// milliseconds. When empty, no header is read or sent.
+	Header string `yaml:"header" mapstructure:"header"`
+
+	// SafetyMargin is subtracted from the remaining time budget of the caller before it is
+	// applied to downstream calls, leaving time to handle the downstream response.
+	SafetyMargin time.Duration `yaml:"safetyMargin" mapstructure:"safetyMargin"`
+}
+
 func (c *LibraryConfig) Validate() error {
 	// existing validation
 	if err := validator.Validate(c); err != nil {
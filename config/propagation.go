@@ -0,0 +1,28 @@
+package config
+
+// HeaderPropagationConfig controls which headers of an incoming request are sent on to a
+// downstream. When no propagation config is given for a downstream, all incoming headers
+// are sent.
+//
+// The rules are applied in order: Deny, Allow, Rename and finally Set. Header names are
+// case-insensitive.
+//
+// The hop-by-hop headers, such as Connection, and the other headers of the HTTP message,
+// such as Host and Content-Type, are never sent to a gRPC downstream.
+type HeaderPropagationConfig struct {
+	// Allow lists the only incoming headers that are sent. When empty, all headers that are
+	// not denied are sent.
+	Allow []string `yaml:"allow" mapstructure:"allow"`
+
+	// Deny lists incoming headers that are never sent (e.g. Authorization or Cookie).
+	Deny []string `yaml:"deny" mapstructure:"deny"`
+
+	// Rename maps the name of an incoming header to the name it is sent as.
+	Rename map[string]string `yaml:"rename" mapstructure:"rename"`
+
+	// Set maps the name of a header to a text/template that is rendered for each request.
+	// The template can refer to .Claims (the JWT claims of the request), .Header (the
+	// incoming headers) and .TraceID, e.g. "{{.Claims.sub}}" or `{{.Header.Get "X-User"}}`.
+	// A header is not sent if its template refers to a value that does not exist.
+	Set map[string]string `yaml:"set" mapstructure:"set"`
+}
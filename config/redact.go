@@ -0,0 +1,117 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var (
+	sensitiveStringType = reflect.TypeOf(SensitiveString{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	stringerType        = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// Redact returns the config value v as maps keyed by config key, slices and scalars, in which
+// sensitive strings are masked. Durations and other values with a text form are formatted as
+// strings, so that the result can be printed as YAML or JSON and read back as config.
+func Redact(v interface{}) interface{} {
+	return redact(reflect.ValueOf(v))
+}
+
+func redact(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	switch v.Type() {
+	case sensitiveStringType:
+		return v.Interface().(SensitiveString).String()
+	case durationType:
+		return v.Interface().(time.Duration).String()
+	}
+	if v.Type().Implements(textMarshalerType) && v.Kind() != reflect.Ptr {
+		if text, err := v.Interface().(encoding.TextMarshaler).MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redact(v.Elem())
+	case reflect.Struct:
+		m := map[string]interface{}{}
+		redactStruct(v, m)
+		return m
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		m := make(map[string]interface{}, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			m[fmt.Sprint(iter.Key().Interface())] = redact(iter.Value())
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			s[i] = redact(v.Index(i))
+		}
+		return s
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil
+	case reflect.String:
+		return v.String()
+	}
+	if v.Type().Implements(stringerType) {
+		return v.Interface().(fmt.Stringer).String()
+	}
+	return v.Interface()
+}
+
+// redactStruct adds the exported fields of the struct v to m, named as in the config.
+func redactStruct(v reflect.Value, m map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || f.Type.Kind() == reflect.Func {
+			continue
+		}
+		name, squash := configFieldName(f)
+		if squash {
+			fv := reflect.Indirect(v.Field(i))
+			if fv.Kind() == reflect.Struct {
+				redactStruct(fv, m)
+				continue
+			}
+		}
+		m[name] = redact(v.Field(i))
+	}
+}
+
+// configFieldName returns the config key of a struct field and whether its fields are squashed
+// into the parent, following the mapstructure tag, then the yaml tag, then the field name.
+func configFieldName(f reflect.StructField) (string, bool) {
+	for _, tag := range []string{"mapstructure", "yaml"} {
+		if value, has := f.Tag.Lookup(tag); has {
+			parts := strings.Split(value, ",")
+			for _, opt := range parts[1:] {
+				if opt == "squash" || opt == "inline" {
+					return "", true
+				}
+			}
+			if parts[0] != "" {
+				return parts[0], false
+			}
+		}
+	}
+	return f.Name, false
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/anz-bank/sysl-go/log"
+)
+
+func TestRedact(t *testing.T) {
+	t.Parallel()
+
+	type Embedded struct {
+		Port int `mapstructure:"port"`
+	}
+	type app struct {
+		Embedded `mapstructure:",squash"`
+		Password SensitiveString             `mapstructure:"password"`
+		Timeout  time.Duration               `yaml:"timeout"`
+		Level    log.Level                   `mapstructure:"level"`
+		Keys     map[string]*SecretKeyConfig `mapstructure:"keys"`
+		Hosts    []string
+		Missing  *LogConfig  `mapstructure:"missing"`
+		Any      interface{} `mapstructure:"any"`
+		callback func()
+	}
+
+	encoding := SecretKeyEncodingBase64
+	value := NewSensitiveString("c2VjcmV0")
+	redacted := Redact(&app{
+		Embedded: Embedded{Port: 8080},
+		Password: NewSensitiveString("secret"),
+		Timeout:  time.Second,
+		Level:    log.DebugLevel,
+		Keys:     map[string]*SecretKeyConfig{"a": {Encoding: &encoding, Value: &value}},
+		Hosts:    []string{"a", "b"},
+		Any:      &Embedded{Port: 1},
+	})
+
+	require.Equal(t, map[string]interface{}{
+		"port":     8080,
+		"password": DefaultReplacementText,
+		"timeout":  "1s",
+		"level":    "debug",
+		"keys": map[string]interface{}{"a": map[string]interface{}{
+			"encoding":         "base64",
+			"alias":            nil,
+			"keyStore":         nil,
+			"keyStorePassword": nil,
+			"value":            DefaultReplacementText,
+		}},
+		"Hosts":   []interface{}{"a", "b"},
+		"missing": nil,
+		"any":     map[string]interface{}{"port": 1},
+	}, redacted)
+}
@@ -0,0 +1,348 @@
+package config
+
+import (
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anz-bank/sysl-go/jsontime"
+	"github.com/anz-bank/sysl-go/log"
+)
+
+// JSONSchemaDraft is the JSON Schema dialect of the schemas returned by JSONSchema.
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+var (
+	logLevelType     = reflect.TypeOf(log.Level(0))
+	jsonDurationType = reflect.TypeOf(jsontime.Duration(0))
+	timeType         = reflect.TypeOf(time.Time{})
+	jsonTimeType     = reflect.TypeOf(jsontime.Time{})
+	durationPattern  = `^([0-9]+(\.[0-9]*)?(ns|us|µs|ms|s|m|h))+$`
+	// the log levels are decoded in any case and the deprecated ones as the closest level
+	logLevelPattern = anyCasePattern("error", "info", "debug", "panic", "fatal", "warn", "trace")
+)
+
+// anyCasePattern returns a pattern that matches any of the words in any case, as JSON Schema
+// patterns have no case-insensitive flag.
+func anyCasePattern(words ...string) string {
+	alternatives := make([]string, 0, len(words))
+	for _, word := range words {
+		var b strings.Builder
+		for _, r := range word {
+			b.WriteString("[" + strings.ToUpper(string(r)) + strings.ToLower(string(r)) + "]")
+		}
+		alternatives = append(alternatives, b.String())
+	}
+	return "^(" + strings.Join(alternatives, "|") + ")$"
+}
+
+// JSONSchema returns the JSON Schema of the config files that decode into a value of type t.
+// The properties are named by config key and unknown keys are not allowed, like in strict mode.
+// The validate tags of the fields are mapped to constraints where JSON Schema can express them
+// and the description tags to descriptions. The keys that setDefaults, if not nil, gives a
+// default value to are never required.
+func JSONSchema(t reflect.Type, setDefaults func(func(key string, value interface{}))) map[string]interface{} {
+	g := &schemaGenerator{
+		defaults: map[string]interface{}{},
+		defs:     map[string]interface{}{},
+		names:    map[reflect.Type]string{},
+	}
+	if setDefaults != nil {
+		setDefaults(func(key string, value interface{}) {
+			g.defaults[strings.ToLower(key)] = value
+		})
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	var schema map[string]interface{}
+	if t.Kind() == reflect.Struct {
+		schema = g.structSchema(t, "")
+	} else {
+		schema = g.schema(t, "")
+	}
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		properties[IncludeKey] = stringOrArraySchema("The config files to merge before this one, relative to it.")
+		properties[ProfileKey] = stringOrArraySchema("The profiles whose overlays to merge, when none is given on the command line.")
+	}
+	schema["$schema"] = JSONSchemaDraft
+	if len(g.defs) > 0 {
+		schema["$defs"] = g.defs
+	}
+	return schema
+}
+
+func stringOrArraySchema(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"anyOf": []interface{}{
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		},
+	}
+}
+
+type schemaGenerator struct {
+	// defaults are the default values by lower case key.
+	defaults map[string]interface{}
+	defs     map[string]interface{}
+	// names are the names of the named struct types in defs.
+	names map[reflect.Type]string
+}
+
+// schema returns the schema of type t at the config key.
+func (g *schemaGenerator) schema(t reflect.Type, key string) map[string]interface{} {
+	switch t {
+	case sensitiveStringType:
+		return map[string]interface{}{"type": "string", "writeOnly": true}
+	case durationType, jsonDurationType:
+		g.defs["time.Duration"] = map[string]interface{}{
+			"description": "A duration such as 1m30s, or a number of nanoseconds.",
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "string", "pattern": durationPattern},
+				map[string]interface{}{"type": "integer"},
+			},
+		}
+		return map[string]interface{}{"$ref": "#/$defs/time.Duration"}
+	case logLevelType:
+		g.defs["log.Level"] = map[string]interface{}{
+			"description": "A log level: error, info or debug in any case (panic, fatal, warn and trace are deprecated), or the number of a Logrus level.",
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "string", "pattern": logLevelPattern},
+				map[string]interface{}{"type": "integer"},
+			},
+		}
+		return map[string]interface{}{"$ref": "#/$defs/log.Level"}
+	case timeType, jsonTimeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schema(t.Elem(), key)
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string"}
+		}
+		array := map[string]interface{}{"type": "array", "items": g.schema(t.Elem(), "")}
+		if t.Kind() == reflect.Array {
+			return array
+		}
+		// the decoder also splits a string at commas into a slice
+		return map[string]interface{}{"anyOf": []interface{}{map[string]interface{}{"type": "string"}, array}}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem(), "")}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t, key)
+		}
+		// named struct types are defined once, unless one of their keys has a default
+		if !g.hasDefaults(key) {
+			name, has := g.names[t]
+			if !has {
+				name = g.defName(t)
+				g.names[t] = name
+				g.defs[name] = g.structSchema(t, "")
+			}
+			return map[string]interface{}{"$ref": "#/$defs/" + name}
+		}
+		return g.structSchema(t, key)
+	}
+	// interfaces accept any value, functions and channels are not config
+	return map[string]interface{}{}
+}
+
+// hasDefaults returns whether a key under the config key has a default value.
+func (g *schemaGenerator) hasDefaults(key string) bool {
+	prefix := strings.ToLower(key) + "."
+	for k := range g.defaults {
+		if strings.HasPrefix(k, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// defName returns the name of the definition of the named type t, the package name qualified by
+// more of the package path if another type has the same name.
+func (g *schemaGenerator) defName(t reflect.Type) string {
+	pkg := t.PkgPath()
+	name := path.Base(pkg) + "." + t.Name()
+	for _, n := range g.names {
+		if n == name {
+			return strings.ReplaceAll(pkg, "/", ".") + "." + t.Name()
+		}
+	}
+	return name
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type, key string) map[string]interface{} {
+	properties := map[string]interface{}{}
+	var required []interface{}
+	g.addFields(t, key, properties, &required)
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// addFields adds the schemas of the fields of the struct type t to properties.
+func (g *schemaGenerator) addFields(t reflect.Type, key string, properties map[string]interface{}, required *[]interface{}) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, squash := configFieldName(f)
+		if (!f.IsExported() && !squash) || f.Type.Kind() == reflect.Func || f.Type.Kind() == reflect.Chan {
+			continue
+		}
+		if squash {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(ft, key, properties, required)
+				continue
+			}
+		}
+
+		fieldKey := name
+		if key != "" {
+			fieldKey = key + "." + name
+		}
+		schema := g.schema(f.Type, fieldKey)
+		if description := f.Tag.Get("description"); description != "" {
+			schema["description"] = description
+		}
+		def, hasDefault := g.defaults[strings.ToLower(fieldKey)]
+		if hasDefault {
+			schema["default"] = Redact(def)
+		}
+		if applyValidateTag(schema, f.Type, f.Tag.Get("validate")) && !hasDefault {
+			*required = append(*required, name)
+		}
+		properties[name] = schema
+	}
+}
+
+// applyValidateTag adds the constraints of the validate tag of a field of type t to its schema
+// and returns whether the field is required.
+func applyValidateTag(schema map[string]interface{}, t reflect.Type, tag string) bool {
+	if tag == "" || tag == "-" {
+		return false
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	// the constraints of a slice apply to its array form rather than to its string form
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok && t.Kind() == reflect.Slice {
+		return applyValidateTag(anyOf[len(anyOf)-1].(map[string]interface{}), t, tag)
+	}
+
+	// the rules after dive apply to the elements
+	rules := strings.Split(tag, ",")
+	if i := slices.Index(rules, "dive"); i >= 0 {
+		elemTag := strings.Join(rules[i+1:], ",")
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			applyValidateTag(items, t.Elem(), elemTag)
+		} else if values, ok := schema["additionalProperties"].(map[string]interface{}); ok {
+			applyValidateTag(values, t.Elem(), elemTag)
+		}
+		rules = rules[:i]
+	}
+
+	required, omitempty := false, false
+	schemaType, _ := schema["type"].(string)
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "omitempty":
+			omitempty = true
+		case "required", "nonnil":
+			required = true
+			if schemaType == "string" {
+				schema["minLength"] = 1
+			}
+		case "min", "max", "len":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			bounds := []string{name}
+			if name == "len" {
+				bounds = []string{"min", "max"}
+			}
+			for _, bound := range bounds {
+				switch schemaType {
+				case "integer", "number":
+					schema[bound+"imum"] = n
+				case "string":
+					schema[bound+"Length"] = int(n)
+				case "array":
+					schema[bound+"Items"] = int(n)
+				case "object":
+					schema[bound+"Properties"] = int(n)
+				}
+			}
+		case "oneof":
+			var enum []interface{}
+			if omitempty && schemaType == "string" {
+				enum = append(enum, "")
+			}
+			for _, value := range strings.Fields(param) {
+				if schemaType == "integer" || schemaType == "number" {
+					if n, err := strconv.ParseFloat(value, 64); err == nil {
+						enum = append(enum, n)
+					}
+				} else {
+					enum = append(enum, value)
+				}
+			}
+			schema["enum"] = enum
+		case "startswith", "endswith", "contains", "alpha", "alphanum", "numeric":
+			if schemaType != "string" {
+				continue
+			}
+			pattern := map[string]string{
+				"startswith": "^" + regexp.QuoteMeta(param),
+				"endswith":   regexp.QuoteMeta(param) + "$",
+				"contains":   regexp.QuoteMeta(param),
+				"alpha":      "^[a-zA-Z]+$",
+				"alphanum":   "^[a-zA-Z0-9]+$",
+				"numeric":    "^[-+]?[0-9]+(\\.[0-9]+)?$",
+			}[name]
+			if omitempty {
+				pattern = "^$|" + pattern
+			}
+			schema["pattern"] = pattern
+		case "url", "uri":
+			if schemaType == "string" {
+				schema["format"] = "uri"
+			}
+		case "email":
+			if schemaType == "string" {
+				schema["format"] = "email"
+			}
+		}
+	}
+	return required
+}
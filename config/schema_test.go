@@ -0,0 +1,138 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/anz-bank/sysl-go/log"
+)
+
+type schemaTestServer struct {
+	Port     int              `mapstructure:"port" validate:"min=0,max=65534"`
+	BasePath string           `mapstructure:"basePath" validate:"omitempty,startswith=/"`
+	Weights  []int            `mapstructure:"weights" validate:"min=1,dive,min=1"`
+	Password *SensitiveString `mapstructure:"password"`
+}
+
+type schemaTestConfig struct {
+	Name               string            `mapstructure:"name" validate:"required" description:"The name of the service."`
+	Mode               string            `mapstructure:"mode" validate:"oneof=fast safe"`
+	Timeout            time.Duration     `mapstructure:"timeout" validate:"nonnil"`
+	Public             schemaTestServer  `mapstructure:"public"`
+	Admin              *schemaTestServer `mapstructure:"admin"`
+	Labels             map[string]string `mapstructure:"labels"`
+	Any                interface{}       `mapstructure:"any"`
+	Level              log.Level         `mapstructure:"level"`
+	Tags               []string          `mapstructure:"tags"`
+	schemaTestEmbedded `mapstructure:",squash"`
+}
+
+type schemaTestEmbedded struct {
+	Region string `mapstructure:"region"`
+}
+
+func TestJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	schema := JSONSchema(reflect.TypeOf(&schemaTestConfig{}), func(set func(string, interface{})) {
+		set("Mode", "safe")
+		set("Timeout", 5*time.Second)
+	})
+	b, err := json.Marshal(schema)
+	require.NoError(t, err)
+
+	require.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"additionalProperties": false,
+		"required": ["name"],
+		"properties": {
+			"name": {"type": "string", "minLength": 1, "description": "The name of the service."},
+			"mode": {"type": "string", "enum": ["fast", "safe"], "default": "safe"},
+			"timeout": {"$ref": "#/$defs/time.Duration", "default": "5s"},
+			"public": {"$ref": "#/$defs/config.schemaTestServer"},
+			"admin": {"$ref": "#/$defs/config.schemaTestServer"},
+			"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+			"any": {},
+			"level": {"$ref": "#/$defs/log.Level"},
+			"tags": {"anyOf": [{"type": "string"}, {"type": "array", "items": {"type": "string"}}]},
+			"region": {"type": "string"},
+			"include": {
+				"description": "The config files to merge before this one, relative to it.",
+				"anyOf": [{"type": "string"}, {"type": "array", "items": {"type": "string"}}]
+			},
+			"profile": {
+				"description": "The profiles whose overlays to merge, when none is given on the command line.",
+				"anyOf": [{"type": "string"}, {"type": "array", "items": {"type": "string"}}]
+			}
+		},
+		"$defs": {
+			"time.Duration": {
+				"description": "A duration such as 1m30s, or a number of nanoseconds.",
+				"anyOf": [
+					{"type": "string", "pattern": "^([0-9]+(\\.[0-9]*)?(ns|us|µs|ms|s|m|h))+$"},
+					{"type": "integer"}
+				]
+			},
+			"log.Level": {
+				"description": "A log level: error, info or debug in any case (panic, fatal, warn and trace are deprecated), or the number of a Logrus level.",
+				"anyOf": [
+					{"type": "string", "pattern": "^([Ee][Rr][Rr][Oo][Rr]|[Ii][Nn][Ff][Oo]|[Dd][Ee][Bb][Uu][Gg]|[Pp][Aa][Nn][Ii][Cc]|[Ff][Aa][Tt][Aa][Ll]|[Ww][Aa][Rr][Nn]|[Tt][Rr][Aa][Cc][Ee])$"},
+					{"type": "integer"}
+				]
+			},
+			"config.schemaTestServer": {
+				"type": "object",
+				"additionalProperties": false,
+				"properties": {
+					"port": {"type": "integer", "minimum": 0, "maximum": 65534},
+					"basePath": {"type": "string", "pattern": "^$|^/"},
+					"weights": {"anyOf": [
+						{"type": "string"},
+						{"type": "array", "minItems": 1, "items": {"type": "integer", "minimum": 1}}
+					]},
+					"password": {"type": "string", "writeOnly": true}
+				}
+			}
+		}
+	}`, string(b))
+}
+
+func TestJSONSchemaOfDefaultConfig(t *testing.T) {
+	t.Parallel()
+
+	schema := JSONSchema(reflect.TypeOf(DefaultConfig{}), SetDefaults)
+	properties := schema["properties"].(map[string]interface{})
+	require.Contains(t, properties, "library")
+	require.Contains(t, properties, "genCode")
+
+	// the keys with defaults are inlined to show them
+	library := properties["library"].(map[string]interface{})
+	level := library["properties"].(map[string]interface{})["log"].(map[string]interface{})["properties"].(map[string]interface{})["level"]
+	require.Equal(t, "info", level.(map[string]interface{})["default"])
+
+	// the fields are described by their description tags
+	require.Equal(t, "The config of the library features of the service.", library["description"])
+	profiling := library["properties"].(map[string]interface{})["profiling"]
+	require.Equal(t, "Serves the pprof profiles on the admin server.", profiling.(map[string]interface{})["description"])
+	server := schema["$defs"].(map[string]interface{})["config.CommonServerConfig"].(map[string]interface{})
+	port := server["properties"].(map[string]interface{})["port"]
+	require.Equal(t, "The port the server listens on.", port.(map[string]interface{})["description"])
+}
+
+func TestJSONSchemaLogLevelPattern(t *testing.T) {
+	t.Parallel()
+
+	pattern := regexp.MustCompile(logLevelPattern)
+	for _, level := range []string{"error", "INFO", "Debug", "warn", "Trace", "PANIC", "fatal"} {
+		require.Regexp(t, pattern, level)
+	}
+	for _, level := range []string{"", "verbose", "infos", "error,debug"} {
+		require.NotRegexp(t, pattern, level)
+	}
+}
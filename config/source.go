@@ -0,0 +1,55 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// SourceKind is the kind of source of a config value.
+type SourceKind string
+
+const (
+	// SourceDefault is the default value of a key, see ConfigReaderBuilder.WithDefaults.
+	SourceDefault SourceKind = "default"
+	// SourceFile is a config file.
+	SourceFile SourceKind = "file"
+	// SourceEnv is an environment variable, see ConfigReaderBuilder.AttachEnvPrefix.
+	SourceEnv SourceKind = "env"
+	// SourceOverride is an override, such as a command-line flag, see
+	// ConfigReaderBuilder.WithOverride.
+	SourceOverride SourceKind = "override"
+)
+
+// Source is where a config value comes from.
+type Source struct {
+	Kind SourceKind `json:"kind" yaml:"kind"`
+	// File, Line and Column locate the key in the config file of a SourceFile value.
+	File   string `json:"file,omitempty" yaml:"file,omitempty"`
+	Line   int    `json:"line,omitempty" yaml:"line,omitempty"`
+	Column int    `json:"column,omitempty" yaml:"column,omitempty"`
+	// EnvVar is the environment variable of a SourceEnv value.
+	EnvVar string `json:"envVar,omitempty" yaml:"envVar,omitempty"`
+}
+
+// Source returns where the value of the key comes from, following the precedence of
+// ConfigReaderBuilder, or false if the key has no value.
+func (m configReaderImpl) Source(key string) (Source, bool) {
+	key = strings.ToLower(key)
+	if _, has := m.overrides[key]; has {
+		return Source{Kind: SourceOverride}, true
+	}
+	if m.envPrefix != "" {
+		// viper ignores empty environment variables
+		envVar := strings.ToUpper(strings.ReplaceAll(m.envPrefix+"_"+key, ".", "_"))
+		if value, has := os.LookupEnv(envVar); has && value != "" {
+			return Source{Kind: SourceEnv, EnvVar: envVar}, true
+		}
+	}
+	if source, has := m.sources[key]; has {
+		return Source{Kind: SourceFile, File: source.File, Line: source.Line, Column: source.Column}, true
+	}
+	if _, has := m.defaults[key]; has {
+		return Source{Kind: SourceDefault}, true
+	}
+	return Source{}, false
+}
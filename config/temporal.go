@@ -1,12 +1,129 @@
 package config
 
+import (
+	"time"
+)
+
 type CommonTemporalDownstreamData struct {
-	HostPort  string `yaml:"hostPort" mapstructure:"hostPort"`
-	Identity  string `yaml:"identity" mapstructure:"identity"`
-	Namespace string `yaml:"namespace" mapstructure:"namespace"`
+	HostPort                 string `yaml:"hostPort" mapstructure:"hostPort"`
+	Identity                 string `yaml:"identity" mapstructure:"identity"`
+	Namespace                string `yaml:"namespace" mapstructure:"namespace"`
+	TemporalConnectionConfig `yaml:",inline" mapstructure:",squash"`
 }
 
 type TemporalServerConfig struct {
-	HostPort  string `yaml:"hostPort" mapstructure:"hostPort"`
-	Namespace string `yaml:"namespace" mapstructure:"namespace"`
+	HostPort                 string `yaml:"hostPort" mapstructure:"hostPort"`
+	Namespace                string `yaml:"namespace" mapstructure:"namespace"`
+	TemporalConnectionConfig `yaml:",inline" mapstructure:",squash"`
+
+	// Worker tunes the concurrency of the worker. Zero values use the SDK defaults.
+	Worker TemporalWorkerConfig `yaml:"worker" mapstructure:"worker"`
+}
+
+// TemporalWorkerConfig configures the concurrency and pollers of a Temporal worker.
+type TemporalWorkerConfig struct {
+	MaxConcurrentActivityExecutionSize      int `yaml:"maxConcurrentActivityExecutionSize" mapstructure:"maxConcurrentActivityExecutionSize" validate:"min=0"`
+	MaxConcurrentLocalActivityExecutionSize int `yaml:"maxConcurrentLocalActivityExecutionSize" mapstructure:"maxConcurrentLocalActivityExecutionSize" validate:"min=0"`
+	MaxConcurrentWorkflowTaskExecutionSize  int `yaml:"maxConcurrentWorkflowTaskExecutionSize" mapstructure:"maxConcurrentWorkflowTaskExecutionSize" validate:"min=0"`
+	MaxConcurrentActivityTaskPollers        int `yaml:"maxConcurrentActivityTaskPollers" mapstructure:"maxConcurrentActivityTaskPollers" validate:"min=0"`
+	MaxConcurrentWorkflowTaskPollers        int `yaml:"maxConcurrentWorkflowTaskPollers" mapstructure:"maxConcurrentWorkflowTaskPollers" validate:"min=0"`
+
+	// WorkerActivitiesPerSecond limits the rate at which this worker starts activities.
+	WorkerActivitiesPerSecond float64 `yaml:"workerActivitiesPerSecond" mapstructure:"workerActivitiesPerSecond" validate:"min=0"`
+	// TaskQueueActivitiesPerSecond limits the rate at which all workers of the task queue start activities.
+	TaskQueueActivitiesPerSecond float64 `yaml:"taskQueueActivitiesPerSecond" mapstructure:"taskQueueActivitiesPerSecond" validate:"min=0"`
+
+	StickyScheduleToStartTimeout time.Duration `yaml:"stickyScheduleToStartTimeout" mapstructure:"stickyScheduleToStartTimeout" validate:"min=0"`
+	// WorkerStopTimeout is how long running activities are given to finish when the worker stops.
+	WorkerStopTimeout time.Duration `yaml:"workerStopTimeout" mapstructure:"workerStopTimeout" validate:"min=0"`
+	// EnableSessionWorker enables the session API for activities.
+	EnableSessionWorker bool `yaml:"enableSessionWorker" mapstructure:"enableSessionWorker"`
+}
+
+// TemporalConnectionConfig configures the connection to a Temporal frontend.
+type TemporalConnectionConfig struct {
+	// TLS enables TLS for the connection. Client certificates given in serverIdentities are
+	// presented to the frontend for mTLS.
+	TLS *TLSConfig `yaml:"tls" mapstructure:"tls"`
+
+	// APIKey is sent as a bearer token with every request (e.g. for Temporal Cloud). TLS is
+	// enabled automatically when an API key is set.
+	APIKey *SensitiveString `yaml:"apiKey" mapstructure:"apiKey"`
+
+	// Headers are sent with every request, e.g. to authenticate with a proxy in front of the
+	// frontend.
+	Headers map[string]*SensitiveString `yaml:"headers" mapstructure:"headers"`
+
+	// Keepalive configures the keepalive pings of the connection. Nil uses the SDK defaults.
+	Keepalive *TemporalKeepaliveConfig `yaml:"keepalive" mapstructure:"keepalive"`
+
+	// Authority overrides the :authority pseudo-header. Only used without TLS.
+	Authority string `yaml:"authority" mapstructure:"authority"`
+
+	// MaxPayloadSize limits the size of messages in bytes. Zero uses the SDK default.
+	MaxPayloadSize int `yaml:"maxPayloadSize" mapstructure:"maxPayloadSize" validate:"min=0"`
+
+	// PropagateHeaders lists the headers of the request that starts a workflow that are made
+	// available to the workflow and its activities, in addition to the trace ID.
+	PropagateHeaders []string `yaml:"propagateHeaders" mapstructure:"propagateHeaders"`
+
+	// Codec configures how workflow and activity payloads are encoded. Nil uses the SDK defaults.
+	Codec *TemporalCodecConfig `yaml:"codec" mapstructure:"codec"`
+}
+
+// TemporalKeepaliveConfig configures the keepalive pings sent to a Temporal frontend.
+type TemporalKeepaliveConfig struct {
+	Disable bool          `yaml:"disable" mapstructure:"disable"`
+	Time    time.Duration `yaml:"time" mapstructure:"time" validate:"min=0"`
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout" validate:"min=0"`
+	// DisablePermitWithoutStream stops pings while there are no active calls.
+	DisablePermitWithoutStream bool `yaml:"disablePermitWithoutStream" mapstructure:"disablePermitWithoutStream"`
+}
+
+// TemporalCodecConfig configures the encoding of the payloads sent to the Temporal frontend.
+// The same config must be used by every client and worker of a workflow.
+type TemporalCodecConfig struct {
+	// Encryption encrypts payloads with AES-GCM.
+	Encryption *TemporalEncryptionConfig `yaml:"encryption" mapstructure:"encryption"`
+
+	// Compress compresses payloads with zlib (before they are encrypted).
+	Compress bool `yaml:"compress" mapstructure:"compress"`
+
+	// ProtoJSON configures how protobuf messages are converted to JSON.
+	ProtoJSON *TemporalProtoJSONConfig `yaml:"protoJSON" mapstructure:"protoJSON"`
+
+	// EncodeFailureAttributes moves error messages and stack traces into an encoded payload so
+	// that they are encrypted too.
+	EncodeFailureAttributes bool `yaml:"encodeFailureAttributes" mapstructure:"encodeFailureAttributes"`
+
+	// Server serves the codec on the admin server of a worker at /-/temporal/codec, so that the
+	// Temporal UI and CLI can decode the payloads. Nil does not serve it.
+	Server *TemporalCodecServerConfig `yaml:"server" mapstructure:"server"`
+}
+
+// TemporalCodecServerConfig configures the codec server. Its requests are authorized by the
+// temporal rule of admin.auth, and decoded payloads are only served to authorized requests.
+type TemporalCodecServerConfig struct {
+	// UIOrigin is the origin of the Temporal UI that calls the codec server from the browser,
+	// e.g. https://cloud.temporal.io. Cross-origin requests are refused if it is empty.
+	UIOrigin string `yaml:"uiOrigin" mapstructure:"uiOrigin" validate:"omitempty,url"`
+}
+
+// TemporalEncryptionConfig configures the keys that encrypt payloads. Payloads are encrypted with
+// the key named by KeyID and the ID is stored with the payload, so keys can be rotated by adding
+// a new key, switching KeyID to it and removing the old key once no payload uses it any more.
+type TemporalEncryptionConfig struct {
+	// KeyID names the key in Keys that encrypts new payloads.
+	KeyID string `yaml:"keyID" mapstructure:"keyID" validate:"required"`
+	// Keys maps key IDs to AES keys of 16, 24 or 32 bytes.
+	Keys map[string]*SecretKeyConfig `yaml:"keys" mapstructure:"keys" validate:"min=1"`
+}
+
+// TemporalProtoJSONConfig configures the conversion of protobuf messages to and from JSON.
+type TemporalProtoJSONConfig struct {
+	ExcludeProtobufMessageTypes bool `yaml:"excludeProtobufMessageTypes" mapstructure:"excludeProtobufMessageTypes"`
+	AllowUnknownFields          bool `yaml:"allowUnknownFields" mapstructure:"allowUnknownFields"`
+	UseProtoNames               bool `yaml:"useProtoNames" mapstructure:"useProtoNames"`
+	UseEnumNumbers              bool `yaml:"useEnumNumbers" mapstructure:"useEnumNumbers"`
+	EmitUnpopulated             bool `yaml:"emitUnpopulated" mapstructure:"emitUnpopulated"`
 }
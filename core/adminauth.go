@@ -0,0 +1,245 @@
+package core
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/anz-bank/sysl-go/core/authrules"
+	"github.com/anz-bank/sysl-go/jwtauth"
+	"github.com/anz-bank/sysl-go/log"
+)
+
+type adminAuthKey struct{}
+
+// adminAuth authorizes the requests to the admin server, see config.AdminAuthConfig.
+type adminAuth struct {
+	routes map[string]*adminAuthRule
+	def    *adminAuthRule
+	hook   func(r *http.Request) error
+}
+
+type adminAuthRule struct {
+	open    bool
+	basic   bool
+	methods []adminAuthMethod
+}
+
+// adminAuthMethod authenticates a request, returning the context of the request, e.g. with the
+// claims of its token, if it succeeds.
+type adminAuthMethod func(r *http.Request) (context.Context, error)
+
+// withAdminAuth returns a context holding the authorization of the requests to the admin
+// server configured by cfg.Admin.Auth and Hooks.AuthorizeAdminRequest.
+func withAdminAuth(ctx context.Context, hooks *Hooks, cfg *config.DefaultConfig) (context.Context, error) {
+	var auth *config.AdminAuthConfig
+	if cfg != nil && cfg.Admin != nil {
+		auth = cfg.Admin.Auth
+	}
+	var authenticator jwtauth.Authenticator
+	if usesJWT(auth) {
+		if cfg.Library.Authentication == nil || cfg.Library.Authentication.JWTAuth == nil {
+			return nil, errors.New("admin.auth has a jwt rule, but there is no config for library.authentication.jwtauth")
+		}
+		httpClient, err := config.DefaultHTTPClient(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		authenticator, err = jwtauth.AuthFromConfig(ctx, cfg.Library.Authentication.JWTAuth, func(string) *http.Client { return httpClient })
+		if err != nil {
+			return nil, err
+		}
+	}
+	a, err := newAdminAuth(auth, hooks, authenticator)
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, adminAuthKey{}, a), nil
+}
+
+func usesJWT(auth *config.AdminAuthConfig) bool {
+	if auth == nil {
+		return false
+	}
+	if auth.Default != nil && auth.Default.JWT != "" {
+		return true
+	}
+	for _, rule := range auth.Routes {
+		if rule.JWT != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// newAdminAuth returns the authorization of the admin server by auth, which may be nil, and the
+// hooks, authenticating the tokens of the jwt rules with authenticator.
+func newAdminAuth(auth *config.AdminAuthConfig, hooks *Hooks, authenticator jwtauth.Authenticator) (*adminAuth, error) {
+	a := &adminAuth{routes: map[string]*adminAuthRule{}}
+	if hooks != nil {
+		a.hook = hooks.AuthorizeAdminRequest
+	}
+	if auth == nil {
+		return a, nil
+	}
+	var err error
+	if auth.Default != nil {
+		if a.def, err = newAdminAuthRule(*auth.Default, hooks, authenticator); err != nil {
+			return nil, fmt.Errorf("admin.auth.default: %w", err)
+		}
+	}
+	for group, rule := range auth.Routes {
+		if a.routes[group], err = newAdminAuthRule(rule, hooks, authenticator); err != nil {
+			return nil, fmt.Errorf("admin.auth.routes.%s: %w", group, err)
+		}
+	}
+	return a, nil
+}
+
+func newAdminAuthRule(rule config.AdminAuthRule, hooks *Hooks, authenticator jwtauth.Authenticator) (*adminAuthRule, error) {
+	r := &adminAuthRule{open: rule.Open}
+	if rule.MTLS != nil {
+		r.methods = append(r.methods, mtlsAuth(rule.MTLS.Identities))
+	}
+	if rule.JWT != "" {
+		makeRule := authrules.MakeDefaultJWTClaimsBasedAuthorizationRule
+		if hooks != nil && hooks.OverrideMakeJWTClaimsBasedAuthorizationRule != nil {
+			makeRule = hooks.OverrideMakeJWTClaimsBasedAuthorizationRule
+		}
+		claimsRule, err := makeRule(rule.JWT)
+		if err != nil {
+			return nil, err
+		}
+		r.methods = append(r.methods, jwtAuth(claimsRule, authenticator))
+	}
+	if rule.Basic != nil {
+		if rule.Basic.Password.Value() == "" {
+			return nil, errors.New("basic auth has no password")
+		}
+		r.basic = true
+		r.methods = append(r.methods, basicAuth(rule.Basic.Username, rule.Basic.Password.Value()))
+	}
+	return r, nil
+}
+
+// mtlsAuth authenticates the verified client certificates of one of the identities, or of any
+// identity if there are none.
+func mtlsAuth(identities []string) adminAuthMethod {
+	return func(r *http.Request) (context.Context, error) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+			return nil, errors.New("no verified client certificate")
+		}
+		if len(identities) == 0 {
+			return r.Context(), nil
+		}
+		cert := r.TLS.VerifiedChains[0][0]
+		names := append([]string{cert.Subject.CommonName}, cert.DNSNames...)
+		for _, uri := range cert.URIs {
+			names = append(names, uri.String())
+		}
+		for _, name := range names {
+			for _, identity := range identities {
+				if name != "" && name == identity {
+					return r.Context(), nil
+				}
+			}
+		}
+		return nil, fmt.Errorf("client certificate of %s is not authorized", cert.Subject.CommonName)
+	}
+}
+
+// jwtAuth authenticates the bearer tokens whose claims are authorized by claimsRule.
+func jwtAuth(claimsRule authrules.JWTClaimsBasedAuthorizationRule, authenticator jwtauth.Authenticator) adminAuthMethod {
+	return func(r *http.Request) (context.Context, error) {
+		header := r.Header.Get("Authorization")
+		if len(header) < 8 || !strings.EqualFold(header[:7], "bearer ") {
+			return nil, errors.New("no bearer token")
+		}
+		claims, err := authenticator.Authenticate(r.Context(), header[7:])
+		if err != nil {
+			return nil, err
+		}
+		authorized, err := claimsRule(r.Context(), claims)
+		if err != nil {
+			return nil, err
+		}
+		if !authorized {
+			return nil, errors.New("token claims are not authorized")
+		}
+		return jwtauth.AddClaimsToContext(r.Context(), claims), nil
+	}
+}
+
+// basicAuth authenticates the basic auth credentials of username and password.
+func basicAuth(username, password string) adminAuthMethod {
+	return func(r *http.Request) (context.Context, error) {
+		u, p, ok := r.BasicAuth()
+		if !ok {
+			return nil, errors.New("no basic auth credentials")
+		}
+		// evaluate both comparisons to not reveal which one failed
+		validUser := subtle.ConstantTimeCompare([]byte(u), []byte(username))
+		validPassword := subtle.ConstantTimeCompare([]byte(p), []byte(password))
+		if validUser&validPassword != 1 {
+			return nil, errors.New("invalid basic auth credentials")
+		}
+		return r.Context(), nil
+	}
+}
+
+// authorizeAdmin returns a middleware that serves the requests to the admin route group that
+// are authorized by the rule of the group, see config.AdminAuthConfig. If the group has no rule,
+// the sensitive routes are only served to the requests authorized by Hooks.AuthorizeAdminRequest
+// and the others are open.
+func authorizeAdmin(ctx context.Context, group string, sensitive bool) func(http.Handler) http.Handler {
+	a, _ := ctx.Value(adminAuthKey{}).(*adminAuth)
+	if a == nil {
+		a = &adminAuth{}
+	}
+	rule := a.routes[group]
+	if rule == nil {
+		rule = a.def
+	}
+	if rule == nil && !sensitive || rule != nil && rule.open {
+		return func(next http.Handler) http.Handler { return next }
+	}
+	if rule == nil {
+		rule = &adminAuthRule{}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(rule.methods) == 0 && a.hook == nil {
+				http.Error(w, "admin authorization is not configured, see admin.auth or Hooks.AuthorizeAdminRequest", http.StatusForbidden)
+				return
+			}
+			var reasons []string
+			for _, method := range rule.methods {
+				ctx, err := method(r)
+				if err == nil {
+					next.ServeHTTP(w, r.WithContext(ctx))
+					return
+				}
+				reasons = append(reasons, err.Error())
+			}
+			if a.hook != nil {
+				err := a.hook(r)
+				if err == nil {
+					next.ServeHTTP(w, r)
+					return
+				}
+				reasons = append(reasons, err.Error())
+			}
+			log.Infof(r.Context(), "admin request to %s denied: %s", r.URL.Path, strings.Join(reasons, "; "))
+			if rule.basic {
+				w.Header().Set("WWW-Authenticate", `Basic realm="admin", charset="UTF-8"`)
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/anz-bank/sysl-go/jwtauth/jwttest"
+	"github.com/anz-bank/sysl-go/log"
+)
+
+// serveAdmin returns the status of a request to the admin route group served with auth.
+func serveAdmin(t *testing.T, auth *adminAuth, group string, sensitive bool, req *http.Request) *httptest.ResponseRecorder {
+	t.Helper()
+	ctx := context.WithValue(context.Background(), adminAuthKey{}, auth)
+	r := chi.NewRouter()
+	r.With(authorizeAdmin(ctx, group, sensitive)).Get("/", func(w http.ResponseWriter, r *http.Request) {})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req.WithContext(log.PutLogger(req.Context(), log.NewDefaultLogger())))
+	return w
+}
+
+func TestAuthorizeAdminWithoutRules(t *testing.T) {
+	t.Parallel()
+
+	auth, err := newAdminAuth(nil, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, serveAdmin(t, auth, config.AdminRouteMetrics, false, httptest.NewRequest(http.MethodGet, "/", nil)).Code)
+	w := serveAdmin(t, auth, config.AdminRouteConfig, true, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Contains(t, w.Body.String(), "admin.auth or Hooks.AuthorizeAdminRequest")
+
+	auth, err = newAdminAuth(nil, &Hooks{AuthorizeAdminRequest: func(r *http.Request) error { return nil }}, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, serveAdmin(t, auth, config.AdminRouteConfig, true, httptest.NewRequest(http.MethodGet, "/", nil)).Code)
+}
+
+func TestAuthorizeAdminRules(t *testing.T) {
+	t.Parallel()
+
+	issuer, err := jwttest.NewIssuer("test", 2048)
+	require.NoError(t, err)
+	auth, err := newAdminAuth(&config.AdminAuthConfig{
+		Default: &config.AdminAuthRule{
+			Basic: &config.AdminBasicAuth{Username: "admin", Password: config.NewSensitiveString("secret")},
+		},
+		Routes: map[string]config.AdminAuthRule{
+			config.AdminRouteMetrics:   {Open: true},
+			config.AdminRouteProfiling: {MTLS: &config.AdminMTLSAuth{Identities: []string{"ops"}}},
+			config.AdminRouteConfig:    {JWT: `jwtHasScope("admin")`},
+		},
+	}, &Hooks{AuthorizeAdminRequest: func(r *http.Request) error {
+		if r.Header.Get("X-Admin") == "" {
+			return errors.New("not an admin")
+		}
+		return nil
+	}}, issuer.Authenticator())
+	require.NoError(t, err)
+
+	request := func(setup func(r *http.Request)) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if setup != nil {
+			setup(r)
+		}
+		return r
+	}
+	status := func(group string, setup func(r *http.Request)) int {
+		return serveAdmin(t, auth, group, false, request(setup)).Code
+	}
+
+	// an open rule
+	require.Equal(t, http.StatusOK, status(config.AdminRouteMetrics, nil))
+
+	// the default rule, which challenges the requests for basic auth credentials
+	w := serveAdmin(t, auth, config.AdminRouteStatus, false, request(nil))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Contains(t, w.Header().Get("WWW-Authenticate"), "Basic")
+	require.Equal(t, http.StatusUnauthorized, status(config.AdminRouteStatus, func(r *http.Request) { r.SetBasicAuth("admin", "wrong") }))
+	require.Equal(t, http.StatusOK, status(config.AdminRouteStatus, func(r *http.Request) { r.SetBasicAuth("admin", "secret") }))
+
+	// mTLS
+	withCert := func(cert *x509.Certificate) func(r *http.Request) {
+		return func(r *http.Request) {
+			r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+		}
+	}
+	require.Equal(t, http.StatusForbidden, status(config.AdminRouteProfiling, nil))
+	require.Equal(t, http.StatusForbidden, status(config.AdminRouteProfiling, withCert(&x509.Certificate{Subject: pkix.Name{CommonName: "dev"}})))
+	require.Equal(t, http.StatusOK, status(config.AdminRouteProfiling, withCert(&x509.Certificate{Subject: pkix.Name{CommonName: "ops"}})))
+	require.Equal(t, http.StatusOK, status(config.AdminRouteProfiling, withCert(&x509.Certificate{DNSNames: []string{"ops"}})))
+
+	// JWT with a required scope
+	bearer := func(scope string) func(r *http.Request) {
+		token, err := issuer.IssueFromMap(map[string]interface{}{"scope": scope})
+		require.NoError(t, err)
+		return func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) }
+	}
+	require.Equal(t, http.StatusForbidden, status(config.AdminRouteConfig, nil))
+	require.Equal(t, http.StatusForbidden, status(config.AdminRouteConfig, bearer("read")))
+	require.Equal(t, http.StatusOK, status(config.AdminRouteConfig, bearer("read admin")))
+
+	// the hook authorizes the requests of every rule that is not open
+	require.Equal(t, http.StatusOK, status(config.AdminRouteConfig, func(r *http.Request) { r.Header.Set("X-Admin", "yes") }))
+}
+
+func TestNewAdminAuthErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := newAdminAuth(&config.AdminAuthConfig{
+		Routes: map[string]config.AdminAuthRule{config.AdminRouteLog: {JWT: `jwtHasScope(`}},
+	}, nil, nil)
+	require.ErrorContains(t, err, "admin.auth.routes.log: ")
+
+	_, err = newAdminAuth(&config.AdminAuthConfig{
+		Default: &config.AdminAuthRule{Basic: &config.AdminBasicAuth{Username: "admin"}},
+	}, nil, nil)
+	require.EqualError(t, err, "admin.auth.default: basic auth has no password")
+
+	_, err = withAdminAuth(context.Background(), nil, &config.DefaultConfig{Admin: &config.AdminConfig{Auth: &config.AdminAuthConfig{
+		Default: &config.AdminAuthRule{JWT: `jwtHasScope("admin")`},
+	}}})
+	require.EqualError(t, err, "admin.auth has a jwt rule, but there is no config for library.authentication.jwtauth")
+}
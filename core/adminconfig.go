@@ -0,0 +1,108 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/anz-bank/sysl-go/log"
+)
+
+type customConfigKey struct{}
+
+// loadedConfig is the config loaded by LoadCustomConfig and its reader.
+type loadedConfig struct {
+	config interface{}
+	reader config.ConfigReader
+}
+
+// withCustomConfig returns a context holding the config loaded by LoadCustomConfig and its
+// reader, which the admin server describes.
+func withCustomConfig(ctx context.Context, customConfig interface{}, reader config.ConfigReader) context.Context {
+	return context.WithValue(ctx, customConfigKey{}, loadedConfig{customConfig, reader})
+}
+
+func getCustomConfig(ctx context.Context) interface{} {
+	loaded, _ := ctx.Value(customConfigKey{}).(loadedConfig)
+	return loaded.config
+}
+
+func getConfigReader(ctx context.Context) config.ConfigReader {
+	loaded, _ := ctx.Value(customConfigKey{}).(loadedConfig)
+	return loaded.reader
+}
+
+// customConfigSchema returns the JSON Schema of the config files of customConfig.
+func customConfigSchema(customConfig interface{}) map[string]interface{} {
+	schema := config.JSONSchema(reflect.TypeOf(customConfig), config.SetDefaults)
+	if properties, ok := schema["properties"].(map[string]interface{}); ok {
+		properties["envPrefix"] = map[string]interface{}{
+			"type":        "string",
+			"description": "The prefix of the environment variables that override config values.",
+		}
+	}
+	return schema
+}
+
+// effectiveConfig is the effective config served at /config.
+type effectiveConfig struct {
+	// Config is the redacted config, see config.Redact.
+	Config interface{} `json:"config"`
+	// Sources are the sources of the values of Config by key.
+	Sources map[string]config.Source `json:"sources"`
+}
+
+// newEffectiveConfig returns the redacted customConfig and the sources of its values.
+func newEffectiveConfig(customConfig interface{}, reader config.ConfigReader) effectiveConfig {
+	redacted := config.Redact(customConfig)
+	sources := map[string]config.Source{}
+	if reader != nil {
+		var addSources func(key string, value interface{})
+		addSources = func(key string, value interface{}) {
+			if m, ok := value.(map[string]interface{}); ok {
+				for k, v := range m {
+					if key != "" {
+						k = key + "." + k
+					}
+					addSources(k, v)
+				}
+				return
+			}
+			if source, has := reader.Source(key); has {
+				sources[key] = source
+			}
+		}
+		addSources("", redacted)
+	}
+	return effectiveConfig{Config: redacted, Sources: sources}
+}
+
+// registerConfigHandlers serves the JSON Schema of the config of the context at /config/schema
+// and the effective config, redacted, with the source of each value at /config. The effective
+// config is only served to the requests authorized by the admin.auth rule of the config routes,
+// see authorizeAdmin.
+func registerConfigHandlers(ctx context.Context, r chi.Router) {
+	customConfig := getCustomConfig(ctx)
+	if customConfig == nil {
+		return
+	}
+	schema := customConfigSchema(customConfig)
+	r.With(authorizeAdmin(ctx, config.AdminRouteConfig, false)).Get("/config/schema", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/schema+json")
+		if err := json.NewEncoder(w).Encode(schema); err != nil {
+			log.Error(r.Context(), err, "failed to write config schema")
+		}
+	})
+
+	effective := newEffectiveConfig(customConfig, getConfigReader(ctx))
+	r.With(authorizeAdmin(ctx, config.AdminRouteConfig, true)).Get("/config", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(effective); err != nil {
+			log.Error(r.Context(), err, "failed to write config")
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/anz-bank/sysl-go/log"
+)
+
+func TestRegisterConfigHandlersServesSchema(t *testing.T) {
+	t.Parallel()
+
+	customConfig := NewZeroCustomConfig(reflect.TypeOf(&struct{}{}), reflect.TypeOf(testCLIAppConfig{}))
+	r := chi.NewRouter()
+	registerConfigHandlers(withCustomConfig(context.Background(), customConfig, nil), r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/config/schema", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "application/schema+json", w.Header().Get("Content-Type"))
+
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &schema))
+	require.Equal(t, config.JSONSchemaDraft, schema["$schema"])
+	properties := schema["properties"].(map[string]interface{})
+	require.Contains(t, properties, "envPrefix")
+	require.Contains(t, properties, "library")
+	require.Equal(t, "#/$defs/core.testCLIAppConfig", properties["app"].(map[string]interface{})["$ref"])
+	app := schema["$defs"].(map[string]interface{})["core.testCLIAppConfig"]
+	require.Equal(t, []interface{}{"name"}, app.(map[string]interface{})["required"])
+}
+
+func TestRegisterConfigHandlersWithoutConfig(t *testing.T) {
+	t.Parallel()
+
+	r := chi.NewRouter()
+	registerConfigHandlers(context.Background(), r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/config/schema", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRegisterConfigHandlersServesEffectiveConfig(t *testing.T) {
+	t.Setenv("ADMINTEST_LIBRARY_LOG_FORMAT", "json")
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "config.yaml", []byte("envPrefix: admintest\napp:\n  name: base\n  password: secret\n"), 0644))
+	c, err := parseCommandLine("app", []string{"config.yaml", "--set", "app.name=override"})
+	require.NoError(t, err)
+	c.fs = fs
+	reader, err := c.configReaderBuilder(false).Build()
+	require.NoError(t, err)
+	customConfig := NewZeroCustomConfig(reflect.TypeOf(&struct{}{}), reflect.TypeOf(testCLIAppConfig{}))
+	require.NoError(t, reader.Unmarshal(customConfig))
+
+	ctx := withCustomConfig(context.Background(), customConfig, reader)
+	ctx, err = withAdminAuth(ctx, &Hooks{AuthorizeAdminRequest: func(r *http.Request) error {
+		if r.Header.Get("Authorization") != "admin" {
+			return errors.New("not an admin")
+		}
+		return nil
+	}}, nil)
+	require.NoError(t, err)
+	r := chi.NewRouter()
+	registerConfigHandlers(ctx, r)
+
+	// the denied requests are logged
+	req := httptest.NewRequest(http.MethodGet, "/config", nil).WithContext(log.PutLogger(context.Background(), log.NewDefaultLogger()))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	req.Header.Set("Authorization", "admin")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotContains(t, w.Body.String(), "secret")
+
+	var effective struct {
+		Config  map[string]map[string]interface{} `json:"config"`
+		Sources map[string]config.Source          `json:"sources"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &effective))
+	require.Equal(t, "override", effective.Config["app"]["name"])
+	require.Equal(t, "****************", effective.Config["app"]["password"])
+	require.Equal(t, config.Source{Kind: config.SourceOverride}, effective.Sources["app.name"])
+	require.Equal(t, config.Source{Kind: config.SourceFile, File: "config.yaml", Line: 4, Column: 3}, effective.Sources["app.password"])
+	require.Equal(t, config.Source{Kind: config.SourceEnv, EnvVar: "ADMINTEST_LIBRARY_LOG_FORMAT"}, effective.Sources["library.log.format"])
+	require.Equal(t, config.Source{Kind: config.SourceDefault}, effective.Sources["genCode.upstream.contextTimeout"])
+	require.NotContains(t, effective.Sources, "library.profiling")
+}
+
+func TestRegisterConfigHandlersDeniesEffectiveConfigWithoutAuthorizer(t *testing.T) {
+	t.Parallel()
+
+	customConfig := NewZeroCustomConfig(reflect.TypeOf(&struct{}{}), reflect.TypeOf(testCLIAppConfig{}))
+	r := chi.NewRouter()
+	registerConfigHandlers(withCustomConfig(context.Background(), customConfig, nil), r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/config", nil))
+	require.Equal(t, http.StatusForbidden, w.Code)
+	require.Contains(t, w.Body.String(), "Hooks.AuthorizeAdminRequest")
+}
@@ -1,6 +1,16 @@
 package core
 
-import "github.com/anz-bank/sysl-go/status"
+import (
+	"encoding/json"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/anz-bank/sysl-go/log"
+	"github.com/anz-bank/sysl-go/status"
+)
 
 var (
 	// Build metadata. These values are intended to be overridden by values
@@ -30,11 +40,77 @@ var (
 	TagName = ""
 )
 
-var buildMetadata = &status.BuildMetadata{
-	Name:       Name,
-	Version:    Version,
-	BuildID:    BuildID,
-	CommitSha:  CommitSha,
-	BranchName: BranchName,
-	TagName:    TagName,
+var buildMetadata = newBuildMetadata()
+
+// newBuildMetadata returns the build metadata set at the build time, completed with the build
+// info embedded in the binary.
+func newBuildMetadata() *status.BuildMetadata {
+	m := &status.BuildMetadata{
+		Name:       Name,
+		Version:    Version,
+		BuildID:    BuildID,
+		CommitSha:  CommitSha,
+		BranchName: BranchName,
+		TagName:    TagName,
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		completeBuildMetadata(m, info)
+	}
+	return m
+}
+
+// completeBuildMetadata sets the version, commit and Go version of m that were not set at the
+// build time from the build info of the binary. The build is dirty if it had uncommitted changes
+// to the commit of m.
+func completeBuildMetadata(m *status.BuildMetadata, info *debug.BuildInfo) {
+	m.GoVersion = info.GoVersion
+	if m.Version == "" && info.Main.Version != "(devel)" {
+		m.Version = info.Main.Version
+	}
+	var revision string
+	var modified bool
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		}
+	}
+	if m.CommitSha == "" {
+		m.CommitSha = revision
+	}
+	m.Dirty = modified && revision != "" && m.CommitSha == revision
+}
+
+// registerBuildInfo registers the build_info gauge, which is always 1 and is labelled with the
+// build metadata of the service, e.g. to correlate deployments with changes of other metrics.
+func registerBuildInfo(registry *prometheus.Registry, m *status.BuildMetadata) {
+	registry.MustRegister(prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "build_info",
+			Help: "Build metadata of the service, by name, version, commit, branch, tag and Go version",
+			ConstLabels: prometheus.Labels{
+				"name":        m.Name,
+				"version":     m.Version,
+				"build_id":    m.BuildID,
+				"commit_sha":  m.CommitSha,
+				"branch_name": m.BranchName,
+				"tag_name":    m.TagName,
+				"go_version":  m.GoVersion,
+				"dirty":       strconv.FormatBool(m.Dirty),
+			},
+		},
+		func() float64 { return 1 },
+	))
+}
+
+// versionHandler serves the build metadata of the service.
+func versionHandler(m *status.BuildMetadata) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(m); err != nil {
+			log.Error(r.Context(), err, "failed to write build metadata")
+		}
+	}
 }
@@ -0,0 +1,77 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime/debug"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anz-bank/sysl-go/status"
+)
+
+func TestCompleteBuildMetadata(t *testing.T) {
+	t.Parallel()
+
+	info := &debug.BuildInfo{
+		GoVersion: "go1.24.0",
+		Main:      debug.Module{Version: "v1.2.3"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs.revision", Value: "abc123"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	}
+	m := &status.BuildMetadata{Name: "app"}
+	completeBuildMetadata(m, info)
+	require.Equal(t, &status.BuildMetadata{Name: "app", Version: "v1.2.3", CommitSha: "abc123", GoVersion: "go1.24.0", Dirty: true}, m)
+
+	// the values set at the build time are kept
+	m = &status.BuildMetadata{Version: "1.0", CommitSha: "def456"}
+	completeBuildMetadata(m, info)
+	require.Equal(t, &status.BuildMetadata{Version: "1.0", CommitSha: "def456", GoVersion: "go1.24.0"}, m)
+
+	m = &status.BuildMetadata{}
+	completeBuildMetadata(m, &debug.BuildInfo{GoVersion: "go1.24.0", Main: debug.Module{Version: "(devel)"}})
+	require.Equal(t, &status.BuildMetadata{GoVersion: "go1.24.0"}, m)
+}
+
+func TestRegisterBuildInfo(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewRegistry()
+	registerBuildInfo(registry, &status.BuildMetadata{Name: "app", Version: "1.0", GoVersion: "go1.24.0"})
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	require.Equal(t, "build_info", families[0].GetName())
+	metric := families[0].GetMetric()[0]
+	require.Equal(t, 1.0, metric.GetGauge().GetValue())
+	labels := map[string]string{}
+	for _, l := range metric.GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+	require.Equal(t, map[string]string{
+		"name":        "app",
+		"version":     "1.0",
+		"build_id":    "",
+		"commit_sha":  "",
+		"branch_name": "",
+		"tag_name":    "",
+		"go_version":  "go1.24.0",
+		"dirty":       "false",
+	}, labels)
+}
+
+func TestVersionHandler(t *testing.T) {
+	t.Parallel()
+
+	w := httptest.NewRecorder()
+	versionHandler(&status.BuildMetadata{Name: "app", Version: "1.0"})(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var m status.BuildMetadata
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &m))
+	require.Equal(t, status.BuildMetadata{Name: "app", Version: "1.0"}, m)
+}
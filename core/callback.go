@@ -36,7 +36,8 @@ type RestGenCallback interface {
 	WriteError(ctx context.Context, w http.ResponseWriter, httpError *common.HTTPError)
 	// DownstreamTimeoutContext add the desired timeout duration to the context for downstreams
 	// A separate service timeout (usually greater than the downstream) should also be in
-	// place to automatically respond to callers
+	// place to automatically respond to callers. Implementations should not exceed the
+	// remaining time budget of the caller, see common.DownstreamBudget.
 	DownstreamTimeoutContext(ctx context.Context) (context.Context, context.CancelFunc)
 }
 
@@ -133,6 +134,13 @@ type Hooks struct {
 	// used to serve the admin HTTP endpoints. See AddHTTPMiddleware for further details.
 	AddAdminHTTPMiddleware func(ctx context.Context, r chi.Router)
 
+	// AuthorizeAdminRequest authorizes the requests of the admin endpoints that expose sensitive
+	// data, such as the effective config at /-/config, and of the admin endpoints whose admin.auth
+	// rule is not open, in addition to the methods of the rule. A request is denied with 403
+	// Forbidden if it returns an error. If this hook is nil and admin.auth has no rule for such
+	// an endpoint then all of its requests are denied.
+	AuthorizeAdminRequest func(r *http.Request) error
+
 	// DownstreamRoundTripper can be used to install additional HTTP RoundTrippers to the downstream clients
 	DownstreamRoundTripper func(serviceName string, serviceURL string, original http.RoundTripper) http.RoundTripper
 
@@ -168,6 +176,10 @@ type Hooks struct {
 	// HealthCheck can be used to provide custom health check endpoints for your service.
 	// Currently only gRPC service is supported by implementing grpc.health.v1 when this field is set.
 	HealthCheck HealthCheck
+
+	// Commands can be used to add commands to the service binary, such as a database migration, that run
+	// with the configuration of the service instead of serving. See Command for how they are invoked.
+	Commands []Command
 }
 
 // HealthCheckStatus is an expected response for a health check function.
@@ -224,11 +236,15 @@ func ResolveGrpcServerOptions(ctx context.Context, h *Hooks, grpcPublicServerCon
 	}
 }
 
+// ResolveGRPCAuthorizationRule returns the authorization rule of a gRPC method, named by its full
+// name, /<service>/<method>.
 func ResolveGRPCAuthorizationRule(ctx context.Context, h *Hooks, endpointName string, authRuleExpression string) (authrules.Rule, error) {
+	getInventory(ctx).addAuthRule(true, endpointName, authRuleExpression)
 	return resolveAuthorizationRule(ctx, h, endpointName, authRuleExpression, authrules.MakeGRPCJWTAuthorizationRule)
 }
 
 func ResolveRESTAuthorizationRule(ctx context.Context, h *Hooks, endpointName string, authRuleExpression string) (authrules.Rule, error) {
+	getInventory(ctx).addAuthRule(false, endpointName, authRuleExpression)
 	return resolveAuthorizationRule(ctx, h, endpointName, authRuleExpression, authrules.MakeRESTJWTAuthorizationRule)
 }
 
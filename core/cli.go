@@ -0,0 +1,382 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+
+	"github.com/anz-bank/sysl-go/config"
+)
+
+// Command is a command of the application that the service binary runs instead of serving, see
+// Hooks.Commands. It is run as:
+//
+//	app <name> [--profile profile[,profile...]] [--set key=value]... config... [-- args...]
+type Command struct {
+	Name string
+	// Description is shown in the usage of the service binary.
+	Description string
+	// Run runs the command with the arguments that follow --. It is called once the configuration
+	// is loaded and validated and the service created, with a context holding the logger and the
+	// configuration of the service.
+	Run func(ctx context.Context, args []string) error
+}
+
+// Commands of the service binary.
+const (
+	serveCommand          = "serve"
+	configValidateCommand = "config validate"
+	configPrintCommand    = "config print"
+	configSchemaCommand   = "config schema"
+	configSampleCommand   = "config sample"
+	versionCommand        = "version"
+	helpCommand           = "help"
+)
+
+const commandLineUsage = `Usage:
+  %[1]s [serve] [flags] config...          serve with the given config files
+  %[1]s config validate [flags] config...  load and validate the config files
+  %[1]s config print [--effective] [flags] config...
+                                         print the config, redacted, with --effective including
+                                         the defaults, environment variables and overrides
+  %[1]s config schema                      print the JSON Schema of the config files
+  %[1]s config sample                      print a sample config file
+  %[1]s version [--json]                   print the build metadata
+  %[1]s <command> [flags] config... [-- args...]
+                                         run a command of the application
+
+Flags:
+  --profile profile[,profile...]  merge the overlays of the profiles, e.g. config.prod.yaml
+  --set key=value                 override a config value, e.g. --set genCode.upstream.http.common.port=9000
+
+Config files are merged in order, each one taking precedence over the ones before it.
+`
+
+// commandLine is the parsed command line of the service binary.
+type commandLine struct {
+	program     string
+	command     string
+	configPaths []string
+	profile     string
+	overrides   []string
+	effective   bool
+	json        bool
+	// args are the arguments that follow -- for a command of the application.
+	args []string
+	// config is the config loaded for the command, nil until it is loaded.
+	config interface{}
+	// reader is the reader of the config, nil until it is loaded.
+	reader config.ConfigReader
+	// decodeErr are the errors of the config decoded for the serve command or a command of the
+	// application, returned along with the validation errors of the default config.
+	decodeErr error
+
+	fs  afero.Fs
+	out io.Writer
+}
+
+// overrides collects the values of repeated --set flags.
+type overrides []string
+
+func (o *overrides) String() string { return strings.Join(*o, ",") }
+
+func (o *overrides) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	*o = append(*o, value)
+	return nil
+}
+
+// commandLineFromContext returns the command line of the service binary, which is the one of the
+// context if there is one, see WithCommandLine, or a serve command of the configuration data of
+// the context if there is some, see WithConfigFile.
+func commandLineFromContext(ctx context.Context) (*commandLine, error) {
+	if v := ctx.Value(serveYAMLConfigFileKey); v != nil {
+		fs := afero.NewMemMapFs()
+		if err := afero.WriteFile(fs, "config.yaml", v.([]byte), 0777); err != nil {
+			return nil, err
+		}
+		return &commandLine{
+			program:     os.Args[0],
+			command:     serveCommand,
+			configPaths: []string{"config.yaml"},
+			fs:          fs,
+			out:         os.Stdout,
+		}, nil
+	}
+	if args, ok := ctx.Value(serveCommandLineKey).([]string); ok {
+		return parseCommandLine(os.Args[0], args)
+	}
+	return parseCommandLine(os.Args[0], os.Args[1:])
+}
+
+// parseCommandLine parses the arguments of the service binary. For compatibility, the command
+// is serve when the first argument is a flag or a file name, that is a path, an existing file or
+// a name with an extension. A bare word is only a command when there is no file of that name.
+func parseCommandLine(program string, args []string) (*commandLine, error) {
+	c := &commandLine{program: program, command: serveCommand, fs: afero.NewOsFs(), out: os.Stdout}
+	if len(args) == 0 {
+		return nil, c.usageError(errors.New("missing config file"))
+	}
+
+	switch {
+	case args[0] == "-h" || args[0] == "--help" || args[0] == helpCommand:
+		c.command = helpCommand
+		return c, nil
+	case args[0] == "-v" || args[0] == "--version":
+		c.command = versionCommand
+		return c, nil
+	case strings.HasPrefix(args[0], "-") || c.isFile(args[0]):
+	case args[0] == "config":
+		if len(args) < 2 {
+			return nil, c.usageError(errors.New("missing config command"))
+		}
+		c.command = "config " + args[1]
+		switch c.command {
+		case configValidateCommand, configPrintCommand, configSchemaCommand, configSampleCommand:
+		default:
+			return nil, c.usageError(fmt.Errorf("unknown command %q", c.command))
+		}
+		args = args[2:]
+	case filepath.Ext(args[0]) != "":
+	default:
+		c.command = args[0]
+		args = args[1:]
+	}
+
+	flags := flag.NewFlagSet(c.command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	switch c.command {
+	case configSchemaCommand, configSampleCommand:
+	case versionCommand:
+		flags.BoolVar(&c.json, "json", false, "")
+	default:
+		flags.StringVar(&c.profile, "profile", "", "")
+		flags.Var((*overrides)(&c.overrides), "set", "")
+		if c.command == configPrintCommand {
+			flags.BoolVar(&c.effective, "effective", false, "")
+		}
+	}
+
+	if i := slices.Index(args, "--"); i >= 0 {
+		args, c.args = args[:i], args[i+1:]
+	}
+
+	// allow flags after the config files
+	for {
+		if err := flags.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				c.command = helpCommand
+				return c, nil
+			}
+			return nil, c.usageError(err)
+		}
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+		c.configPaths = append(c.configPaths, args[0])
+		args = args[1:]
+	}
+
+	switch c.command {
+	case configSchemaCommand, configSampleCommand, versionCommand:
+		if len(c.configPaths) > 0 {
+			return nil, c.usageError(fmt.Errorf("unexpected arguments %s", strings.Join(c.configPaths, " ")))
+		}
+	default:
+		if len(c.configPaths) == 0 {
+			return nil, c.usageError(errors.New("missing config file"))
+		}
+	}
+	return c, nil
+}
+
+// isFile returns whether the argument names a file rather than a command, because it is a path
+// or a file of that name exists.
+func (c *commandLine) isFile(arg string) bool {
+	if strings.ContainsRune(arg, '/') || strings.ContainsRune(arg, filepath.Separator) {
+		return true
+	}
+	_, err := c.fs.Stat(arg)
+	return err == nil
+}
+
+func (c *commandLine) usageError(err error) error {
+	return fmt.Errorf("%w\n\n%s", err, fmt.Sprintf(commandLineUsage, c.program))
+}
+
+// isApplicationCommand returns whether the command is one of Hooks.Commands.
+func (c *commandLine) isApplicationCommand() bool {
+	switch c.command {
+	case serveCommand, configValidateCommand, configPrintCommand, configSchemaCommand,
+		configSampleCommand, versionCommand, helpCommand:
+		return false
+	}
+	return true
+}
+
+// runWithoutConfig runs the commands that do not read the config files and returns whether the
+// command was run.
+func (c *commandLine) runWithoutConfig(customConfig interface{}) (bool, error) {
+	switch c.command {
+	case helpCommand:
+		fmt.Fprintf(c.out, commandLineUsage+"\n", c.program)
+		describeCustomConfig(c.out, customConfig)
+		fmt.Fprint(c.out, "\n\n")
+		return true, ErrDisplayHelp(2)
+	case versionCommand:
+		if c.json {
+			b, err := json.MarshalIndent(buildMetadata, "", "  ")
+			if err != nil {
+				return true, err
+			}
+			fmt.Fprintf(c.out, "%s\n", b)
+		} else {
+			fmt.Fprintf(c.out, "%s\n", buildMetadata.String())
+		}
+		return true, ErrDisplayHelp(0)
+	case configSchemaCommand:
+		b, err := json.MarshalIndent(customConfigSchema(customConfig), "", "  ")
+		if err != nil {
+			return true, err
+		}
+		fmt.Fprintf(c.out, "%s\n", b)
+		return true, ErrDisplayHelp(0)
+	case configSampleCommand:
+		fmt.Fprint(c.out, sampleCustomConfig(customConfig))
+		return true, ErrDisplayHelp(0)
+	}
+	return false, nil
+}
+
+// configReaderBuilder returns a builder of the config files and profiles of the command line,
+// with the defaults, environment variables and overrides unless filesOnly is set.
+func (c *commandLine) configReaderBuilder(filesOnly bool) config.ConfigReaderBuilder {
+	b := config.NewConfigReaderBuilder().WithFs(c.fs)
+	if !filesOnly {
+		b = b.WithDefaults(config.SetDefaults)
+	}
+	b = b.WithConfigFiles(c.configPaths...)
+
+	envPrefixConfigKey := "envPrefix"
+
+	// Enable strict mode to raise an error if there are config keys read from
+	// input that have no corresponding place in the customConfig structure
+	// that we're going to decode into -- with the exception of the special
+	// optional envPrefix and profile keys -- that don't end up getting decoded
+	// into the structure but, if present, we do read them below to customise
+	// how environment variables and profile overlays are loaded.
+	b = b.WithStrictMode(true, envPrefixConfigKey, config.ProfileKey)
+
+	// Use the environment variable prefix from the config file if provided, the errors of the
+	// config files are left to Build
+	if reader, err := b.Build(); err == nil && !filesOnly {
+		env, err := reader.GetString(envPrefixConfigKey)
+		// Disable the feature if none is provided
+		if len(env) > 0 && err == nil {
+			b = b.AttachEnvPrefix(env)
+		}
+	}
+
+	// Merge the profile overlays last, the profile key may come from an environment variable
+	b = b.WithProfiles(c.profile)
+
+	if !filesOnly {
+		for _, o := range c.overrides {
+			key, value, _ := strings.Cut(o, "=")
+			b = b.WithOverride(key, value)
+		}
+	}
+	return b
+}
+
+// runWithConfig runs the commands that only read the config files and returns whether the
+// command was run. decodeErr are the errors of decoding customConfig.
+func (c *commandLine) runWithConfig(customConfig interface{}, decodeErr error) (bool, error) {
+	switch c.command {
+	case configValidateCommand:
+		if err := config.JoinErrors(decodeErr, c.reader.Validate(customConfig)); err != nil {
+			return true, err
+		}
+		fmt.Fprintf(c.out, "%s: configuration is valid\n", strings.Join(c.configPaths, ", "))
+		return true, ErrDisplayHelp(0)
+	case configPrintCommand:
+		if decodeErr != nil {
+			return true, decodeErr
+		}
+		if !c.effective {
+			customConfig = reflect.New(reflect.TypeOf(customConfig).Elem()).Interface()
+			reader, err := c.configReaderBuilder(true).Build()
+			if err != nil {
+				return true, err
+			}
+			if err := reader.Unmarshal(customConfig); err != nil {
+				return true, err
+			}
+		}
+		b, err := yaml.Marshal(config.Redact(customConfig))
+		if err != nil {
+			return true, err
+		}
+		_, err = c.out.Write(b)
+		if err != nil {
+			return true, err
+		}
+		return true, ErrDisplayHelp(0)
+	}
+	return false, nil
+}
+
+// configErrors returns the decode errors of the config along with the validation errors of the
+// default config decoded from it, so that they are all reported at once, or nil if the config
+// was decoded.
+func (c *commandLine) configErrors(defaultConfig *config.DefaultConfig) error {
+	if c.decodeErr == nil {
+		return nil
+	}
+	return config.JoinErrors(c.decodeErr, c.reader.Validate(defaultConfig))
+}
+
+// runApplicationCommand runs the command of the hooks named by the command line.
+func (c *commandLine) runApplicationCommand(ctx context.Context, hooks *Hooks) error {
+	if hooks != nil {
+		for _, command := range hooks.Commands {
+			if command.Name == c.command {
+				if err := command.Run(ctx, c.args); err != nil {
+					return err
+				}
+				return ErrDisplayHelp(0)
+			}
+		}
+	}
+	return c.usageError(fmt.Errorf("unknown command %q", c.command))
+}
+
+var ansiEscape = regexp.MustCompile("\033\\[[0-9;]*m")
+
+// sampleCustomConfig returns a sample YAML config file for the type of customConfig, in which
+// every value is the zero value of its type.
+func sampleCustomConfig(customConfig interface{}) string {
+	commonTypes := map[reflect.Type]string{
+		reflect.TypeOf(config.SensitiveString{}): yamlEgComment(`"*****"`, "sensitive string"),
+	}
+	var b strings.Builder
+	b.WriteString("# Sample configuration file, every value is the zero value of its type.")
+	describeYAMLForType(&b, reflect.TypeOf(customConfig), commonTypes, 0)
+	b.WriteString("\n")
+	return ansiEscape.ReplaceAllString(b.String(), "")
+}
@@ -0,0 +1,223 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anz-bank/sysl-go/config"
+)
+
+type testCLIAppConfig struct {
+	Name     string                  `yaml:"name" mapstructure:"name" validate:"required"`
+	Password *config.SensitiveString `yaml:"password" mapstructure:"password"`
+}
+
+func TestParseCommandLine(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []struct {
+		args     []string
+		expected commandLine
+	}{
+		{[]string{"config.yaml"}, commandLine{command: serveCommand, configPaths: []string{"config.yaml"}}},
+		{[]string{"/etc/app/config"}, commandLine{command: serveCommand, configPaths: []string{"/etc/app/config"}}},
+		{[]string{"./config", "--profile", "prod"}, commandLine{command: serveCommand, configPaths: []string{"./config"}, profile: "prod"}},
+		{
+			[]string{"serve", "base.yaml", "--profile", "prod,local", "local.yaml", "--set", "a.b=1", "--set=c=2"},
+			commandLine{command: serveCommand, configPaths: []string{"base.yaml", "local.yaml"}, profile: "prod,local", overrides: []string{"a.b=1", "c=2"}},
+		},
+		{[]string{"--profile=prod", "base.yaml"}, commandLine{command: serveCommand, configPaths: []string{"base.yaml"}, profile: "prod"}},
+		{[]string{"config", "validate", "base.yaml"}, commandLine{command: configValidateCommand, configPaths: []string{"base.yaml"}}},
+		{[]string{"config", "print", "base.yaml", "--effective"}, commandLine{command: configPrintCommand, configPaths: []string{"base.yaml"}, effective: true}},
+		{[]string{"config", "schema"}, commandLine{command: configSchemaCommand}},
+		{[]string{"config", "sample"}, commandLine{command: configSampleCommand}},
+		{[]string{"version", "--json"}, commandLine{command: versionCommand, json: true}},
+		{[]string{"-v"}, commandLine{command: versionCommand}},
+		{[]string{"--help"}, commandLine{command: helpCommand}},
+		{[]string{"serve", "-h"}, commandLine{command: helpCommand}},
+		{[]string{"migrate", "base.yaml", "--", "up", "--steps", "3"}, commandLine{command: "migrate", configPaths: []string{"base.yaml"}, args: []string{"up", "--steps", "3"}}},
+	} {
+		c, err := parseCommandLine("app", s.args)
+		require.NoError(t, err, s.args)
+		c.program, c.fs, c.out = "", nil, nil
+		assert.Equal(t, s.expected, *c, s.args)
+	}
+
+	for _, s := range []struct {
+		args     []string
+		expected string
+	}{
+		{nil, "missing config file"},
+		{[]string{"serve"}, "missing config file"},
+		{[]string{"config"}, "missing config command"},
+		{[]string{"config", "edit"}, `unknown command "config edit"`},
+		{[]string{"config", "schema", "base.yaml"}, "unexpected arguments base.yaml"},
+		{[]string{"base.yaml", "--profile"}, "flag needs an argument: -profile"},
+		{[]string{"base.yaml", "--set", "a.b"}, `invalid value "a.b" for flag -set: expected key=value, got "a.b"`},
+		{[]string{"base.yaml", "--effective"}, "flag provided but not defined: -effective"},
+	} {
+		_, err := parseCommandLine("app", s.args)
+		require.Error(t, err, s.args)
+		assert.Contains(t, err.Error(), s.expected+"\n\nUsage:", s.args)
+	}
+}
+
+func TestParseCommandLineOfExistingFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.WriteFile("config", []byte("app:\n  name: base"), 0644))
+
+	// a file in the working directory is served rather than taken as a command
+	c, err := parseCommandLine("app", []string{"config"})
+	require.NoError(t, err)
+	require.Equal(t, serveCommand, c.command)
+	require.Equal(t, []string{"config"}, c.configPaths)
+
+	_, err = parseCommandLine("app", []string{"migrate"})
+	require.ErrorContains(t, err, "missing config file")
+}
+
+func newTestCLI(t *testing.T, args ...string) (*commandLine, *bytes.Buffer) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "base.yaml", []byte("app:\n  name: base\n  password: secret"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "invalid.yaml", []byte("app:\n  name: \"\""), 0644))
+	require.NoError(t, afero.WriteFile(fs, "unknown.yaml", []byte("app:\n  nickname: base"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "broken.yaml", []byte("library:\n  log:\n    caller: maybe\napp:\n  name: \"\"\n  nickname: base"), 0644))
+
+	c, err := parseCommandLine("app", args)
+	require.NoError(t, err)
+	out := &bytes.Buffer{}
+	c.fs, c.out = fs, out
+	return c, out
+}
+
+// runTestCLI runs the command line like loadCustomConfig.
+func runTestCLI(c *commandLine) error {
+	customConfig := NewZeroCustomConfig(reflect.TypeOf(&struct{}{}), reflect.TypeOf(testCLIAppConfig{}))
+	if done, err := c.runWithoutConfig(customConfig); done {
+		return err
+	}
+	var err error
+	if c.reader, err = c.configReaderBuilder(false).Build(); err != nil {
+		return err
+	}
+	_, err = c.runWithConfig(customConfig, c.reader.Unmarshal(customConfig))
+	return err
+}
+
+func TestCommandLineConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	c, out := newTestCLI(t, "config", "validate", "base.yaml")
+	require.Equal(t, ErrDisplayHelp(0), runTestCLI(c))
+	require.Equal(t, "base.yaml: configuration is valid\n", out.String())
+
+	c, _ = newTestCLI(t, "config", "validate", "base.yaml", "invalid.yaml")
+	require.EqualError(t, runTestCLI(c), "invalid configuration: invalid.yaml:2:3: app.name: failed on the 'required' validation")
+
+	c, _ = newTestCLI(t, "config", "validate", "base.yaml", "unknown.yaml")
+	require.EqualError(t, runTestCLI(c), "invalid configuration: unknown.yaml:2:3: app.nickname: unexpected config key")
+
+	c, _ = newTestCLI(t, "config", "validate", "broken.yaml")
+	require.EqualError(t, runTestCLI(c), "invalid configuration, 3 errors:\n"+
+		"\tbroken.yaml:3:5: library.log.caller: cannot parse 'library.log.caller' as bool: strconv.ParseBool: parsing \"maybe\": invalid syntax\n"+
+		"\tbroken.yaml:6:3: app.nickname: unexpected config key\n"+
+		"\tbroken.yaml:5:3: app.name: failed on the 'required' validation")
+
+	c, _ = newTestCLI(t, "config", "validate", "missing.yaml")
+	require.ErrorContains(t, runTestCLI(c), "failed to read config file missing.yaml")
+}
+
+func TestCommandLineConfigPrint(t *testing.T) {
+	t.Parallel()
+
+	c, out := newTestCLI(t, "config", "print", "base.yaml", "--set", "app.name=override")
+	require.Equal(t, ErrDisplayHelp(0), runTestCLI(c))
+	require.Contains(t, out.String(), "name: base\n")
+	require.Contains(t, out.String(), "password: '****************'\n")
+	require.NotContains(t, out.String(), "secret")
+	require.Contains(t, out.String(), "format: \"\"\n")
+
+	c, out = newTestCLI(t, "config", "print", "--effective", "base.yaml", "--set", "app.name=override")
+	require.Equal(t, ErrDisplayHelp(0), runTestCLI(c))
+	require.Contains(t, out.String(), "name: override\n")
+	require.Contains(t, out.String(), "format: text\n")
+	require.NotContains(t, out.String(), "secret")
+}
+
+func TestCommandLineConfigSample(t *testing.T) {
+	t.Parallel()
+
+	c, out := newTestCLI(t, "config", "sample")
+	require.Equal(t, ErrDisplayHelp(0), runTestCLI(c))
+	require.NotContains(t, out.String(), "\033")
+	require.Contains(t, out.String(), "\napp:\n    name: \"\"\n    password: \"*****\" # sensitive string\n")
+}
+
+func TestCommandLineConfigSchema(t *testing.T) {
+	t.Parallel()
+
+	c, out := newTestCLI(t, "config", "schema")
+	require.Equal(t, ErrDisplayHelp(0), runTestCLI(c))
+	var schema map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &schema))
+	require.Equal(t, config.JSONSchemaDraft, schema["$schema"])
+	require.Contains(t, schema["properties"], "app")
+}
+
+func TestCommandLineVersion(t *testing.T) {
+	t.Parallel()
+
+	c, out := newTestCLI(t, "version", "--json")
+	require.Equal(t, ErrDisplayHelp(0), runTestCLI(c))
+	require.JSONEq(t, `{"name": "", "version": "", "build_id": "", "commit_sha": "", "branch_name": "", "tag_name": "", "go_version": "`+runtime.Version()+`"}`, out.String())
+}
+
+func TestNewServerRunsApplicationCommand(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("app:\n  field3: 3"), 0600))
+
+	var args []string
+	var field3 int
+	hooks := &Hooks{Commands: []Command{{
+		Name: "migrate",
+		Run: func(ctx context.Context, a []string) error {
+			args = a
+			if a[0] == "fail" {
+				return errors.New("migration failed")
+			}
+			return nil
+		},
+	}}}
+	newServer := func(args ...string) error {
+		_, err := NewServer(WithCommandLine(context.Background(), args...), &struct{}{},
+			func(ctx context.Context, config TestAppConfig) (*TestServiceInterface, *Hooks, error) {
+				field3 = config.Field3
+				return &TestServiceInterface{}, hooks, nil
+			},
+			&TestServiceInterface{},
+			func(ctx context.Context, serviceIntf interface{}, _ *Hooks) (Manager, *GrpcServerManager, error) {
+				panic("the server is not created for a command")
+			},
+		)
+		return err
+	}
+
+	require.Equal(t, ErrDisplayHelp(0), newServer("migrate", path, "--", "up", "3"))
+	require.Equal(t, []string{"up", "3"}, args)
+	require.Equal(t, 3, field3)
+	require.EqualError(t, newServer("migrate", path, "--", "fail"), "migration failed")
+	require.ErrorContains(t, newServer("seed", path), `unknown command "seed"`)
+}
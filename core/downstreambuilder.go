@@ -3,9 +3,11 @@ package core
 import (
 	"context"
 	"net/http"
+	"strings"
 
 	"go.temporal.io/sdk/client"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/anz-bank/sysl-go/common"
 	"github.com/anz-bank/sysl-go/config"
@@ -25,7 +27,21 @@ func BuildDownstreamHTTPClient(ctx context.Context, serviceName string, hooks *H
 		return nil, "", err
 	}
 
+	if cfg != nil && cfg.LoadBalancing != nil {
+		client.Transport, err = common.NewBalancingRoundTripper(ctx, cfg.LoadBalancing, client.Transport)
+		if err != nil {
+			return nil, "", err
+		}
+		if serviceURL == "" {
+			// The balancer replaces the host of each request, so any endpoint will do.
+			serviceURL = cfg.LoadBalancing.Endpoints[0].Address
+		}
+	}
+
 	client.Transport = common.NewLoggingRoundTripper(serviceName, client.Transport)
+	if d := getInventory(ctx).addDownstream(newHTTPDownstream(serviceName, serviceURL, cfg)); d != nil {
+		client.Transport = &downstreamRoundTripper{d, client.Transport}
+	}
 	if hooks != nil && hooks.DownstreamRoundTripper != nil {
 		client.Transport = hooks.DownstreamRoundTripper(serviceName, serviceURL, client.Transport)
 	}
@@ -33,7 +49,8 @@ func BuildDownstreamHTTPClient(ctx context.Context, serviceName string, hooks *H
 	return
 }
 
-// BuildDownstreamGRPCClient creates a grpc client connection to the target indicated by cfg.ServiceAddress.
+// BuildDownstreamGRPCClient creates a grpc client connection to the target indicated by cfg.ServiceAddress,
+// or balanced across cfg.LoadBalancing.Endpoints when load balancing is configured.
 // The dial options can be customised by cfg or by hooks, see ResolveGrpcDialOptions for details. The
 // serviceName is the name of the target service. This function is intended to be called from generated code.
 func BuildDownstreamGRPCClient(ctx context.Context, serviceName string, hooks *Hooks, cfg *config.CommonGRPCDownstreamData) (*grpc.ClientConn, error) {
@@ -41,7 +58,78 @@ func BuildDownstreamGRPCClient(ctx context.Context, serviceName string, hooks *H
 	if err != nil {
 		return nil, err
 	}
-	return grpc.Dial(cfg.ServiceAddress, opts...)
+	if cfg != nil && cfg.Propagation != nil {
+		policy, err := common.NewHeaderPolicy(cfg.Propagation)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.WithChainUnaryInterceptor(HeaderPropagationInterceptor(policy)))
+	}
+	target := cfg.ServiceAddress
+	if cfg != nil && cfg.LoadBalancing != nil {
+		var lbOpts []grpc.DialOption
+		target, lbOpts, err = grpcLoadBalancingDialOptions(ctx, serviceName, cfg.LoadBalancing)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, lbOpts...)
+	}
+	d := &downstream{Name: serviceName, Kind: "grpc", Target: target, TLS: tlsMode(cfg.TLS, false)}
+	if d = getInventory(ctx).addDownstream(d); d != nil {
+		opts = append(opts, grpc.WithChainUnaryInterceptor(downstreamInterceptor(d)))
+	}
+	return grpc.Dial(target, opts...)
+}
+
+// httpOnlyHeaders are the headers of a request that describe its HTTP connection or message
+// rather than the request itself, which grpc-go sets or rejects as metadata.
+var httpOnlyHeaders = map[string]bool{
+	"Connection":          true,
+	"Keep-Alive":          true,
+	"Proxy-Connection":    true,
+	"Proxy-Authenticate":  true,
+	"Proxy-Authorization": true,
+	"Te":                  true,
+	"Trailer":             true,
+	"Transfer-Encoding":   true,
+	"Upgrade":             true,
+	"Host":                true,
+	"Content-Length":      true,
+	"Content-Type":        true,
+}
+
+// HeaderPropagationInterceptor returns a client interceptor that sends the headers of the
+// incoming REST request (or the metadata of the incoming gRPC call) as outgoing metadata,
+// filtered by the given policy. The hop-by-hop headers, including those named by the
+// Connection header, and the other HTTP only headers are never sent.
+func HeaderPropagationInterceptor(policy *common.HeaderPolicy) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		incoming := common.RequestHeaderFromContext(ctx)
+		if incoming == nil {
+			if md, ok := metadata.FromIncomingContext(ctx); ok {
+				incoming = http.Header(md)
+			}
+		}
+		hopByHop := map[string]bool{}
+		for _, value := range incoming.Values("Connection") {
+			for _, name := range strings.Split(value, ",") {
+				hopByHop[http.CanonicalHeaderKey(strings.TrimSpace(name))] = true
+			}
+		}
+		for name, values := range policy.Apply(ctx, incoming) {
+			if canonical := http.CanonicalHeaderKey(name); httpOnlyHeaders[canonical] || hopByHop[canonical] {
+				continue
+			}
+			name = strings.ToLower(name)
+			if strings.HasPrefix(name, ":") {
+				continue // pseudo-headers are set by the transport
+			}
+			for _, v := range values {
+				ctx = metadata.AppendToOutgoingContext(ctx, name, v)
+			}
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
 }
 
 // BuildDownstreamTemporalClient creates a temporal client connection to the target indicated by cfg.HostPort.
@@ -52,14 +140,17 @@ func BuildDownstreamTemporalClient(
 	hooks *Hooks,
 	cfg *config.CommonTemporalDownstreamData,
 ) (client.Client, error) {
-	clientOptions := client.Options{
-		HostPort:  cfg.HostPort,
-		Namespace: cfg.Namespace,
-		Identity:  cfg.Identity,
-
-		// TODO: add sysl-go logging solution
-		// Logger:             nil,
+	clientOptions, err := temporalClientOptions(ctx, cfg.HostPort, cfg.Namespace, cfg.Identity, &cfg.TemporalConnectionConfig)
+	if err != nil {
+		return nil, err
 	}
+	// the calls of temporal clients are not recorded
+	getInventory(ctx).addDownstream(&downstream{
+		Name:   serviceName,
+		Kind:   "temporal",
+		Target: cfg.HostPort,
+		TLS:    tlsMode(cfg.TLS, cfg.APIKey != nil),
+	})
 
 	if hooks.ExperimentalValidateTemporalClientOptions != nil {
 		if err := hooks.ExperimentalValidateTemporalClientOptions(ctx, &clientOptions); err != nil {
@@ -1,10 +1,19 @@
 package core
 
 import (
+	"context"
+	"net"
 	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/anz-bank/sysl-go/common"
+	"github.com/anz-bank/sysl-go/config"
 )
 
 type roundTripper struct {
@@ -28,3 +37,65 @@ func TestDownstreamRoundTripper(t *testing.T) {
 	require.NotNil(t, client)
 	require.IsType(t, roundTripper{}, client.Transport)
 }
+
+func TestHeaderPropagationInterceptor(t *testing.T) {
+	policy, err := common.NewHeaderPolicy(&config.HeaderPropagationConfig{
+		Deny:   []string{"Authorization"},
+		Rename: map[string]string{"X-Channel": "X-Source-Channel"},
+	})
+	require.NoError(t, err)
+
+	ctx := common.RequestHeaderToContext(context.Background(), http.Header{
+		"Authorization": {"Bearer abc"},
+		"X-Channel":     {"web"},
+	})
+
+	var outgoing metadata.MD
+	invoker := func(ctx context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		outgoing, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	err = HeaderPropagationInterceptor(policy)(ctx, "/test.TestService/Test", nil, nil, nil, invoker)
+	require.NoError(t, err)
+	require.Equal(t, metadata.MD{"x-source-channel": {"web"}}, outgoing)
+}
+
+func TestHeaderPropagationInterceptorDropsHTTPOnlyHeaders(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	var incoming metadata.MD
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		incoming, _ = metadata.FromIncomingContext(ctx)
+		return handler(ctx, req)
+	}))
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	conn, err := BuildDownstreamGRPCClient(ctx, "health", &Hooks{}, &config.CommonGRPCDownstreamData{
+		ServiceAddress: lis.Addr().String(),
+		Propagation:    &config.HeaderPropagationConfig{Deny: []string{"Authorization"}},
+	})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	reqCtx := common.RequestHeaderToContext(ctx, http.Header{
+		"Connection":        {"keep-alive, X-Hop"},
+		"Keep-Alive":        {"timeout=5"},
+		"X-Hop":             {"1"},
+		"Transfer-Encoding": {"chunked"},
+		"Te":                {"trailers"},
+		"Upgrade":           {"h2c"},
+		"Host":              {"example.com"},
+		"Content-Length":    {"0"},
+		"Content-Type":      {"application/json"},
+		"X-Channel":         {"web"},
+	})
+	_, err = grpc_health_v1.NewHealthClient(conn).Check(reqCtx, &grpc_health_v1.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"web"}, incoming.Get("x-channel"))
+	for _, name := range []string{"connection", "keep-alive", "x-hop", "transfer-encoding", "upgrade", "host", "content-length"} {
+		require.Empty(t, incoming.Get(name), name)
+	}
+	require.Equal(t, []string{"application/grpc"}, incoming.Get("content-type"))
+}
@@ -59,7 +59,7 @@ func DefaultGrpcServerOptions(ctx context.Context, grpcPublicServerConfig *confi
 
 	logger := log.GetLogger(ctx)
 	// Inject the logger into the ctx so we can log when we're serving rpc calls.
-	opts = append(opts, grpc.ChainUnaryInterceptor(makeLoggerInterceptor(logger)))
+	opts = append(opts, grpc.ChainUnaryInterceptor(makeLoggerInterceptor(logger, log.GetLevelController(ctx))))
 
 	opts = append(opts, grpc.ChainUnaryInterceptor(TraceidLogInterceptor))
 	return opts, nil
@@ -83,13 +83,14 @@ func extractGrpcServerOptionsFromGrpcManager(ctx context.Context, hl GrpcManager
 		return nil, err
 	}
 	opts = append(opts, grpc.ChainUnaryInterceptor(hl.Interceptors()...))
-	opts = append(opts, grpc.ChainUnaryInterceptor(makeLoggerInterceptor(log.GetLogger(ctx))))
+	opts = append(opts, grpc.ChainUnaryInterceptor(makeLoggerInterceptor(log.GetLogger(ctx), log.GetLevelController(ctx))))
 	opts = append(opts, grpc.ChainUnaryInterceptor(TraceidLogInterceptor)) // seems wrong to have this last in chain, but that was old behaviour.
 	return opts, nil
 }
 
 func configurePublicGrpcServerListener(ctx context.Context, m GrpcServerManager, hooks *Hooks) StoppableServer {
 	server := grpc.NewServer(m.GrpcServerOptions...)
+	getInventory(ctx).setGRPCServer(server)
 	cfg := config.GetDefaultConfig(ctx)
 	if cfg != nil && cfg.GenCode.Upstream.GRPC.EnableReflection {
 		reflection.Register(server)
@@ -182,9 +183,15 @@ func prepareGrpcServerListener(ctx context.Context, server *grpc.Server, commonC
 	return grpcServer{ctx: ctx, cfg: commonConfig, server: server, name: name}
 }
 
-func makeLoggerInterceptor(logger log.Logger) grpc.UnaryServerInterceptor {
+// makeLoggerInterceptor puts the logger into the context of the calls, at the level of the method
+// if there is a level controller, see log.LevelController.
+func makeLoggerInterceptor(logger log.Logger, levels *log.LevelController) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		ctx = log.PutLogger(ctx, logger)
+		if levels != nil {
+			ctx = log.WithLevelController(ctx, levels)
+			ctx = log.WithRouteLevel(ctx, info.FullMethod)
+		}
 		return handler(ctx, req)
 	}
 }
@@ -0,0 +1,149 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/resolver"
+
+	"github.com/anz-bank/sysl-go/config"
+	anzlog "github.com/anz-bank/sysl-go/log"
+
+	// Register the least-request balancer and client-side health checking.
+	_ "google.golang.org/grpc/balancer/leastrequest"
+	_ "google.golang.org/grpc/health"
+)
+
+const endpointsScheme = "sysl-endpoints"
+
+// grpcLoadBalancingDialOptions returns the dial target and options that balance the calls of a
+// grpc client across the endpoints given in cfg.
+func grpcLoadBalancingDialOptions(ctx context.Context, serviceName string, cfg *config.LoadBalancingConfig) (string, []grpc.DialOption, error) {
+	if len(cfg.Endpoints) == 0 {
+		return "", nil, fmt.Errorf("load balancing requires at least one endpoint")
+	}
+	serviceConfig, err := grpcServiceConfig(cfg)
+	if err != nil {
+		return "", nil, err
+	}
+	builder := &endpointsResolverBuilder{ctx: ctx, cfg: cfg}
+	target := fmt.Sprintf("%s:///%s", endpointsScheme, serviceName)
+	return target, []grpc.DialOption{
+		grpc.WithResolvers(builder),
+		grpc.WithDefaultServiceConfig(serviceConfig),
+	}, nil
+}
+
+func grpcServiceConfig(cfg *config.LoadBalancingConfig) (string, error) {
+	var policy map[string]interface{}
+	switch cfg.Policy {
+	case "", config.RoundRobin:
+		policy = map[string]interface{}{"round_robin": struct{}{}}
+	case config.LeastOutstanding:
+		policy = map[string]interface{}{"least_request_experimental": map[string]int{"choiceCount": 2}}
+	default:
+		return "", fmt.Errorf("load balancing policy %s is not supported for grpc downstreams", cfg.Policy)
+	}
+	sc := map[string]interface{}{
+		"loadBalancingConfig": []interface{}{policy},
+	}
+	if cfg.HealthCheck != nil {
+		sc["healthCheckConfig"] = map[string]string{"serviceName": cfg.HealthCheck.Path}
+	}
+	b, err := json.Marshal(sc)
+	return string(b), err
+}
+
+type endpointsResolverBuilder struct {
+	ctx context.Context
+	cfg *config.LoadBalancingConfig
+}
+
+func (b *endpointsResolverBuilder) Scheme() string {
+	return endpointsScheme
+}
+
+func (b *endpointsResolverBuilder) Build(_ resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	ctx, cancel := context.WithCancel(b.ctx)
+	r := &endpointsResolver{ctx: ctx, cancel: cancel, cfg: b.cfg, cc: cc, now: make(chan struct{}, 1)}
+	r.resolve()
+	if b.cfg.ResolveInterval > 0 {
+		r.wg.Add(1)
+		go r.watch()
+	}
+	return r, nil
+}
+
+// endpointsResolver resolves the configured endpoints to addresses. Without a resolve interval
+// the endpoints are passed to grpc as they are and the host names are resolved on connect.
+type endpointsResolver struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	cfg    *config.LoadBalancingConfig
+	cc     resolver.ClientConn
+	now    chan struct{}
+	wg     sync.WaitGroup
+}
+
+// watch resolves the endpoints again at the configured interval or when grpc asks for it.
+func (r *endpointsResolver) watch() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.cfg.ResolveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.now:
+		}
+		r.resolve()
+	}
+}
+
+func (r *endpointsResolver) resolve() {
+	var addrs []resolver.Address
+	for _, e := range r.cfg.Endpoints {
+		host, port, err := net.SplitHostPort(e.Address)
+		if err != nil {
+			r.cc.ReportError(fmt.Errorf("invalid endpoint %s: %w", e.Address, err))
+			return
+		}
+		if r.cfg.ResolveInterval <= 0 || net.ParseIP(host) != nil {
+			addrs = append(addrs, resolver.Address{Addr: e.Address, ServerName: host})
+			continue
+		}
+		ips, err := net.DefaultResolver.LookupHost(r.ctx, host)
+		if err != nil {
+			anzlog.Infof(r.ctx, "failed to resolve endpoint %s: %v", e.Address, err)
+			continue
+		}
+		for _, ip := range ips {
+			addrs = append(addrs, resolver.Address{Addr: net.JoinHostPort(ip, port), ServerName: host})
+		}
+	}
+	if len(addrs) == 0 {
+		r.cc.ReportError(fmt.Errorf("no endpoint could be resolved"))
+		return
+	}
+	if err := r.cc.UpdateState(resolver.State{Addresses: addrs}); err != nil {
+		anzlog.Debugf(r.ctx, "failed to update resolver state: %v", err)
+	}
+}
+
+func (r *endpointsResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.now <- struct{}{}:
+	default:
+	}
+}
+
+func (r *endpointsResolver) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/anz-bank/sysl-go/config"
+)
+
+func startCountingGrpcServer(t *testing.T, hits *atomic.Int32) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		hits.Add(1)
+		return handler(ctx, req)
+	}))
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+func TestBuildDownstreamGRPCClient_LoadBalancing(t *testing.T) {
+	var a, b atomic.Int32
+	cfg := &config.CommonGRPCDownstreamData{
+		LoadBalancing: &config.LoadBalancingConfig{
+			Endpoints: []config.EndpointConfig{
+				{Address: startCountingGrpcServer(t, &a)},
+				{Address: startCountingGrpcServer(t, &b)},
+			},
+		},
+	}
+	conn, err := BuildDownstreamGRPCClient(ctx, "health", &Hooks{}, cfg)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := grpc_health_v1.NewHealthClient(conn)
+	require.Eventually(t, func() bool {
+		_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{}, grpc.WaitForReady(true))
+		require.NoError(t, err)
+		return a.Load() > 0 && b.Load() > 0
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestGrpcServiceConfig(t *testing.T) {
+	sc, err := grpcServiceConfig(&config.LoadBalancingConfig{
+		Policy:      config.LeastOutstanding,
+		HealthCheck: &config.EndpointHealthCheckConfig{Path: "svc"},
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"loadBalancingConfig":[{"least_request_experimental":{"choiceCount":2}}],"healthCheckConfig":{"serviceName":"svc"}}`, sc)
+
+	_, err = grpcServiceConfig(&config.LoadBalancingConfig{Policy: config.Weighted})
+	require.Error(t, err)
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/go-chi/chi/v5"
 
 	"github.com/anz-bank/pkg/health"
+	"github.com/anz-bank/sysl-go/common"
 	"github.com/anz-bank/sysl-go/config"
 	"github.com/anz-bank/sysl-go/handlerinitialiser"
 	"github.com/anz-bank/sysl-go/metrics"
@@ -65,17 +66,25 @@ func configureAdminServerListener(ctx context.Context, hl Manager, promRegistry
 			hl.AddAdminHTTPMiddleware()(ctx, adminRouter)
 		}
 		r.Route("/status", func(r chi.Router) {
+			r.Use(authorizeAdmin(ctx, config.AdminRouteStatus, false))
 			status.WireRoutes(r, &statusService)
 		})
+		r.With(authorizeAdmin(ctx, config.AdminRouteStatus, false)).Get("/version", versionHandler(buildMetadata))
 		if promRegistry != nil {
 			r.Route("/metrics", func(r chi.Router) {
+				r.Use(authorizeAdmin(ctx, config.AdminRouteMetrics, false))
 				r.Get("/", metrics.Handler(promRegistry).(http.HandlerFunc))
 			})
 		}
 		registerProfilingHandler(ctx, hl.LibraryConfig(), r)
+		registerConfigHandlers(ctx, r)
+		registerLogHandlers(ctx, r)
+		registerInventoryHandlers(ctx, r)
+		registerDebugHandlers(ctx, r)
 	})
 	adminRouter.Route("/", func(r chi.Router) {
 		if healthServer != nil {
+			r.Use(authorizeAdmin(ctx, config.AdminRouteHealth, false))
 			healthServer.RegisterWith(r)
 		}
 	})
@@ -96,6 +105,11 @@ func configurePublicServerListener(ctx context.Context, hl Manager, mWare []func
 	for _, h := range hl.EnabledHandlers() {
 		h.WireRoutes(ctx, publicRouter)
 	}
+	contextTimeout := hl.PublicServerConfig().ContextTimeout
+	if contextTimeout == 0 {
+		contextTimeout = defaultContextTimeout
+	}
+	getInventory(ctx).setHTTPRoutes(rootPublicRouter, contextTimeout)
 
 	if len(hl.EnabledHandlers()) == 0 {
 		anzlog.Info(ctx, "No service handlers enabled by config.")
@@ -115,6 +129,7 @@ func registerProfilingHandler(ctx context.Context, cfg *config.LibraryConfig, pa
 	if cfg.Profiling {
 		anzlog.Info(ctx, "Register profiling handlers")
 		parentRouter.Group(func(r chi.Router) {
+			r.Use(authorizeAdmin(ctx, config.AdminRouteProfiling, false))
 			r.HandleFunc("/pprof", pprof.Index)
 			r.Handle("/allocs", pprof.Handler("allocs"))
 			r.Handle("/block", pprof.Handler("block"))
@@ -203,7 +218,10 @@ func prepareServerListener(ctx context.Context, rootRouter http.Handler, tlsConf
 
 func makeNewServer(ctx context.Context, router http.Handler, tlsConfig *tls.Config, serverConfig config.CommonHTTPServerConfig, serverLogger *log.Logger) *http.Server {
 	listenAddr := fmt.Sprintf("%s:%d", serverConfig.Common.HostName, serverConfig.Common.Port)
-	return &http.Server{
+	if serverConfig.MaxRequestBodyBytes > 0 {
+		router = common.MaxRequestBodyMiddleware(serverConfig.MaxRequestBodyBytes)(router)
+	}
+	server := &http.Server{
 		Addr:              listenAddr,
 		Handler:           router,
 		TLSConfig:         tlsConfig,
@@ -215,6 +233,43 @@ func makeNewServer(ctx context.Context, router http.Handler, tlsConfig *tls.Conf
 		ErrorLog:          serverLogger,
 		BaseContext:       func(net.Listener) context.Context { return ctx },
 	}
+	if serverConfig.ReadHeaderTimeout > 0 {
+		server.ReadHeaderTimeout = serverConfig.ReadHeaderTimeout
+	}
+	if serverConfig.IdleTimeout > 0 {
+		server.IdleTimeout = serverConfig.IdleTimeout
+	}
+	if serverConfig.MaxHeaderBytes > 0 {
+		server.MaxHeaderBytes = serverConfig.MaxHeaderBytes
+	}
+	if serverConfig.DisableKeepAlives {
+		server.SetKeepAlivesEnabled(false)
+	}
+	configureHTTP2(server, serverConfig.HTTP2)
+	return server
+}
+
+// configureHTTP2 applies the HTTP/2 settings to the server. The server is left untouched
+// when the settings are all zero so that the defaults of net/http apply.
+func configureHTTP2(server *http.Server, cfg config.HTTP2ServerConfig) {
+	if cfg == (config.HTTP2ServerConfig{}) {
+		return
+	}
+	if cfg.Disable || cfg.H2C {
+		protocols := new(http.Protocols)
+		protocols.SetHTTP1(true)
+		protocols.SetHTTP2(!cfg.Disable)
+		protocols.SetUnencryptedHTTP2(cfg.H2C)
+		server.Protocols = protocols
+	}
+	server.HTTP2 = &http.HTTP2Config{
+		MaxConcurrentStreams:          cfg.MaxConcurrentStreams,
+		MaxReadFrameSize:              cfg.MaxReadFrameSize,
+		MaxReceiveBufferPerConnection: cfg.MaxReceiveBufferPerConnection,
+		MaxReceiveBufferPerStream:     cfg.MaxReceiveBufferPerStream,
+		SendPingTimeout:               cfg.SendPingTimeout,
+		PingTimeout:                   cfg.PingTimeout,
+	}
 }
 
 func configureRouters(basePath string, mWare []func(handler http.Handler) http.Handler) (rootRouter, router *chi.Mux) {
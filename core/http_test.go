@@ -4,7 +4,9 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"strings"
 	"testing"
@@ -165,6 +167,37 @@ func Test_makeNewServer(t *testing.T) {
 	}
 }
 
+func Test_makeNewServer_Tuning(t *testing.T) {
+	serverConfig := config.CommonHTTPServerConfig{
+		ReadHeaderTimeout:   time.Second,
+		IdleTimeout:         time.Minute,
+		MaxHeaderBytes:      4096,
+		MaxRequestBodyBytes: 10,
+		HTTP2: config.HTTP2ServerConfig{
+			H2C:                  true,
+			MaxConcurrentStreams: 50,
+		},
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := io.ReadAll(r.Body)
+		common.HandleError(r.Context(), w, common.BadRequestError, "Error reading request body", err, nil, nil)
+	})
+	got := makeNewServer(context.Background(), handler, nil, serverConfig, nil)
+	require.Equal(t, time.Second, got.ReadHeaderTimeout)
+	require.Equal(t, time.Minute, got.IdleTimeout)
+	require.Equal(t, 4096, got.MaxHeaderBytes)
+	require.NotNil(t, got.Protocols)
+	require.True(t, got.Protocols.HTTP1())
+	require.True(t, got.Protocols.HTTP2())
+	require.True(t, got.Protocols.UnencryptedHTTP2())
+	require.Equal(t, 50, got.HTTP2.MaxConcurrentStreams)
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("longer than ten bytes")).WithContext(testutil.NewTestContext())
+	w := httptest.NewRecorder()
+	got.Handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
 func Test_prepareServerListener(t *testing.T) {
 	ctx := testutil.NewTestContext()
 
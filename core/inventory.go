@@ -0,0 +1,388 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/anz-bank/sysl-go/log"
+)
+
+type inventoryKey struct{}
+
+// inventory collects the routes a running service serves and the downstreams it calls, which
+// the admin server describes at /routes and /downstreams. Its methods do nothing on a nil
+// inventory.
+type inventory struct {
+	mu            sync.Mutex
+	restAuthRules map[string]string
+	grpcAuthRules map[string]string
+	httpRoutes    chi.Routes
+	httpTimeout   time.Duration
+	grpcServer    *grpc.Server
+	downstreams   []*downstream
+}
+
+// withInventory returns a context holding a new inventory of the service.
+func withInventory(ctx context.Context) context.Context {
+	return context.WithValue(ctx, inventoryKey{}, &inventory{
+		restAuthRules: map[string]string{},
+		grpcAuthRules: map[string]string{},
+	})
+}
+
+// getInventory returns the inventory of the context, or nil if there is none.
+func getInventory(ctx context.Context) *inventory {
+	i, _ := ctx.Value(inventoryKey{}).(*inventory)
+	return i
+}
+
+// addAuthRule records the authorization rule expression of a REST endpoint by name or of a gRPC
+// method by full name, /<service>/<method>.
+func (i *inventory) addAuthRule(grpc bool, endpointName, expression string) {
+	if i == nil {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if grpc {
+		i.grpcAuthRules[endpointName] = expression
+	} else {
+		i.restAuthRules[endpointName] = expression
+	}
+}
+
+// setHTTPRoutes records the routes of the public HTTP server and their timeout.
+func (i *inventory) setHTTPRoutes(routes chi.Routes, timeout time.Duration) {
+	if i == nil {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.httpRoutes, i.httpTimeout = routes, timeout
+}
+
+// setGRPCServer records the public gRPC server.
+func (i *inventory) setGRPCServer(server *grpc.Server) {
+	if i == nil {
+		return
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.grpcServer = server
+}
+
+// addDownstream records a downstream, returning the downstream to record its calls with, which
+// is nil on a nil inventory. Downstreams that are built again, e.g. by the tests of a service,
+// are recorded once.
+func (i *inventory) addDownstream(d *downstream) *downstream {
+	if i == nil {
+		return nil
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, existing := range i.downstreams {
+		if existing.Kind == d.Kind && existing.Name == d.Name {
+			return existing
+		}
+	}
+	i.downstreams = append(i.downstreams, d)
+	return d
+}
+
+// routeInfo describes a route served at /routes.
+type routeInfo struct {
+	// Method is the HTTP method of a REST route, or the full name of a gRPC method.
+	Method string `json:"method"`
+	// Pattern is the chi route pattern of a REST route.
+	Pattern string `json:"pattern,omitempty"`
+	// Handler is the name of the handler function of a REST route.
+	Handler string `json:"handler,omitempty"`
+	// Authorization is the authorization rule expression of the endpoint, if it has one.
+	Authorization string `json:"authorization,omitempty"`
+	// Validated is whether the requests of the route are validated against the spec, which the
+	// REST handlers generated by sysl-go do.
+	Validated bool `json:"validated"`
+	// Timeout is the time allowed to serve a request.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// routes returns the routes of the service.
+func (i *inventory) routes() map[string][]routeInfo {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	result := map[string][]routeInfo{"http": {}, "grpc": {}}
+	if i.httpRoutes != nil {
+		_ = chi.Walk(i.httpRoutes, func(method, route string, handler http.Handler, _ ...func(http.Handler) http.Handler) error {
+			name := handlerName(handler)
+			info := routeInfo{Method: method, Pattern: route, Handler: name}
+			if endpoint, generated := generatedEndpoint(name); generated {
+				info.Authorization = i.restAuthRules[endpoint]
+				info.Validated = true
+			}
+			if i.httpTimeout > 0 {
+				info.Timeout = i.httpTimeout.String()
+			}
+			result["http"] = append(result["http"], info)
+			return nil
+		})
+		sort.Slice(result["http"], func(a, b int) bool {
+			x, y := result["http"][a], result["http"][b]
+			if x.Pattern != y.Pattern {
+				return x.Pattern < y.Pattern
+			}
+			return x.Method < y.Method
+		})
+	}
+	if i.grpcServer != nil {
+		for service, info := range i.grpcServer.GetServiceInfo() {
+			for _, method := range info.Methods {
+				fullMethod := "/" + service + "/" + method.Name
+				result["grpc"] = append(result["grpc"], routeInfo{
+					Method:        fullMethod,
+					Authorization: i.grpcAuthRules[fullMethod],
+				})
+			}
+		}
+		sort.Slice(result["grpc"], func(a, b int) bool { return result["grpc"][a].Method < result["grpc"][b].Method })
+	}
+	return result
+}
+
+// handlerName returns the name of the function of a handler, or else of its type.
+func handlerName(handler http.Handler) string {
+	v := reflect.ValueOf(handler)
+	if v.Kind() != reflect.Func {
+		return reflect.TypeOf(handler).String()
+	}
+	name := runtime.FuncForPC(v.Pointer()).Name()
+	name = strings.TrimSuffix(name, "-fm")
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+// generatedEndpoint returns the name of the endpoint of a handler generated by sysl-go, which
+// is a method of a ServiceHandler named after the endpoint.
+func generatedEndpoint(handlerName string) (string, bool) {
+	_, method, found := strings.Cut(handlerName, ".(*ServiceHandler).")
+	if !found || !strings.HasSuffix(method, "Handler") {
+		return "", false
+	}
+	return strings.TrimSuffix(method, "Handler"), true
+}
+
+// downstream is a downstream of the service and the statistics of its calls.
+type downstream struct {
+	Name   string
+	Kind   string
+	Target string
+	TLS    string
+	Proxy  string
+	// Timeout is the time allowed for a call, zero if there is no limit.
+	Timeout time.Duration
+
+	mu        sync.Mutex
+	calls     int
+	failures  int
+	last      *downstreamCall
+	latencies []time.Duration
+	next      int
+}
+
+// downstreamLatencyWindow is the number of the latest calls of a downstream that its latency
+// percentiles are computed from.
+const downstreamLatencyWindow = 1024
+
+type downstreamCall struct {
+	Time    time.Time `json:"time"`
+	Status  string    `json:"status"`
+	Failed  bool      `json:"failed"`
+	Latency string    `json:"latency"`
+}
+
+// record records a call started at start with its status, which failed or not.
+func (d *downstream) record(start time.Time, status string, failed bool) {
+	if d == nil {
+		return
+	}
+	latency := time.Since(start)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls++
+	if failed {
+		d.failures++
+	}
+	d.last = &downstreamCall{Time: start, Status: status, Failed: failed, Latency: latency.String()}
+	if len(d.latencies) < downstreamLatencyWindow {
+		d.latencies = append(d.latencies, latency)
+	} else {
+		d.latencies[d.next] = latency
+		d.next = (d.next + 1) % downstreamLatencyWindow
+	}
+}
+
+// downstreamInfo describes a downstream served at /downstreams.
+type downstreamInfo struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind"`
+	Target  string `json:"target"`
+	TLS     string `json:"tls"`
+	Proxy   string `json:"proxy,omitempty"`
+	Timeout string `json:"timeout,omitempty"`
+	Calls   int    `json:"calls"`
+	// Failures are the calls that returned an error, or a 5xx status for HTTP.
+	Failures int             `json:"failures"`
+	LastCall *downstreamCall `json:"lastCall,omitempty"`
+	// Latency are the percentiles of the latency of the latest calls, by percentile.
+	Latency map[string]string `json:"latency,omitempty"`
+}
+
+func (d *downstream) info() downstreamInfo {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	info := downstreamInfo{
+		Name:     d.Name,
+		Kind:     d.Kind,
+		Target:   d.Target,
+		TLS:      d.TLS,
+		Proxy:    d.Proxy,
+		Calls:    d.calls,
+		Failures: d.failures,
+		LastCall: d.last,
+	}
+	if d.Timeout > 0 {
+		info.Timeout = d.Timeout.String()
+	}
+	if len(d.latencies) > 0 {
+		sorted := append([]time.Duration(nil), d.latencies...)
+		sort.Slice(sorted, func(a, b int) bool { return sorted[a] < sorted[b] })
+		info.Latency = map[string]string{}
+		for _, p := range []int{50, 90, 99} {
+			info.Latency["p"+strconv.Itoa(p)] = sorted[(len(sorted)-1)*p/100].String()
+		}
+	}
+	return info
+}
+
+// downstreamInfos returns the downstreams of the service.
+func (i *inventory) downstreamInfos() []downstreamInfo {
+	i.mu.Lock()
+	downstreams := append([]*downstream(nil), i.downstreams...)
+	i.mu.Unlock()
+	infos := make([]downstreamInfo, 0, len(downstreams))
+	for _, d := range downstreams {
+		infos = append(infos, d.info())
+	}
+	sort.Slice(infos, func(a, b int) bool {
+		if infos[a].Name != infos[b].Name {
+			return infos[a].Name < infos[b].Name
+		}
+		return infos[a].Kind < infos[b].Kind
+	})
+	return infos
+}
+
+// tlsMode describes the TLS of a connection configured by cfg: none, tls, mtls if it presents
+// a client certificate, or insecure if it does not verify the server.
+func tlsMode(cfg *config.TLSConfig, enabled bool) string {
+	switch {
+	case cfg == nil && !enabled:
+		return "none"
+	case cfg == nil:
+		return "tls"
+	case cfg.InsecureSkipVerify:
+		return "insecure"
+	case len(cfg.ServerIdentities) > 0:
+		return "mtls"
+	default:
+		return "tls"
+	}
+}
+
+// newHTTPDownstream returns the downstream of an HTTP client of cfg, which may be nil.
+func newHTTPDownstream(name, serviceURL string, cfg *config.CommonDownstreamData) *downstream {
+	d := &downstream{Name: name, Kind: "http", Target: serviceURL}
+	u, err := url.Parse(serviceURL)
+	https := err == nil && u.Scheme == "https"
+	if cfg == nil {
+		d.TLS = tlsMode(nil, https)
+		return d
+	}
+	d.TLS = tlsMode(cfg.ClientTransport.ClientTLS, https)
+	d.Timeout = cfg.ClientTimeout
+	if cfg.ClientTransport.UseProxy {
+		d.Proxy = "environment"
+		if cfg.ClientTransport.ProxyURL != "" {
+			d.Proxy = cfg.ClientTransport.ProxyURL
+		}
+	}
+	return d
+}
+
+// downstreamRoundTripper records the calls of an HTTP downstream, and of the recorded request
+// that they are made for, see recordHTTPCall.
+type downstreamRoundTripper struct {
+	downstream *downstream
+	base       http.RoundTripper
+}
+
+func (t *downstreamRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	start := time.Now()
+	r, recordCall := recordHTTPCall(t.downstream.Name, r)
+	resp, err := t.base.RoundTrip(r)
+	recordCall(resp, err)
+	if err != nil {
+		t.downstream.record(start, err.Error(), true)
+	} else {
+		t.downstream.record(start, strconv.Itoa(resp.StatusCode), resp.StatusCode >= http.StatusInternalServerError)
+	}
+	return resp, err
+}
+
+// downstreamInterceptor records the calls of a gRPC downstream, and of the recorded request that
+// they are made for.
+func downstreamInterceptor(d *downstream) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		d.record(start, status.Code(err).String(), err != nil)
+		recordGRPCCall(ctx, d.Name, method, start, err)
+		return err
+	}
+}
+
+// registerInventoryHandlers serves the routes of the service at /routes and its downstreams at
+// /downstreams, to the requests authorized by the admin.auth rule of the inventory routes.
+func registerInventoryHandlers(ctx context.Context, r chi.Router) {
+	inv := getInventory(ctx)
+	if inv == nil {
+		return
+	}
+	writeJSON := func(w http.ResponseWriter, r *http.Request, v interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(v); err != nil {
+			log.Error(r.Context(), err, "failed to write inventory")
+		}
+	}
+	r.Group(func(r chi.Router) {
+		r.Use(authorizeAdmin(ctx, config.AdminRouteInventory, true))
+		r.Get("/routes", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, r, inv.routes())
+		})
+		r.Get("/downstreams", func(w http.ResponseWriter, r *http.Request) {
+			writeJSON(w, r, inv.downstreamInfos())
+		})
+	})
+}
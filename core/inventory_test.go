@@ -0,0 +1,148 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/anz-bank/sysl-go/log"
+)
+
+// ServiceHandler is named after the handlers generated by sysl-go.
+type ServiceHandler struct{}
+
+func (s *ServiceHandler) GetAccountHandler(w http.ResponseWriter, r *http.Request) {}
+
+func pingHandler(w http.ResponseWriter, r *http.Request) {}
+
+type healthServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+}
+
+func TestInventoryRoutes(t *testing.T) {
+	t.Parallel()
+
+	ctx := withInventory(config.PutDefaultConfig(log.PutLogger(context.Background(), log.NewDefaultLogger()), &config.DefaultConfig{}))
+	_, err := ResolveRESTAuthorizationRule(ctx, &Hooks{}, "GetAccount", `jwtHasScope("read")`)
+	require.Error(t, err) // there is no jwtauth config, but the rule is still listed
+
+	r := chi.NewRouter()
+	r.Route("/v1", func(r chi.Router) {
+		r.Get("/accounts/{id}", (&ServiceHandler{}).GetAccountHandler)
+		r.Post("/ping", pingHandler)
+	})
+	getInventory(ctx).setHTTPRoutes(r, 30*time.Second)
+
+	server := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(server, &healthServer{})
+	getInventory(ctx).setGRPCServer(server)
+	getInventory(ctx).addAuthRule(true, "/grpc.health.v1.Health/Check", `jwtHasScope("health")`)
+
+	routes := getInventory(ctx).routes()
+	require.Equal(t, []routeInfo{
+		{
+			Method:        http.MethodGet,
+			Pattern:       "/v1/accounts/{id}",
+			Handler:       "core.(*ServiceHandler).GetAccountHandler",
+			Authorization: `jwtHasScope("read")`,
+			Validated:     true,
+			Timeout:       "30s",
+		},
+		{
+			Method:  http.MethodPost,
+			Pattern: "/v1/ping",
+			Handler: "core.pingHandler",
+			Timeout: "30s",
+		},
+	}, routes["http"])
+	require.Contains(t, routes["grpc"], routeInfo{Method: "/grpc.health.v1.Health/Check", Authorization: `jwtHasScope("health")`})
+	require.Contains(t, routes["grpc"], routeInfo{Method: "/grpc.health.v1.Health/Watch"})
+}
+
+func TestInventoryDownstreams(t *testing.T) {
+	t.Parallel()
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer backend.Close()
+
+	ctx := withInventory(log.PutLogger(context.Background(), log.NewDefaultLogger()))
+	cfg := config.DefaultCommonDownstreamData()
+	cfg.ServiceURL = backend.URL
+	cfg.ClientTransport.UseProxy = true
+	client, _, err := BuildDownstreamHTTPClient(ctx, "backend", nil, cfg)
+	require.NoError(t, err)
+	for _, path := range []string{"/", "/", "/fail"} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, backend.URL+path, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	conn, err := BuildDownstreamGRPCClient(ctx, "encoder", &Hooks{}, &config.CommonGRPCDownstreamData{ServiceAddress: "localhost:0"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	downstreams := getInventory(ctx).downstreamInfos()
+	require.Len(t, downstreams, 2)
+
+	backendInfo := downstreams[0]
+	require.Equal(t, "backend", backendInfo.Name)
+	require.Equal(t, "http", backendInfo.Kind)
+	require.Equal(t, backend.URL, backendInfo.Target)
+	require.Equal(t, "none", backendInfo.TLS)
+	require.Equal(t, "environment", backendInfo.Proxy)
+	require.Equal(t, "1m0s", backendInfo.Timeout)
+	require.Equal(t, 3, backendInfo.Calls)
+	require.Equal(t, 1, backendInfo.Failures)
+	require.Equal(t, "502", backendInfo.LastCall.Status)
+	require.True(t, backendInfo.LastCall.Failed)
+	require.Contains(t, backendInfo.Latency, "p99")
+
+	require.Equal(t, downstreamInfo{Name: "encoder", Kind: "grpc", Target: "localhost:0", TLS: "none"}, downstreams[1])
+}
+
+func TestDownstreamLatencyPercentiles(t *testing.T) {
+	t.Parallel()
+
+	d := &downstream{}
+	for i := 1; i <= 100; i++ {
+		d.latencies = append(d.latencies, time.Duration(i)*time.Millisecond)
+	}
+	require.Equal(t, map[string]string{"p50": "50ms", "p90": "90ms", "p99": "99ms"}, d.info().Latency)
+}
+
+func TestRegisterInventoryHandlers(t *testing.T) {
+	t.Parallel()
+
+	ctx := withInventory(context.Background())
+	r := chi.NewRouter()
+	registerInventoryHandlers(ctx, r)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/routes", nil))
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	ctx, err := withAdminAuth(ctx, &Hooks{AuthorizeAdminRequest: func(r *http.Request) error { return nil }}, nil)
+	require.NoError(t, err)
+	r = chi.NewRouter()
+	registerInventoryHandlers(ctx, r)
+	for _, path := range []string{"/routes", "/downstreams"} {
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		require.True(t, json.Valid(w.Body.Bytes()))
+	}
+}
@@ -0,0 +1,255 @@
+package core
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/anz-bank/sysl-go/log"
+)
+
+// LogLevelHeader is the header of a request that sets the log level of the request, e.g. to
+// trace a single request at debug level. Its value is level:expires:signature, where expires is
+// a Unix time and signature is signed with the library.log.levelHeaderKey, see
+// SignLogLevelHeader.
+const LogLevelHeader = "Sysl-Log-Level"
+
+// SignLogLevelHeader returns the value of the LogLevelHeader that sets the log level of the
+// requests of a service whose library.log.levelHeaderKey is key, until expires.
+func SignLogLevelHeader(key string, level log.Level, expires time.Time) string {
+	payload := level.String() + ":" + strconv.FormatInt(expires.Unix(), 10)
+	return payload + ":" + logLevelSignature(key, payload)
+}
+
+func logLevelSignature(key, payload string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// parseLogLevelHeader returns the level of the value of the LogLevelHeader signed with key.
+func parseLogLevelHeader(key, value string, now time.Time) (log.Level, error) {
+	i := strings.LastIndex(value, ":")
+	if i < 0 {
+		return 0, errors.New("expected level:expires:signature")
+	}
+	payload, signature := value[:i], value[i+1:]
+	if !hmac.Equal([]byte(signature), []byte(logLevelSignature(key, payload))) {
+		return 0, errors.New("invalid signature")
+	}
+	name, expires, _ := strings.Cut(payload, ":")
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid expiry: %w", err)
+	}
+	if !now.Before(time.Unix(unix, 0)) {
+		return 0, errors.New("expired")
+	}
+	return log.ParseLevel(name)
+}
+
+// logLevelMiddleware sets the level of the logger of the requests, which is the level of their
+// LogLevelHeader if it is signed, or else the level of their route, see log.LevelController.
+func logLevelMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		levels := log.GetLevelController(ctx)
+		if levels == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if level, ok := requestLogLevel(ctx, r); ok {
+			ctx = log.WithRequestLevel(ctx, level)
+		} else {
+			ctx = log.WithRouteLevel(ctx, routePattern(r))
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requestLogLevel returns the level of the LogLevelHeader of the request, if it has a valid one.
+func requestLogLevel(ctx context.Context, r *http.Request) (log.Level, bool) {
+	value := r.Header.Get(LogLevelHeader)
+	if value == "" {
+		return 0, false
+	}
+	cfg := config.GetDefaultConfig(ctx)
+	if cfg == nil || cfg.Library.Log.LevelHeaderKey == nil || cfg.Library.Log.LevelHeaderKey.Value() == "" {
+		return 0, false
+	}
+	level, err := parseLogLevelHeader(cfg.Library.Log.LevelHeaderKey.Value(), value, time.Now())
+	if err != nil {
+		log.Infof(ctx, "ignoring the %s header of the request: %s", LogLevelHeader, err)
+		return 0, false
+	}
+	return level, true
+}
+
+// routePattern returns the chi route pattern that the request matches, if any.
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return ""
+	}
+	path := r.URL.RawPath
+	if path == "" {
+		path = r.URL.Path
+	}
+	match := chi.NewRouteContext()
+	if !rctx.Routes.Match(match, r.Method, path) {
+		return ""
+	}
+	return match.RoutePattern()
+}
+
+// logLevels is the state of the log levels served at /log/level.
+type logLevels struct {
+	// Level is the configured level.
+	Level      string             `json:"level"`
+	LogPayload bool               `json:"logPayload"`
+	Overrides  []logLevelOverride `json:"overrides"`
+}
+
+type logLevelOverride struct {
+	Logger  string     `json:"logger,omitempty"`
+	Route   string     `json:"route,omitempty"`
+	Level   string     `json:"level"`
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// setLogLevel is the request to set a log level, globally if neither Logger nor Route is set.
+type setLogLevel struct {
+	Logger string `json:"logger"`
+	Route  string `json:"route"`
+	Level  string `json:"level"`
+	// TTL is the duration after which the level reverts, never if empty.
+	TTL string `json:"ttl"`
+}
+
+// setLogPayload is the request to set whether payloads are logged.
+type setLogPayload struct {
+	Enabled bool `json:"enabled"`
+	// TTL is the duration after which the logging of payloads reverts, never if empty.
+	TTL string `json:"ttl"`
+}
+
+func newLogLevels(levels *log.LevelController) logLevels {
+	state := logLevels{
+		Level:      levels.ConfiguredLevel().String(),
+		LogPayload: levels.LogPayload(),
+		Overrides:  []logLevelOverride{},
+	}
+	for _, o := range levels.Overrides() {
+		override := logLevelOverride{Logger: o.Logger, Route: o.Route, Level: o.Level.String()}
+		if !o.Expires.IsZero() {
+			expires := o.Expires
+			override.Expires = &expires
+		}
+		state.Overrides = append(state.Overrides, override)
+	}
+	return state
+}
+
+func parseTTL(ttl string) (time.Duration, error) {
+	if ttl == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(ttl)
+	if err == nil && d < 0 {
+		err = errors.New("negative duration")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid ttl %q: %w", ttl, err)
+	}
+	return d, nil
+}
+
+// registerLogHandlers serves the log levels of the level controller of the context at
+// /log/level, to the requests authorized by the admin.auth rule of the log routes, see
+// authorizeAdmin:
+//
+//   - GET /log/level returns the configured level, the levels that are set and whether payloads
+//     are logged.
+//   - PUT /log/level {"level": "debug", "logger": "", "route": "", "ttl": "10m"} sets the level
+//     of the loggers named logger, of the requests of the chi route pattern or gRPC method route,
+//     or of every logger if neither is set, reverting after ttl unless it is empty.
+//   - DELETE /log/level?logger=&route= reverts a level.
+//   - PUT /log/payload {"enabled": true, "ttl": "10m"} sets whether payloads are logged and
+//     DELETE /log/payload reverts it.
+//
+// Each of them returns the state of the levels.
+func registerLogHandlers(ctx context.Context, r chi.Router) {
+	levels := log.GetLevelController(ctx)
+	if levels == nil {
+		return
+	}
+	writeLevels := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(newLogLevels(levels)); err != nil {
+			log.Error(r.Context(), err, "failed to write log levels")
+		}
+	}
+
+	r.Route("/log", func(r chi.Router) {
+		r.Use(authorizeAdmin(ctx, config.AdminRouteLog, true))
+		r.Get("/level", writeLevels)
+		r.Put("/level", func(w http.ResponseWriter, r *http.Request) {
+			var req setLogLevel
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+				return
+			}
+			level, err := log.ParseLevel(req.Level)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			ttl, err := parseTTL(req.TTL)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			scope := log.LevelScope{Logger: req.Logger, Route: req.Route}
+			levels.SetLevel(scope, level, ttl)
+			log.Infof(r.Context(), "log level of %+v set to %s with ttl %q", scope, level, req.TTL)
+			writeLevels(w, r)
+		})
+		r.Delete("/level", func(w http.ResponseWriter, r *http.Request) {
+			scope := log.LevelScope{Logger: r.URL.Query().Get("logger"), Route: r.URL.Query().Get("route")}
+			levels.ResetLevel(scope)
+			log.Infof(r.Context(), "log level of %+v reset", scope)
+			writeLevels(w, r)
+		})
+		r.Put("/payload", func(w http.ResponseWriter, r *http.Request) {
+			var req setLogPayload
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+				return
+			}
+			ttl, err := parseTTL(req.TTL)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			levels.SetLogPayload(req.Enabled, ttl)
+			log.Infof(r.Context(), "logging of payloads set to %t with ttl %q", req.Enabled, req.TTL)
+			writeLevels(w, r)
+		})
+		r.Delete("/payload", func(w http.ResponseWriter, r *http.Request) {
+			levels.ResetLogPayload()
+			log.Info(r.Context(), "logging of payloads reset")
+			writeLevels(w, r)
+		})
+	})
+}
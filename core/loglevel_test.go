@@ -0,0 +1,144 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	zero "github.com/anz-bank/pkg/logging"
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/anz-bank/sysl-go/log"
+)
+
+func TestParseLogLevelHeader(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	level, err := parseLogLevelHeader("key", SignLogLevelHeader("key", log.DebugLevel, now.Add(time.Minute)), now)
+	require.NoError(t, err)
+	require.Equal(t, log.DebugLevel, level)
+
+	_, err = parseLogLevelHeader("other", SignLogLevelHeader("key", log.DebugLevel, now.Add(time.Minute)), now)
+	require.EqualError(t, err, "invalid signature")
+	_, err = parseLogLevelHeader("key", SignLogLevelHeader("key", log.DebugLevel, now.Add(-time.Minute)), now)
+	require.EqualError(t, err, "expired")
+	tampered := strings.Replace(SignLogLevelHeader("key", log.ErrorLevel, now.Add(time.Minute)), "error", "debug", 1)
+	_, err = parseLogLevelHeader("key", tampered, now)
+	require.EqualError(t, err, "invalid signature")
+	_, err = parseLogLevelHeader("key", "debug", now)
+	require.EqualError(t, err, "expected level:expires:signature")
+}
+
+func TestLogLevelMiddleware(t *testing.T) {
+	t.Parallel()
+
+	levels := log.NewLevelController(log.InfoLevel, false)
+	levels.SetLevel(log.LevelScope{Route: "/accounts/{id}"}, log.ErrorLevel, 0)
+	key := config.NewSensitiveString("key")
+	cfg := &config.DefaultConfig{}
+	cfg.Library.Log.LevelHeaderKey = &key
+	buf := &bytes.Buffer{}
+	ctx := log.PutLogger(context.Background(), log.NewZeroPkgLogger(zero.New(buf)).WithLevel(log.InfoLevel))
+	ctx = config.PutDefaultConfig(log.WithLevelController(ctx, levels), cfg)
+
+	r := chi.NewRouter()
+	r.Use(logLevelMiddleware)
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		log.Info(r.Context(), "info")
+		log.Debug(r.Context(), "debug")
+	}
+	r.Get("/accounts/{id}", handler)
+	r.Get("/health", handler)
+
+	// serve returns the messages logged by the request
+	serve := func(path, header string) []string {
+		buf.Reset()
+		req := httptest.NewRequest(http.MethodGet, path, nil).WithContext(ctx)
+		if header != "" {
+			req.Header.Set(LogLevelHeader, header)
+		}
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		var messages []string
+		for _, message := range []string{"info", "debug"} {
+			if strings.Contains(buf.String(), `"`+message+`"`) {
+				messages = append(messages, message)
+			}
+		}
+		return messages
+	}
+	require.Empty(t, serve("/accounts/1", ""))
+	require.Equal(t, []string{"info"}, serve("/health", ""))
+	require.Equal(t, []string{"info", "debug"}, serve("/accounts/1", SignLogLevelHeader("key", log.DebugLevel, time.Now().Add(time.Minute))))
+	// the invalid header is ignored, and the reason logged
+	require.NotContains(t, serve("/accounts/1", SignLogLevelHeader("other", log.DebugLevel, time.Now().Add(time.Minute))), "debug")
+}
+
+func TestRegisterLogHandlers(t *testing.T) {
+	t.Parallel()
+
+	levels := log.NewLevelController(log.InfoLevel, false)
+	ctx := log.WithLevelController(context.Background(), levels)
+	ctx, err := withAdminAuth(ctx, &Hooks{AuthorizeAdminRequest: func(r *http.Request) error { return nil }}, nil)
+	require.NoError(t, err)
+	r := chi.NewRouter()
+	registerLogHandlers(ctx, r)
+
+	serve := func(method, path, body string) (int, logLevels) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req = req.WithContext(log.PutLogger(context.Background(), log.NewDefaultLogger()))
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		var state logLevels
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &state))
+		}
+		return w.Code, state
+	}
+
+	code, state := serve(http.MethodGet, "/log/level", "")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, logLevels{Level: "info", Overrides: []logLevelOverride{}}, state)
+
+	code, state = serve(http.MethodPut, "/log/level", `{"level": "debug", "logger": "db", "ttl": "10m"}`)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, state.Overrides, 1)
+	require.Equal(t, "db", state.Overrides[0].Logger)
+	require.Equal(t, "debug", state.Overrides[0].Level)
+	require.NotNil(t, state.Overrides[0].Expires)
+	require.Equal(t, log.DebugLevel, levels.Level("db", ""))
+
+	code, _ = serve(http.MethodPut, "/log/level", `{"level": "trace"}`)
+	require.Equal(t, http.StatusBadRequest, code)
+	code, _ = serve(http.MethodPut, "/log/level", `{"level": "debug", "ttl": "-1m"}`)
+	require.Equal(t, http.StatusBadRequest, code)
+
+	code, state = serve(http.MethodDelete, "/log/level?logger=db", "")
+	require.Equal(t, http.StatusOK, code)
+	require.Empty(t, state.Overrides)
+
+	code, state = serve(http.MethodPut, "/log/payload", `{"enabled": true}`)
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, state.LogPayload)
+	code, state = serve(http.MethodDelete, "/log/payload", "")
+	require.Equal(t, http.StatusOK, code)
+	require.False(t, state.LogPayload)
+}
+
+func TestRegisterLogHandlersDeniesWithoutAuthorizer(t *testing.T) {
+	t.Parallel()
+
+	r := chi.NewRouter()
+	registerLogHandlers(log.WithLevelController(context.Background(), log.NewLevelController(log.InfoLevel, false)), r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/log/level", strings.NewReader(`{"level": "debug"}`)))
+	require.Equal(t, http.StatusForbidden, w.Code)
+}
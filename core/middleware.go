@@ -20,8 +20,8 @@ func prepareMiddleware(name string, promRegistry *prometheus.Registry, contextTi
 	result.addToBoth(Recoverer)
 	result.addToBoth(common.Timeout(contextTimeout, http.HandlerFunc(timeoutHandler)))
 
-	result.public = append(result.public, common.TraceabilityMiddleware)
-	result.addToBoth(common.CoreRequestContextMiddleware)
+	result.public = append(result.public, common.TraceabilityMiddleware, common.DeadlineMiddleware, recordRequestsMiddleware)
+	result.addToBoth(logLevelMiddleware, common.CoreRequestContextMiddleware)
 
 	if promRegistry != nil {
 		metricsMiddleware := metrics.NewHTTPServerMetricsMiddleware(promRegistry, name, metrics.GetChiPathPattern)
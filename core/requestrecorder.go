@@ -0,0 +1,433 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"google.golang.org/grpc/status"
+
+	"github.com/anz-bank/sysl-go/common"
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/anz-bank/sysl-go/log"
+)
+
+const (
+	defaultRecordedRequests = 100
+	defaultRecordedBodySize = 4096
+)
+
+// redactedHeaders are the headers whose values are always redacted from the recorded requests.
+var redactedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	LogLevelHeader,
+}
+
+type requestRecorderKey struct{}
+type recordedRequestKey struct{}
+
+// requestRecorder keeps the latest inbound HTTP requests of the service and their downstream
+// calls, see config.RequestRecorderConfig.
+type requestRecorder struct {
+	maxBodyBytes int
+	redact       map[string]bool
+
+	mu       sync.Mutex
+	requests []*recordedRequest // a ring buffer of the latest requests
+	next     int
+	size     int
+}
+
+// withRequestRecorder returns a context holding a new request recorder, if it is enabled by
+// cfg.Admin.Debug.Requests.
+func withRequestRecorder(ctx context.Context, cfg *config.DefaultConfig) context.Context {
+	if cfg == nil || cfg.Admin == nil || cfg.Admin.Debug == nil || cfg.Admin.Debug.Requests == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, requestRecorderKey{}, newRequestRecorder(cfg.Admin.Debug.Requests))
+}
+
+func getRequestRecorder(ctx context.Context) *requestRecorder {
+	rec, _ := ctx.Value(requestRecorderKey{}).(*requestRecorder)
+	return rec
+}
+
+func newRequestRecorder(cfg *config.RequestRecorderConfig) *requestRecorder {
+	rec := &requestRecorder{
+		size:         cfg.Size,
+		maxBodyBytes: cfg.MaxBodyBytes,
+		redact:       map[string]bool{},
+	}
+	if rec.size == 0 {
+		rec.size = defaultRecordedRequests
+	}
+	if rec.maxBodyBytes == 0 {
+		rec.maxBodyBytes = defaultRecordedBodySize
+	}
+	for _, h := range append(append([]string(nil), redactedHeaders...), cfg.RedactHeaders...) {
+		rec.redact[http.CanonicalHeaderKey(h)] = true
+	}
+	return rec
+}
+
+func (rec *requestRecorder) add(req *recordedRequest) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.requests) < rec.size {
+		rec.requests = append(rec.requests, req)
+		return
+	}
+	rec.requests[rec.next] = req
+	rec.next = (rec.next + 1) % rec.size
+}
+
+// headers returns a copy of h whose sensitive values are redacted.
+func (rec *requestRecorder) headers(h http.Header) http.Header {
+	result := make(http.Header, len(h))
+	for name, values := range h {
+		if rec.redact[http.CanonicalHeaderKey(name)] {
+			values = []string{config.DefaultReplacementText}
+		}
+		result[name] = append([]string(nil), values...)
+	}
+	return result
+}
+
+// recordedRequest is an inbound request of the service.
+type recordedRequest struct {
+	traceID        string
+	method         string
+	path           string
+	route          string
+	status         int
+	start          time.Time
+	duration       time.Duration
+	requestHeader  http.Header
+	responseHeader http.Header
+	requestBody    *limitedBuffer
+	responseBody   *limitedBuffer
+
+	mu    sync.Mutex
+	calls []*recordedCall
+}
+
+// recordedCall is a call of a downstream made while serving a recorded request.
+type recordedCall struct {
+	Downstream     string        `json:"downstream"`
+	Method         string        `json:"method"`
+	URL            string        `json:"url,omitempty"`
+	Status         string        `json:"status"`
+	Start          time.Time     `json:"start"`
+	Duration       string        `json:"duration"`
+	RequestHeader  http.Header   `json:"requestHeader,omitempty"`
+	ResponseHeader http.Header   `json:"responseHeader,omitempty"`
+	RequestBody    *recordedBody `json:"requestBody,omitempty"`
+	ResponseBody   *recordedBody `json:"responseBody,omitempty"`
+
+	requestBody  *limitedBuffer
+	responseBody *limitedBuffer
+}
+
+func (req *recordedRequest) addCall(call *recordedCall) {
+	req.mu.Lock()
+	defer req.mu.Unlock()
+	req.calls = append(req.calls, call)
+}
+
+// limitedBuffer keeps the first bytes written to it, discarding the rest.
+type limitedBuffer struct {
+	mu        sync.Mutex
+	max       int
+	buf       []byte
+	truncated bool
+}
+
+func newLimitedBuffer(max int) *limitedBuffer {
+	return &limitedBuffer{max: max}
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := len(p)
+	if free := b.max - len(b.buf); n > free {
+		p = p[:free]
+		b.truncated = true
+	}
+	b.buf = append(b.buf, p...)
+	return n, nil
+}
+
+func (b *limitedBuffer) body() *recordedBody {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.buf) == 0 {
+		return nil
+	}
+	return &recordedBody{Content: string(b.buf), Truncated: b.truncated}
+}
+
+// teeBody writes what is read from a body to a buffer.
+type teeBody struct {
+	io.ReadCloser
+	buf *limitedBuffer
+}
+
+func (t *teeBody) Read(p []byte) (int, error) {
+	n, err := t.ReadCloser.Read(p)
+	if n > 0 {
+		_, _ = t.buf.Write(p[:n])
+	}
+	return n, err
+}
+
+// recordRequestsMiddleware records the requests with the request recorder of their context, if
+// it has one.
+func recordRequestsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := getRequestRecorder(r.Context())
+		if rec == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		req := &recordedRequest{
+			method:        r.Method,
+			path:          r.URL.Path,
+			start:         time.Now(),
+			requestHeader: rec.headers(r.Header),
+			requestBody:   newLimitedBuffer(rec.maxBodyBytes),
+			responseBody:  newLimitedBuffer(rec.maxBodyBytes),
+		}
+		if id, ok := common.LookupTraceIDFromContext(r.Context()); ok {
+			req.traceID = id.String()
+		}
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = &teeBody{r.Body, req.requestBody}
+		}
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		ww.Tee(req.responseBody)
+		defer func() {
+			req.duration = time.Since(req.start)
+			req.status = ww.Status()
+			if req.status == 0 {
+				req.status = http.StatusOK
+			}
+			req.responseHeader = rec.headers(ww.Header())
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				req.route = rctx.RoutePattern()
+			}
+			rec.add(req)
+		}()
+		next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), recordedRequestKey{}, req)))
+	})
+}
+
+// recordHTTPCall records the call of an HTTP downstream made with r, returning the request to
+// make the call with and a function that records its result, if the context of r is the context
+// of a recorded request.
+func recordHTTPCall(name string, r *http.Request) (*http.Request, func(*http.Response, error)) {
+	req, _ := r.Context().Value(recordedRequestKey{}).(*recordedRequest)
+	rec := getRequestRecorder(r.Context())
+	if req == nil || rec == nil {
+		return r, func(*http.Response, error) {}
+	}
+	u := *r.URL
+	u.RawQuery, u.User = "", nil
+	call := &recordedCall{
+		Downstream:    name,
+		Method:        r.Method,
+		URL:           u.String(),
+		Start:         time.Now(),
+		RequestHeader: rec.headers(r.Header),
+		requestBody:   newLimitedBuffer(rec.maxBodyBytes),
+		responseBody:  newLimitedBuffer(rec.maxBodyBytes),
+	}
+	if r.Body != nil && r.Body != http.NoBody {
+		r = r.Clone(r.Context())
+		r.Body = &teeBody{r.Body, call.requestBody}
+	}
+	return r, func(resp *http.Response, err error) {
+		call.Duration = time.Since(call.Start).String()
+		if err != nil {
+			call.Status = err.Error()
+		} else {
+			call.Status = strconv.Itoa(resp.StatusCode)
+			call.ResponseHeader = rec.headers(resp.Header)
+			resp.Body = &teeBody{resp.Body, call.responseBody}
+		}
+		req.addCall(call)
+	}
+}
+
+// recordGRPCCall records the call of the gRPC method of a downstream, if ctx is the context of
+// a recorded request.
+func recordGRPCCall(ctx context.Context, name, method string, start time.Time, err error) {
+	req, _ := ctx.Value(recordedRequestKey{}).(*recordedRequest)
+	if req == nil {
+		return
+	}
+	req.addCall(&recordedCall{
+		Downstream: name,
+		Method:     method,
+		Status:     status.Code(err).String(),
+		Start:      start,
+		Duration:   time.Since(start).String(),
+	})
+}
+
+// recordedRequestInfo is a recorded request served at /debug/requests.
+type recordedRequestInfo struct {
+	Method         string          `json:"method"`
+	Path           string          `json:"path"`
+	Route          string          `json:"route,omitempty"`
+	Status         int             `json:"status"`
+	Start          time.Time       `json:"start"`
+	Duration       string          `json:"duration"`
+	RequestHeader  http.Header     `json:"requestHeader,omitempty"`
+	ResponseHeader http.Header     `json:"responseHeader,omitempty"`
+	RequestBody    *recordedBody   `json:"requestBody,omitempty"`
+	ResponseBody   *recordedBody   `json:"responseBody,omitempty"`
+	Downstream     []*recordedCall `json:"downstream"`
+}
+
+type recordedBody struct {
+	Content   string `json:"content"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+// recordedTrace is the recorded requests of a trace ID.
+type recordedTrace struct {
+	TraceID  string                `json:"traceId"`
+	Requests []recordedRequestInfo `json:"requests"`
+}
+
+func (req *recordedRequest) info() recordedRequestInfo {
+	info := recordedRequestInfo{
+		Method:         req.method,
+		Path:           req.path,
+		Route:          req.route,
+		Status:         req.status,
+		Start:          req.start,
+		Duration:       req.duration.String(),
+		RequestHeader:  req.requestHeader,
+		ResponseHeader: req.responseHeader,
+		RequestBody:    req.requestBody.body(),
+		ResponseBody:   req.responseBody.body(),
+		Downstream:     []*recordedCall{},
+	}
+	req.mu.Lock()
+	defer req.mu.Unlock()
+	for _, c := range req.calls {
+		call := *c
+		call.RequestBody = c.requestBody.body()
+		call.ResponseBody = c.responseBody.body()
+		info.Downstream = append(info.Downstream, &call)
+	}
+	return info
+}
+
+// requestFilter selects the recorded requests of a route, a status, e.g. 404 or 4xx, and a trace
+// ID, each of which matches every request if it is empty.
+type requestFilter struct {
+	route   string
+	status  string
+	traceID string
+}
+
+func parseRequestFilter(r *http.Request) (requestFilter, error) {
+	q := r.URL.Query()
+	f := requestFilter{route: q.Get("route"), status: strings.ToLower(q.Get("status")), traceID: q.Get("traceId")}
+	if f.status != "" {
+		valid := len(f.status) == 3 && f.status[0] >= '1' && f.status[0] <= '5'
+		if strings.HasSuffix(f.status, "xx") {
+			valid = valid && f.status[1:] == "xx"
+		} else {
+			_, err := strconv.Atoi(f.status)
+			valid = valid && err == nil
+		}
+		if !valid {
+			return f, fmt.Errorf("invalid status %q, expected e.g. 404 or 4xx", f.status)
+		}
+	}
+	return f, nil
+}
+
+func (f requestFilter) matches(req *recordedRequest) bool {
+	if f.route != "" && f.route != req.route {
+		return false
+	}
+	if f.traceID != "" && f.traceID != req.traceID {
+		return false
+	}
+	if strings.HasSuffix(f.status, "xx") {
+		return req.status/100 == int(f.status[0]-'0')
+	}
+	return f.status == "" || f.status == strconv.Itoa(req.status)
+}
+
+// traces returns the recorded requests selected by f grouped by trace ID, starting with the
+// trace of the latest request.
+func (rec *requestRecorder) traces(f requestFilter) []recordedTrace {
+	rec.mu.Lock()
+	requests := append(append([]*recordedRequest(nil), rec.requests[rec.next:]...), rec.requests[:rec.next]...)
+	rec.mu.Unlock()
+
+	traces := []recordedTrace{}
+	index := map[string]int{}
+	for i := len(requests) - 1; i >= 0; i-- {
+		req := requests[i]
+		if !f.matches(req) {
+			continue
+		}
+		j, ok := index[req.traceID]
+		if !ok || req.traceID == "" {
+			j = len(traces)
+			index[req.traceID] = j
+			traces = append(traces, recordedTrace{TraceID: req.traceID})
+		}
+		traces[j].Requests = append(traces[j].Requests, req.info())
+	}
+	for _, t := range traces {
+		sort.SliceStable(t.Requests, func(a, b int) bool { return t.Requests[a].Start.Before(t.Requests[b].Start) })
+	}
+	return traces
+}
+
+// registerDebugHandlers serves the recorded requests at /debug/requests?route=&status=&traceId=,
+// to the requests authorized by the admin.auth rule of the debug routes.
+func registerDebugHandlers(ctx context.Context, r chi.Router) {
+	rec := getRequestRecorder(ctx)
+	if rec == nil {
+		return
+	}
+	r.Route("/debug", func(r chi.Router) {
+		r.Use(authorizeAdmin(ctx, config.AdminRouteDebug, true))
+		r.Get("/requests", func(w http.ResponseWriter, r *http.Request) {
+			f, err := parseRequestFilter(r)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if err := json.NewEncoder(w).Encode(rec.traces(f)); err != nil {
+				log.Error(r.Context(), err, "failed to write recorded requests")
+			}
+		})
+	})
+}
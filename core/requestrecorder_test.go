@@ -0,0 +1,229 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/sethvargo/go-retry"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anz-bank/sysl-go/common"
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/anz-bank/sysl-go/handlerinitialiser"
+	"github.com/anz-bank/sysl-go/log"
+)
+
+// pingService serves GET /ping.
+type pingService struct{}
+
+func (pingService) WireRoutes(ctx context.Context, r chi.Router) { r.Get("/ping", pingHandler) }
+func (pingService) Name() string                                 { return "ping" }
+func (pingService) Config() interface{}                          { return nil }
+
+// freePort returns a port that is free to listen on.
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestRecordRequests(t *testing.T) {
+	t.Parallel()
+
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret")
+		_, _ = w.Write([]byte(`{"balance": 100}`))
+	}))
+	defer backend.Close()
+
+	ctx := withInventory(log.PutLogger(context.Background(), log.NewDefaultLogger()))
+	ctx = withRequestRecorder(ctx, &config.DefaultConfig{Admin: &config.AdminConfig{Debug: &config.AdminDebugConfig{
+		Requests: &config.RequestRecorderConfig{Size: 2, MaxBodyBytes: 8, RedactHeaders: []string{"x-secret"}},
+	}}})
+	cfg := config.DefaultCommonDownstreamData()
+	cfg.ServiceURL = backend.URL
+	client, _, err := BuildDownstreamHTTPClient(ctx, "backend", nil, cfg)
+	require.NoError(t, err)
+
+	r := chi.NewRouter()
+	r.Use(common.TraceabilityMiddleware, recordRequestsMiddleware)
+	r.Post("/accounts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, backend.URL+"/balance?token=secret", nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		_, _ = io.Copy(w, resp.Body)
+		require.NoError(t, resp.Body.Close())
+	})
+	r.Get("/missing", http.NotFound)
+	serve := func(method, path, body string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body)).WithContext(ctx)
+		req.Header.Set("Authorization", "Bearer token")
+		req.Header.Set("X-Secret", "secret")
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	serve(http.MethodGet, "/missing", "")
+	serve(http.MethodPost, "/accounts/1", `{"amount": 100}`)
+	serve(http.MethodGet, "/missing", "")
+
+	rec := getRequestRecorder(ctx)
+	traces := rec.traces(requestFilter{})
+	require.Len(t, traces, 2)
+	require.Equal(t, "/missing", traces[0].Requests[0].Path)
+
+	account := traces[1].Requests[0]
+	require.Equal(t, "/accounts/{id}", account.Route)
+	require.Equal(t, http.StatusOK, account.Status)
+	require.Equal(t, config.DefaultReplacementText, account.RequestHeader.Get("Authorization"))
+	require.Equal(t, config.DefaultReplacementText, account.RequestHeader.Get("X-Secret"))
+	require.Equal(t, "application/json", account.RequestHeader.Get("Content-Type"))
+	require.Equal(t, &recordedBody{Content: `{"amount`, Truncated: true}, account.RequestBody)
+	require.Equal(t, &recordedBody{Content: `{"balanc`, Truncated: true}, account.ResponseBody)
+
+	require.Len(t, account.Downstream, 1)
+	call := account.Downstream[0]
+	require.Equal(t, "backend", call.Downstream)
+	require.Equal(t, backend.URL+"/balance", call.URL)
+	require.Equal(t, "200", call.Status)
+	require.Equal(t, config.DefaultReplacementText, call.ResponseHeader.Get("Set-Cookie"))
+	require.Equal(t, &recordedBody{Content: `{"balanc`, Truncated: true}, call.ResponseBody)
+
+	require.Len(t, rec.traces(requestFilter{status: "2xx"}), 1)
+	require.Len(t, rec.traces(requestFilter{status: "404"}), 1)
+	require.Len(t, rec.traces(requestFilter{route: "/accounts/{id}"}), 1)
+	require.Len(t, rec.traces(requestFilter{traceID: traces[0].TraceID}), 1)
+}
+
+func TestRecordRequestsGroupsTraces(t *testing.T) {
+	t.Parallel()
+
+	rec := newRequestRecorder(&config.RequestRecorderConfig{})
+	for _, id := range []string{"a", "b", "a"} {
+		rec.add(&recordedRequest{traceID: id, status: http.StatusOK})
+	}
+	traces := rec.traces(requestFilter{})
+	require.Len(t, traces, 2)
+	require.Equal(t, "a", traces[0].TraceID)
+	require.Len(t, traces[0].Requests, 2)
+	require.Equal(t, "b", traces[1].TraceID)
+}
+
+func TestParseRequestFilter(t *testing.T) {
+	t.Parallel()
+
+	for query, valid := range map[string]bool{
+		"":                   true,
+		"status=404":         true,
+		"status=5XX":         true,
+		"status=600":         false,
+		"status=4x4":         false,
+		"status=ok":          false,
+		"route=/a&traceId=b": true,
+	} {
+		_, err := parseRequestFilter(httptest.NewRequest(http.MethodGet, "/?"+query, nil))
+		require.Equal(t, valid, err == nil, query)
+	}
+}
+
+func TestRegisterDebugHandlers(t *testing.T) {
+	t.Parallel()
+
+	ctx := withRequestRecorder(context.Background(), &config.DefaultConfig{Admin: &config.AdminConfig{Debug: &config.AdminDebugConfig{
+		Requests: &config.RequestRecorderConfig{},
+	}}})
+	ctx, err := withAdminAuth(ctx, &Hooks{AuthorizeAdminRequest: func(r *http.Request) error { return nil }}, nil)
+	require.NoError(t, err)
+	r := chi.NewRouter()
+	registerDebugHandlers(ctx, r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/requests", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var traces []recordedTrace
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &traces))
+	require.Empty(t, traces)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/requests?status=bad", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestNewServerRecordsRequests(t *testing.T) {
+	adminPort, publicPort := freePort(t), freePort(t)
+	ctx := WithConfigFile(context.Background(), []byte(fmt.Sprintf(`
+admin:
+  contextTimeout: 5s
+  http:
+    readTimeout: 5s
+    writeTimeout: 5s
+    common:
+      hostName: localhost
+      port: %d
+  auth:
+    routes:
+      debug:
+        open: true
+  debug:
+    requests:
+      size: 10
+genCode:
+  upstream:
+    contextTimeout: 5s
+    http:
+      readTimeout: 5s
+      writeTimeout: 5s
+      common:
+        hostName: localhost
+        port: %d
+`, adminPort, publicPort)))
+
+	srv, err := NewServer(ctx, &struct{}{},
+		func(ctx context.Context, config TestAppConfig) (*TestServiceInterface, *Hooks, error) {
+			return &TestServiceInterface{}, &Hooks{}, nil
+		},
+		&TestServiceInterface{},
+		func(ctx context.Context, serviceIntf interface{}, _ *Hooks) (Manager, *GrpcServerManager, error) {
+			cfg := config.GetDefaultConfig(ctx)
+			return NewHTTPManagerShim(&cfg.Library, &cfg.Admin.HTTP, &cfg.GenCode.Upstream,
+				[]handlerinitialiser.HandlerInitialiser{pingService{}}, nil), nil, nil
+		},
+	)
+	require.NoError(t, err)
+	go func() { _ = srv.Start() }()
+	defer func() { _ = srv.Stop() }()
+
+	get := func(port int, path string) (*http.Response, error) {
+		return http.Get(fmt.Sprintf("http://localhost:%d%s", port, path))
+	}
+	backoff := retry.WithMaxDuration(5*time.Second, retry.NewFibonacci(20*time.Millisecond))
+	require.NoError(t, retry.Do(context.Background(), backoff, func(ctx context.Context) error {
+		resp, err := get(publicPort, "/ping")
+		if err != nil {
+			return retry.RetryableError(err)
+		}
+		return resp.Body.Close()
+	}))
+
+	resp, err := get(adminPort, "/-/debug/requests?route=/ping")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var traces []recordedTrace
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&traces))
+	require.Len(t, traces, 1)
+	require.Equal(t, "/ping", traces[0].Requests[0].Path)
+	require.Equal(t, http.StatusOK, traces[0].Requests[0].Status)
+}
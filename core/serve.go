@@ -13,7 +13,6 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/spf13/afero"
 	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/worker"
 
@@ -24,16 +23,18 @@ import (
 	"github.com/anz-bank/sysl-go/config"
 	"github.com/anz-bank/sysl-go/health"
 	"github.com/anz-bank/sysl-go/log"
-	"github.com/anz-bank/sysl-go/validator"
 )
 
 type serveContextKey int
 
 const (
 	serveYAMLConfigFileKey serveContextKey = iota
-	defaultContextTimeout                  = 30 * time.Second
+	serveCommandLineKey
+	defaultContextTimeout = 30 * time.Second
 )
 
+// ErrDisplayHelp is returned instead of a server when the command line asks for the help or for
+// another command than serve, which has already run successfully.
 type ErrDisplayHelp int
 
 func (e ErrDisplayHelp) Error() string {
@@ -48,6 +49,13 @@ func WithConfigFile(ctx context.Context, yamlConfigData []byte) context.Context
 	return context.WithValue(ctx, serveYAMLConfigFileKey, yamlConfigData)
 }
 
+// WithCommandLine adds command line arguments into the context, excluding the name of the
+// program. They are used instead of the arguments of the process, such as to run a command
+// of the service binary from a test.
+func WithCommandLine(ctx context.Context, args ...string) context.Context {
+	return context.WithValue(ctx, serveCommandLineKey, args)
+}
+
 // Serve is deprecated and will be removed once downstream applications cease
 // depending upon it. Generated code will no longer call this function.
 // This is a shim for compatibility with code generated by sysl-go versions v0.122.0 & earlier.
@@ -63,14 +71,14 @@ func Serve(
 	return srv.Start()
 }
 
-func validateConfig(ctx context.Context, hooks *Hooks, conf *config.DefaultConfig) error {
+func validateConfig(ctx context.Context, hooks *Hooks, reader config.ConfigReader, conf *config.DefaultConfig) error {
 	// Validate the hooks returned from service creation.
 	if hooks != nil && hooks.ValidateConfig != nil {
 		if err := hooks.ValidateConfig(ctx, conf); err != nil {
 			return err
 		}
 	}
-	return validator.Validate(conf)
+	return reader.Validate(conf)
 }
 
 func withLogLevel(ctx context.Context, defaultConfig *config.DefaultConfig) context.Context {
@@ -80,6 +88,8 @@ func withLogLevel(ctx context.Context, defaultConfig *config.DefaultConfig) cont
 	if defaultConfig.Library.Log.Level != 0 {
 		level = defaultConfig.Library.Log.Level
 	}
+	// The level and the logging of payloads can be changed at runtime, see registerLogHandlers.
+	ctx = log.WithLevelController(ctx, log.NewLevelController(level, defaultConfig.Library.Log.LogPayload))
 	return log.WithLevel(ctx, level)
 }
 
@@ -115,24 +125,41 @@ func NewTemporalWorker[
 				"log.PutLogger before core.NewServer"))
 	}
 
-	defaultConfig, appConfig, err := createDefaultConfig(ctx, downstreamConfig, createService)
+	defaultConfig, appConfig, cl, err := createDefaultConfig(ctx, downstreamConfig, createService)
 	if err != nil {
 		return nil, err
 	}
 	ctx = config.PutDefaultConfig(ctx, defaultConfig)
+	ctx = withCustomConfig(ctx, cl.config, cl.reader)
 
 	serviceIntf, hooks, err := createService(ctx, *appConfig)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = validateConfig(ctx, hooks, defaultConfig); err != nil {
+	if err = validateConfig(ctx, hooks, cl.reader, defaultConfig); err != nil {
 		return nil, err
 	}
+	if cl.isApplicationCommand() {
+		return nil, cl.runApplicationCommand(ctx, hooks)
+	}
+	if ctx, err = withAdminAuth(ctx, hooks, defaultConfig); err != nil {
+		return nil, err
+	}
+	ctx = withInventory(ctx)
 
-	clientOptions := client.Options{
-		HostPort:  defaultConfig.GenCode.Upstream.Temporal.HostPort,
-		Namespace: defaultConfig.GenCode.Upstream.Temporal.Namespace,
+	// Collect prometheus metrics if the admin server is enabled.
+	var promRegistry *prometheus.Registry
+	if defaultConfig.Admin != nil {
+		promRegistry = prometheus.NewRegistry()
+		registerBuildInfo(promRegistry, buildMetadata)
+		ctx = WithPrometheusRegistry(ctx, promRegistry)
+	}
+
+	temporalConfig := &defaultConfig.GenCode.Upstream.Temporal
+	clientOptions, err := temporalClientOptions(ctx, temporalConfig.HostPort, temporalConfig.Namespace, "", &temporalConfig.TemporalConnectionConfig)
+	if err != nil {
+		return nil, err
 	}
 
 	if hooks.ExperimentalValidateTemporalClientOptions != nil {
@@ -156,9 +183,7 @@ func NewTemporalWorker[
 		return nil, err
 	}
 
-	workerOptions := worker.Options{
-		BackgroundActivityContext: ctx,
-	}
+	workerOptions := temporalWorkerOptions(ctx, &temporalConfig.Worker)
 
 	if hooks.ExperimentalValidateTemporalWorkerOptions != nil {
 		if err = hooks.ExperimentalValidateTemporalWorkerOptions(ctx, &workerOptions); err != nil {
@@ -171,29 +196,38 @@ func NewTemporalWorker[
 		buildWorker = hooks.ExperimentalTemporalWorkerBuilder
 	}
 
-	return &TemporalServer[TemporalServiceHandler]{
+	server := &TemporalServer[TemporalServiceHandler]{
 		Spec: buildServiceHandler(
 			temporalClient,
 			buildWorker(temporalClient, taskQueueName, workerOptions),
 			serviceIntf,
 			downstreamClients,
 		),
-	}, nil
+		ctx:       ctx,
+		taskQueue: taskQueueName,
+	}
+	if defaultConfig.Admin != nil {
+		server.admin, err = configureTemporalAdminServer(ctx, defaultConfig, hooks, promRegistry, temporalClient, taskQueueName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return server, nil
 }
 
 func createDefaultConfig[DownstreamConfig, AppConfig, Handlers any](
 	ctx context.Context,
 	downstreamConfig DownstreamConfig,
 	createService func(context.Context, AppConfig) (Handlers, *Hooks, error),
-) (*config.DefaultConfig, *AppConfig, error) {
+) (*config.DefaultConfig, *AppConfig, *commandLine, error) {
 	// Load the custom configuration.
 	customConfig := NewZeroCustomConfig(reflect.TypeOf(downstreamConfig), GetAppConfigType(createService))
-	customConfig, err := LoadCustomConfig(ctx, customConfig)
+	customConfig, cl, err := loadCustomConfig(ctx, customConfig)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 	if customConfig == nil {
-		return nil, nil, fmt.Errorf("configuration is empty")
+		return nil, nil, nil, fmt.Errorf("configuration is empty")
 	}
 
 	customConfigValue := reflect.ValueOf(customConfig).Elem()
@@ -214,7 +248,7 @@ func createDefaultConfig[DownstreamConfig, AppConfig, Handlers any](
 
 	appConfigValue := customConfigValue.FieldByName("App").Interface().(AppConfig)
 
-	return &config.DefaultConfig{
+	defaultConfig := &config.DefaultConfig{
 		Library:     library,
 		Admin:       admin,
 		Development: development,
@@ -222,7 +256,11 @@ func createDefaultConfig[DownstreamConfig, AppConfig, Handlers any](
 			Upstream:   upstream,
 			Downstream: downstream,
 		},
-	}, &appConfigValue, nil
+	}
+	if err = cl.configErrors(defaultConfig); err != nil {
+		return nil, nil, nil, err
+	}
+	return defaultConfig, &appConfigValue, cl, nil
 }
 
 // NewServer returns an auto-generated service.
@@ -256,7 +294,7 @@ func NewServer(
 	// Load the custom configuration.
 	MustTypeCheckCreateService(createService, serviceInterface)
 	customConfig := NewZeroCustomConfig(reflect.TypeOf(downstreamConfig), GetAppConfigType(createService))
-	customConfig, err := LoadCustomConfig(ctx, customConfig)
+	customConfig, cl, err := loadCustomConfig(ctx, customConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -290,9 +328,13 @@ func NewServer(
 			Downstream: downstream,
 		},
 	}
+	if err = cl.configErrors(defaultConfig); err != nil {
+		return nil, err
+	}
 
 	// Put the default configuration in the context.
 	ctx = config.PutDefaultConfig(ctx, defaultConfig)
+	ctx = withCustomConfig(ctx, cl.config, cl.reader)
 
 	// Create the service by calling the create-service callback.
 	createServiceResult := reflect.ValueOf(createService).Call(
@@ -305,7 +347,7 @@ func NewServer(
 	hooksIntf := createServiceResult[1].Interface()
 
 	hooks := hooksIntf.(*Hooks)
-	if err = validateConfig(ctx, hooks, defaultConfig); err != nil {
+	if err = validateConfig(ctx, hooks, cl.reader, defaultConfig); err != nil {
 		return nil, err
 	}
 
@@ -323,11 +365,21 @@ func NewServer(
 	}
 
 	ctx = withLogLevel(ctx, defaultConfig)
+	if cl.isApplicationCommand() {
+		return nil, cl.runApplicationCommand(ctx, hooks)
+	}
+	if ctx, err = withAdminAuth(ctx, hooks, defaultConfig); err != nil {
+		return nil, err
+	}
+	ctx = withInventory(ctx)
+	ctx = withRequestRecorder(ctx, defaultConfig)
 
 	// Collect prometheus metrics if the admin server is enabled.
 	var promRegistry *prometheus.Registry
 	if admin != nil {
 		promRegistry = prometheus.NewRegistry()
+		registerBuildInfo(promRegistry, buildMetadata)
+		ctx = WithPrometheusRegistry(ctx, promRegistry)
 	}
 
 	manager, grpcManager, err := newManagers(ctx, serviceIntf, hooks)
@@ -349,61 +401,45 @@ func NewServer(
 }
 
 // LoadCustomConfig populates the given zero customConfig value with configuration data.
+//
+// The configuration is read from the config files given on the command line, each one taking
+// precedence over the ones before it, followed by the overlays of the profiles selected by the
+// --profile flag or the profile key and the overrides of the --set flags, see
+// config.ConfigReaderBuilder for the details. Commands other than serve, such as help or config
+// validate, are run and reported with ErrDisplayHelp.
 func LoadCustomConfig(ctx context.Context, customConfig interface{}) (interface{}, error) {
-	// Figure out where we can read application configuration data from.
-	var fs afero.Fs
-	var configPath string
-	if v := ctx.Value(serveYAMLConfigFileKey); v != nil {
-		applicationConfig := v.([]byte)
-		fs = afero.NewMemMapFs()
-		configPath = "config.yaml"
-		err := afero.Afero{Fs: fs}.WriteFile(configPath, applicationConfig, 0777)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		fs = afero.NewOsFs()
-		if len(os.Args) != 2 {
-			return nil, fmt.Errorf("wrong number of arguments (usage: %s (config | -h | --help | -v | --version))", os.Args[0])
-		}
-		switch os.Args[1] {
-		case "--help", "-h":
-			fmt.Printf("Usage: %s config\n\n", os.Args[0])
-			describeCustomConfig(os.Stdout, customConfig)
-			fmt.Print("\n\n")
-			return nil, ErrDisplayHelp(2)
-		case "--version", "-v":
-			fmt.Printf("%s\n", buildMetadata.String())
-			return nil, ErrDisplayHelp(2)
-		}
-		configPath = os.Args[1]
+	customConfig, cl, err := loadCustomConfig(ctx, customConfig)
+	if err == nil && cl.decodeErr != nil {
+		return nil, cl.decodeErr
 	}
+	return customConfig, err
+}
 
-	// Read application configuration data.
-	b := config.NewConfigReaderBuilder().WithFs(fs).WithConfigFile(configPath).WithDefaults(config.SetDefaults)
-
-	envPrefixConfigKey := "envPrefix"
-
-	// Enable strict mode to raise an error if there are config keys read from
-	// input that have no corresponding place in the customConfig structure
-	// that we're going to decode into -- with the exception of the special
-	// optional envPrefix key -- that doesn't end up getting decoded into the
-	// structure but, if it is present, we do read it below to customise how
-	// environment variables are loaded.
-	b = b.WithStrictMode(true, envPrefixConfigKey)
-
-	// Use the environment variable prefix from the config file if provided
-	env, err := b.Build().GetString(envPrefixConfigKey)
-	// Disable the feature if none is provided
-	if len(env) > 0 && err == nil {
-		b = b.AttachEnvPrefix(env)
+// loadCustomConfig is LoadCustomConfig, which also returns the command line. The commands of the
+// application are left to the caller, as well as the decode errors of the config, see
+// commandLine.configErrors.
+func loadCustomConfig(ctx context.Context, customConfig interface{}) (interface{}, *commandLine, error) {
+	cl, err := commandLineFromContext(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	if done, err := cl.runWithoutConfig(customConfig); done {
+		return nil, cl, err
 	}
 
-	err = b.Build().Unmarshal(customConfig)
+	// Read application configuration data.
+	cl.reader, err = cl.configReaderBuilder(false).Build()
 	if err != nil {
-		return nil, err
+		return nil, cl, err
 	}
-	return customConfig, err
+	decodeErr := cl.reader.Unmarshal(customConfig)
+
+	if done, err := cl.runWithConfig(customConfig, decodeErr); done {
+		return nil, cl, err
+	}
+	cl.config = customConfig
+	cl.decodeErr = decodeErr
+	return customConfig, cl, nil
 }
 
 // NewZeroCustomConfig uses reflection to create a new type derived from DefaultConfig,
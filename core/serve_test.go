@@ -72,6 +72,41 @@ func TestNewServerReturnsErrorIfValidateConfigReturnsError(t *testing.T) {
 	assert.EqualError(t, err, errString)
 }
 
+func TestNewServerReturnsAllConfigErrors(t *testing.T) {
+	ctx := WithConfigFile(context.Background(), []byte(`library:
+  log:
+    format: plain
+    caller: maybe
+genCode:
+  upstream:
+    http:
+      basePth: /
+      common:
+        port: 70000
+`))
+
+	srv, err := NewServer(
+		ctx,
+		&struct{}{},
+		func(ctx context.Context, config TestAppConfig) (*TestServiceInterface, *Hooks, error) {
+			panic("the service is not created for an invalid config")
+		},
+		&TestServiceInterface{},
+		func(ctx context.Context, serviceIntf interface{}, _ *Hooks) (Manager, *GrpcServerManager, error) {
+			panic("the server is not created for an invalid config")
+		},
+	)
+	assert.Nil(t, srv)
+
+	var configErrs config.Errors
+	assert.ErrorAs(t, err, &configErrs)
+	assert.EqualError(t, err, "invalid configuration, 4 errors:\n"+
+		"\tconfig.yaml:4:5: library.log.caller: cannot parse 'library.log.caller' as bool: strconv.ParseBool: parsing \"maybe\": invalid syntax\n"+
+		"\tconfig.yaml:8:7: genCode.upstream.http.basepth: unexpected config key\n"+
+		"\tconfig.yaml:3:5: library.log.format: failed on the 'oneof=color json text' validation\n"+
+		"\tconfig.yaml:10:9: genCode.upstream.http.common.port: failed on the 'max=65534' validation")
+}
+
 // Test a new server initialises a logger.
 func TestNewServerInitialisesLogger(t *testing.T) {
 	ctx, err := newServerContext(context.Background())
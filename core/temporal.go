@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/worker"
 	"go.temporal.io/sdk/workflow"
 )
@@ -60,19 +61,152 @@ func GetOptionFromClientIntf(options []client.StartWorkflowOptions) client.Start
 
 type Future[T any] struct {
 	workflow.Future
+
+	// onSuccess is called once the future completes successfully, see WithCompensation.
+	onSuccess func()
 }
 
 func (f *Future[T]) Get(ctx workflow.Context) (T, error) {
 	var t T
 	// FIXME: should this wait until it's ready?
 	err := f.Future.Get(ctx, &t)
+	if err == nil && f.onSuccess != nil {
+		f.onSuccess()
+		f.onSuccess = nil
+	}
 	return t, err
 }
 
 func ExecuteActivity[T any](ctx workflow.Context, tq, name string, args ...any) *Future[T] {
-	ctx = workflow.WithTaskQueue(ctx, tq)
-	if workflow.GetActivityOptions(ctx).StartToCloseTimeout == 0 {
-		ctx = workflow.WithStartToCloseTimeout(ctx, 5*time.Second)
+	return ExecuteActivityWithOptions[T](ctx, tq, name, ActivityOptions{}, args...)
+}
+
+// ActivityOptions are the options of an activity given in the spec. They apply where the
+// activity options of the workflow context leave an option unset.
+type ActivityOptions struct {
+	// TaskQueue replaces the task queue of the app that defines the activity.
+	TaskQueue              string
+	StartToCloseTimeout    time.Duration
+	ScheduleToCloseTimeout time.Duration
+	HeartbeatTimeout       time.Duration
+	RetryPolicy            *temporal.RetryPolicy
+}
+
+// ExecuteActivityWithOptions executes the activity of the given name with the options given in
+// the spec. Without a start-to-close or schedule-to-close timeout, a start-to-close timeout of
+// 5 seconds applies.
+func ExecuteActivityWithOptions[T any](ctx workflow.Context, tq, name string, options ActivityOptions, args ...any) *Future[T] {
+	ao := workflow.GetActivityOptions(ctx)
+	ao.TaskQueue = tq
+	if options.TaskQueue != "" {
+		ao.TaskQueue = options.TaskQueue
+	}
+	if ao.StartToCloseTimeout == 0 {
+		ao.StartToCloseTimeout = options.StartToCloseTimeout
+	}
+	if ao.ScheduleToCloseTimeout == 0 {
+		ao.ScheduleToCloseTimeout = options.ScheduleToCloseTimeout
+	}
+	if ao.HeartbeatTimeout == 0 {
+		ao.HeartbeatTimeout = options.HeartbeatTimeout
+	}
+	if ao.RetryPolicy == nil {
+		ao.RetryPolicy = options.RetryPolicy
+	}
+	if ao.StartToCloseTimeout == 0 && ao.ScheduleToCloseTimeout == 0 {
+		ao.StartToCloseTimeout = 5 * time.Second
+	}
+	return &Future[T]{Future: workflow.ExecuteActivity(workflow.WithActivityOptions(ctx, ao), name, args...)}
+}
+
+// WithWorkflowDefaults returns option with the unset fields taken from the workflow options
+// given in the spec.
+func WithWorkflowDefaults(option, defaults client.StartWorkflowOptions) client.StartWorkflowOptions {
+	if option.WorkflowExecutionTimeout == 0 {
+		option.WorkflowExecutionTimeout = defaults.WorkflowExecutionTimeout
+	}
+	if option.WorkflowRunTimeout == 0 {
+		option.WorkflowRunTimeout = defaults.WorkflowRunTimeout
+	}
+	if option.WorkflowTaskTimeout == 0 {
+		option.WorkflowTaskTimeout = defaults.WorkflowTaskTimeout
+	}
+	if option.RetryPolicy == nil {
+		option.RetryPolicy = defaults.RetryPolicy
 	}
-	return &Future[T]{workflow.ExecuteActivity(ctx, name, args...)}
+	return option
+}
+
+// SignalChannel is a typed channel that receives the signals of a given name within a workflow.
+type SignalChannel[T any] struct {
+	// Channel can be used with a workflow.Selector.
+	Channel workflow.ReceiveChannel
+}
+
+// GetSignalChannel returns the typed channel for the signals of the given name.
+func GetSignalChannel[T any](ctx workflow.Context, name string) *SignalChannel[T] {
+	return &SignalChannel[T]{workflow.GetSignalChannel(ctx, name)}
+}
+
+// Receive blocks until a signal is received. It returns false if the channel is closed.
+func (c *SignalChannel[T]) Receive(ctx workflow.Context) (T, bool) {
+	var t T
+	more := c.Channel.Receive(ctx, &t)
+	return t, more
+}
+
+// ReceiveAsync returns a signal if one is pending. It returns false if there is none.
+func (c *SignalChannel[T]) ReceiveAsync() (T, bool) {
+	var t T
+	ok := c.Channel.ReceiveAsync(&t)
+	return t, ok
+}
+
+// QueryWorkflow sends the query of the given name to a workflow and returns its result.
+func QueryWorkflow[T any](ctx context.Context, c client.Client, workflowID, runID, name string, args ...any) (T, error) {
+	var t T
+	v, err := c.QueryWorkflow(ctx, workflowID, runID, name, args...)
+	if err != nil {
+		return t, err
+	}
+	err = v.Get(&t)
+	return t, err
+}
+
+type UpdateHandle[T any] struct {
+	client.WorkflowUpdateHandle
+}
+
+func (h *UpdateHandle[T]) Get(ctx context.Context) (T, error) {
+	var t T
+	err := h.WorkflowUpdateHandle.Get(ctx, &t)
+	return t, err
+}
+
+// UpdateWorkflow sends the update of the given name to a workflow. It returns once the update
+// has been accepted; the result of the update is available from the returned handle.
+func UpdateWorkflow[T any](ctx context.Context, c client.Client, workflowID, runID, name string, args ...any) (*UpdateHandle[T], error) {
+	h, err := c.UpdateWorkflow(ctx, client.UpdateWorkflowOptions{
+		WorkflowID:   workflowID,
+		RunID:        runID,
+		UpdateName:   name,
+		Args:         args,
+		WaitForStage: client.WorkflowUpdateStageAccepted,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &UpdateHandle[T]{h}, nil
+}
+
+// SetUpdateHandler registers the handler of the update of the given name within a workflow.
+// At most one options value may be given, as with GetOptionFromClientIntf.
+func SetUpdateHandler(ctx workflow.Context, name string, handler any, options []workflow.UpdateHandlerOptions) error {
+	switch len(options) {
+	case 0:
+		return workflow.SetUpdateHandler(ctx, name, handler)
+	case 1:
+		return workflow.SetUpdateHandlerWithOptions(ctx, name, handler, options[0])
+	}
+	panic("more than one option is defined")
 }
@@ -1,17 +1,62 @@
 package core
 
-import "go.temporal.io/sdk/worker"
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/anz-bank/sysl-go/log"
+)
 
 type TemporalServer[Spec any] struct {
 	Spec TemporalServiceSpec[Spec]
+
+	ctx       context.Context
+	taskQueue string
+	admin     StoppableServer
 }
 
 func (t *TemporalServer[Spec]) Start() error {
 	t.Spec.Register()
+	if err := t.reconcileSchedules(); err != nil {
+		return err
+	}
+	if t.admin != nil {
+		go func() {
+			if err := t.admin.Start(); err != nil {
+				log.Error(t.ctx, err, "admin server stopped")
+			}
+		}()
+	}
 	return t.Spec.Run(worker.InterruptCh())
 }
 
+// reconcileSchedules makes the Temporal schedules of the task queue match the spec. Failures
+// are only logged when the spec has no schedules, so that a worker without schedules does not
+// depend on the schedule API.
+func (t *TemporalServer[Spec]) reconcileSchedules() error {
+	scheduler, ok := t.Spec.(TemporalScheduler)
+	if !ok || t.ctx == nil {
+		return nil
+	}
+	schedules := scheduler.GetSchedules()
+	err := ReconcileTemporalSchedules(t.ctx, t.Spec.GetClient(), t.taskQueue, schedules)
+	if err != nil && len(schedules) == 0 {
+		log.Error(t.ctx, err, "failed to remove schedules")
+		return nil
+	}
+	return err
+}
+
 func (t *TemporalServer[Spec]) Stop() error {
+	if t.admin != nil {
+		_ = t.admin.Stop()
+	}
 	// stops worker.
 	t.Spec.Stop()
 	// closes client.
@@ -20,9 +65,40 @@ func (t *TemporalServer[Spec]) Stop() error {
 }
 
 func (t *TemporalServer[Spec]) GracefulStop() error {
+	if t.admin != nil {
+		_ = t.admin.GracefulStop()
+	}
 	return t.Stop()
 }
 
 func (t *TemporalServer[Spec]) GetName() string { return "autogenerated-temporal-server" }
 
 func (t *TemporalServer[Spec]) GetSpec() TemporalServiceSpec[Spec] { return t.Spec }
+
+// configureTemporalAdminServer returns the admin server of a temporal worker. It serves the
+// metrics of the worker and lists its schedules at /-/temporal/schedules.
+func configureTemporalAdminServer(
+	ctx context.Context,
+	cfg *config.DefaultConfig,
+	hooks *Hooks,
+	promRegistry *prometheus.Registry,
+	c client.Client,
+	taskQueue string,
+) (StoppableServer, error) {
+	codecRoutes, err := temporalCodecRoutes(cfg.GenCode.Upstream.Temporal.Codec)
+	if err != nil {
+		return nil, err
+	}
+	addRoutes := func(ctx context.Context, r chi.Router) {
+		r.With(authorizeAdmin(ctx, config.AdminRouteTemporal, false)).Get("/-/temporal/schedules", NewTemporalSchedulesHandler(c, taskQueue).(http.HandlerFunc))
+		if codecRoutes != nil {
+			codecRoutes(ctx, r)
+		}
+		if hooks.AddAdminHTTPMiddleware != nil {
+			hooks.AddAdminHTTPMiddleware(ctx, r)
+		}
+	}
+	manager := NewHTTPManagerShim(&cfg.Library, &cfg.Admin.HTTP, nil, nil, addRoutes)
+	mWare := prepareMiddleware("autogenerated-temporal-server", promRegistry, cfg.Admin.ContextTimeout)
+	return configureAdminServerListener(ctx, manager, promRegistry, nil, mWare.admin)
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+func TestExecuteActivityWithOptions(t *testing.T) {
+	t.Parallel()
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+
+	env.RegisterActivityWithOptions(func(ctx context.Context) (activity.Info, error) {
+		return activity.GetInfo(ctx), nil
+	}, activity.RegisterOptions{Name: "Info"})
+	env.RegisterWorkflowWithOptions(func(ctx workflow.Context) ([]activity.Info, error) {
+		var infos []activity.Info
+		specified, err := ExecuteActivityWithOptions[activity.Info](ctx, "app", "Info", ActivityOptions{
+			StartToCloseTimeout: 10 * time.Second,
+			HeartbeatTimeout:    2 * time.Second,
+			RetryPolicy:         &temporal.RetryPolicy{MaximumAttempts: 5},
+		}).Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, specified)
+
+		// options of the context take precedence
+		ctx = workflow.WithStartToCloseTimeout(ctx, time.Minute)
+		overridden, err := ExecuteActivityWithOptions[activity.Info](ctx, "app", "Info", ActivityOptions{
+			StartToCloseTimeout: 10 * time.Second,
+		}).Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return append(infos, overridden), nil
+	}, workflow.RegisterOptions{Name: "Workflow"})
+
+	env.ExecuteWorkflow("Workflow")
+	require.NoError(t, env.GetWorkflowError())
+	var infos []activity.Info
+	require.NoError(t, env.GetWorkflowResult(&infos))
+	require.Len(t, infos, 2)
+	require.Equal(t, 10*time.Second, infos[0].StartToCloseTimeout)
+	require.Equal(t, 2*time.Second, infos[0].HeartbeatTimeout)
+	require.Equal(t, time.Minute, infos[1].StartToCloseTimeout)
+}
+
+func TestWithWorkflowDefaults(t *testing.T) {
+	t.Parallel()
+	policy := &temporal.RetryPolicy{MaximumAttempts: 3}
+	option := WithWorkflowDefaults(
+		client.StartWorkflowOptions{ID: "id", WorkflowRunTimeout: time.Minute},
+		client.StartWorkflowOptions{
+			WorkflowExecutionTimeout: time.Hour,
+			WorkflowRunTimeout:       time.Second,
+			RetryPolicy:              policy,
+		},
+	)
+	require.Equal(t, "id", option.ID)
+	require.Equal(t, time.Hour, option.WorkflowExecutionTimeout)
+	require.Equal(t, time.Minute, option.WorkflowRunTimeout)
+	require.Equal(t, policy, option.RetryPolicy)
+}
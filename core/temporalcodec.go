@@ -0,0 +1,224 @@
+package core
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/anz-bank/sysl-go/config"
+)
+
+const (
+	// temporalCodecPath is the admin path of the codec server.
+	temporalCodecPath = "/-/temporal/codec"
+	// encryptedEncoding is the metadata encoding of payloads encrypted by the AES-GCM codec.
+	encryptedEncoding = "binary/encrypted"
+	// encryptionKeyIDMetadata is the metadata key that holds the ID of the encryption key.
+	encryptionKeyIDMetadata = "encryption-key-id"
+)
+
+// NewTemporalPayloadCodecs returns the payload codecs configured by cfg, outermost first.
+// It returns nil if cfg configures no codec.
+func NewTemporalPayloadCodecs(cfg *config.TemporalCodecConfig) ([]converter.PayloadCodec, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	var codecs []converter.PayloadCodec
+	if cfg.Encryption != nil {
+		codec, err := NewAESGCMPayloadCodec(cfg.Encryption)
+		if err != nil {
+			return nil, err
+		}
+		codecs = append(codecs, codec)
+	}
+	if cfg.Compress {
+		// Compression comes after encryption in the list so that it is applied first on encode.
+		codecs = append(codecs, converter.NewZlibCodec(converter.ZlibCodecOptions{}))
+	}
+	return codecs, nil
+}
+
+// NewTemporalCodecHandler returns an http.Handler that implements the remote codec API used by
+// the Temporal UI and CLI to decode the payloads encoded as configured by cfg. Workers serve it on
+// their admin server when cfg.Server is set.
+func NewTemporalCodecHandler(cfg *config.TemporalCodecConfig) (http.Handler, error) {
+	codecs, err := NewTemporalPayloadCodecs(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return converter.NewPayloadCodecHTTPHandler(codecs...), nil
+}
+
+// temporalCodecRoutes returns a function that adds the codec server configured by cfg to the
+// admin router, or nil if it is not served.
+func temporalCodecRoutes(cfg *config.TemporalCodecConfig) (func(ctx context.Context, r chi.Router), error) {
+	if cfg == nil || cfg.Server == nil {
+		return nil, nil
+	}
+	handler, err := NewTemporalCodecHandler(cfg)
+	if err != nil {
+		return nil, err
+	}
+	origin := cfg.Server.UIOrigin
+	return func(ctx context.Context, r chi.Router) {
+		// The preflight requests of the UI carry no credentials, so they are answered before the
+		// requests are authorized.
+		r.With(temporalCodecCORS(origin), authorizeAdmin(ctx, config.AdminRouteTemporal, true)).
+			Handle(temporalCodecPath+"/*", handler)
+	}, nil
+}
+
+// temporalCodecCORS allows the Temporal UI served from origin to call the codec server from the
+// browser, and answers its preflight requests.
+func temporalCodecCORS(origin string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Origin")
+			if origin == "" || r.Header.Get("Origin") != origin {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+			if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Access-Control-Allow-Methods", http.MethodPost)
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Namespace")
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// setTemporalDataConverter sets the data and failure converters of options as configured by cfg.
+func setTemporalDataConverter(options *client.Options, cfg *config.TemporalCodecConfig) error {
+	if cfg == nil {
+		return nil
+	}
+	dataConverter := converter.GetDefaultDataConverter()
+	if cfg.ProtoJSON != nil {
+		dataConverter = converter.NewCompositeDataConverter(
+			converter.NewNilPayloadConverter(),
+			converter.NewByteSlicePayloadConverter(),
+			converter.NewProtoJSONPayloadConverterWithOptions(converter.ProtoJSONPayloadConverterOptions{
+				ExcludeProtobufMessageTypes: cfg.ProtoJSON.ExcludeProtobufMessageTypes,
+				AllowUnknownFields:          cfg.ProtoJSON.AllowUnknownFields,
+				UseProtoNames:               cfg.ProtoJSON.UseProtoNames,
+				UseEnumNumbers:              cfg.ProtoJSON.UseEnumNumbers,
+				EmitUnpopulated:             cfg.ProtoJSON.EmitUnpopulated,
+			}),
+			converter.NewProtoPayloadConverter(),
+			converter.NewJSONPayloadConverter(),
+		)
+	}
+	codecs, err := NewTemporalPayloadCodecs(cfg)
+	if err != nil {
+		return err
+	}
+	if len(codecs) > 0 {
+		dataConverter = converter.NewCodecDataConverter(dataConverter, codecs...)
+	}
+	options.DataConverter = dataConverter
+	if cfg.EncodeFailureAttributes {
+		options.FailureConverter = temporal.NewDefaultFailureConverter(temporal.DefaultFailureConverterOptions{
+			DataConverter:          dataConverter,
+			EncodeCommonAttributes: true,
+		})
+	}
+	return nil
+}
+
+// AESGCMPayloadCodec encrypts payloads with AES-GCM. Each encrypted payload records the ID of
+// its key so that payloads encrypted with older keys can still be decrypted after rotation.
+type AESGCMPayloadCodec struct {
+	keyID string
+	aeads map[string]cipher.AEAD
+}
+
+// NewAESGCMPayloadCodec returns a codec that encrypts with the keys configured by cfg.
+func NewAESGCMPayloadCodec(cfg *config.TemporalEncryptionConfig) (*AESGCMPayloadCodec, error) {
+	c := &AESGCMPayloadCodec{keyID: cfg.KeyID, aeads: make(map[string]cipher.AEAD, len(cfg.Keys))}
+	for id, keyConfig := range cfg.Keys {
+		key, err := config.MakeSecretKey(keyConfig)
+		if err != nil {
+			return nil, fmt.Errorf("invalid temporal encryption key %s: %w", id, err)
+		}
+		block, err := aes.NewCipher([]byte(key.Value()))
+		if err != nil {
+			return nil, fmt.Errorf("invalid temporal encryption key %s: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		c.aeads[id] = aead
+	}
+	if _, ok := c.aeads[cfg.KeyID]; !ok {
+		return nil, fmt.Errorf("temporal encryption key %s is not configured", cfg.KeyID)
+	}
+	return c, nil
+}
+
+// Encode implements converter.PayloadCodec.
+func (c *AESGCMPayloadCodec) Encode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	aead := c.aeads[c.keyID]
+	result := make([]*commonpb.Payload, len(payloads))
+	for i, p := range payloads {
+		plaintext, err := proto.Marshal(p)
+		if err != nil {
+			return nil, err
+		}
+		nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+		if _, err = rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		result[i] = &commonpb.Payload{
+			Metadata: map[string][]byte{
+				converter.MetadataEncoding: []byte(encryptedEncoding),
+				encryptionKeyIDMetadata:    []byte(c.keyID),
+			},
+			Data: aead.Seal(nonce, nonce, plaintext, nil),
+		}
+	}
+	return result, nil
+}
+
+// Decode implements converter.PayloadCodec. Payloads that are not encrypted are returned as they are.
+func (c *AESGCMPayloadCodec) Decode(payloads []*commonpb.Payload) ([]*commonpb.Payload, error) {
+	result := make([]*commonpb.Payload, len(payloads))
+	for i, p := range payloads {
+		if string(p.GetMetadata()[converter.MetadataEncoding]) != encryptedEncoding {
+			result[i] = p
+			continue
+		}
+		keyID := string(p.GetMetadata()[encryptionKeyIDMetadata])
+		aead, ok := c.aeads[keyID]
+		if !ok {
+			return nil, fmt.Errorf("payload is encrypted with unknown key %s", keyID)
+		}
+		data := p.GetData()
+		if len(data) < aead.NonceSize() {
+			return nil, fmt.Errorf("encrypted payload is too short")
+		}
+		plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt payload: %w", err)
+		}
+		result[i] = &commonpb.Payload{}
+		if err = proto.Unmarshal(plaintext, result[i]); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,184 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/anz-bank/sysl-go/log"
+)
+
+func testSecretKey(b byte) *config.SecretKeyConfig {
+	encoding := config.SecretKeyEncodingBase64
+	value := config.NewSensitiveString(base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), 32))))
+	return &config.SecretKeyConfig{Encoding: &encoding, Value: &value}
+}
+
+func TestAESGCMPayloadCodec_RoundTrip(t *testing.T) {
+	t.Parallel()
+	codec, err := NewAESGCMPayloadCodec(&config.TemporalEncryptionConfig{
+		KeyID: "k1",
+		Keys:  map[string]*config.SecretKeyConfig{"k1": testSecretKey('a')},
+	})
+	require.NoError(t, err)
+
+	payload, err := converter.GetDefaultDataConverter().ToPayload("sensitive")
+	require.NoError(t, err)
+	encoded, err := codec.Encode([]*commonpb.Payload{payload})
+	require.NoError(t, err)
+	require.Equal(t, "binary/encrypted", string(encoded[0].Metadata[converter.MetadataEncoding]))
+	require.Equal(t, "k1", string(encoded[0].Metadata["encryption-key-id"]))
+	require.NotContains(t, string(encoded[0].Data), "sensitive")
+
+	decoded, err := codec.Decode(encoded)
+	require.NoError(t, err)
+	var s string
+	require.NoError(t, converter.GetDefaultDataConverter().FromPayload(decoded[0], &s))
+	require.Equal(t, "sensitive", s)
+}
+
+func TestAESGCMPayloadCodec_Rotation(t *testing.T) {
+	t.Parallel()
+	old, err := NewAESGCMPayloadCodec(&config.TemporalEncryptionConfig{
+		KeyID: "k1",
+		Keys:  map[string]*config.SecretKeyConfig{"k1": testSecretKey('a')},
+	})
+	require.NoError(t, err)
+	rotated, err := NewAESGCMPayloadCodec(&config.TemporalEncryptionConfig{
+		KeyID: "k2",
+		Keys:  map[string]*config.SecretKeyConfig{"k1": testSecretKey('a'), "k2": testSecretKey('b')},
+	})
+	require.NoError(t, err)
+
+	payload, err := converter.GetDefaultDataConverter().ToPayload("data")
+	require.NoError(t, err)
+	encoded, err := old.Encode([]*commonpb.Payload{payload})
+	require.NoError(t, err)
+	_, err = rotated.Decode(encoded)
+	require.NoError(t, err)
+
+	encoded, err = rotated.Encode([]*commonpb.Payload{payload})
+	require.NoError(t, err)
+	require.Equal(t, "k2", string(encoded[0].Metadata["encryption-key-id"]))
+	_, err = old.Decode(encoded)
+	require.ErrorContains(t, err, "unknown key k2")
+}
+
+func TestNewAESGCMPayloadCodec_Errors(t *testing.T) {
+	t.Parallel()
+	_, err := NewAESGCMPayloadCodec(&config.TemporalEncryptionConfig{
+		KeyID: "missing",
+		Keys:  map[string]*config.SecretKeyConfig{"k1": testSecretKey('a')},
+	})
+	require.Error(t, err)
+
+	encoding := config.SecretKeyEncodingBase64
+	short := config.NewSensitiveString(base64.StdEncoding.EncodeToString([]byte("short")))
+	_, err = NewAESGCMPayloadCodec(&config.TemporalEncryptionConfig{
+		KeyID: "k1",
+		Keys:  map[string]*config.SecretKeyConfig{"k1": {Encoding: &encoding, Value: &short}},
+	})
+	require.Error(t, err)
+}
+
+func Test_setTemporalDataConverter(t *testing.T) {
+	t.Parallel()
+	var options client.Options
+	require.NoError(t, setTemporalDataConverter(&options, &config.TemporalCodecConfig{
+		Encryption: &config.TemporalEncryptionConfig{
+			KeyID: "k1",
+			Keys:  map[string]*config.SecretKeyConfig{"k1": testSecretKey('a')},
+		},
+		Compress:                true,
+		ProtoJSON:               &config.TemporalProtoJSONConfig{AllowUnknownFields: true},
+		EncodeFailureAttributes: true,
+	}))
+	require.NotNil(t, options.DataConverter)
+	require.NotNil(t, options.FailureConverter)
+
+	payload, err := options.DataConverter.ToPayload(strings.Repeat("x", 1000))
+	require.NoError(t, err)
+	require.Equal(t, "binary/encrypted", string(payload.Metadata[converter.MetadataEncoding]))
+	var s string
+	require.NoError(t, options.DataConverter.FromPayload(payload, &s))
+	require.Len(t, s, 1000)
+}
+
+func TestNewTemporalCodecHandler(t *testing.T) {
+	t.Parallel()
+	handler, err := NewTemporalCodecHandler(&config.TemporalCodecConfig{Compress: true})
+	require.NoError(t, err)
+	require.NotNil(t, handler)
+}
+
+func TestTemporalCodecRoutes(t *testing.T) {
+	t.Parallel()
+	routes, err := temporalCodecRoutes(&config.TemporalCodecConfig{Compress: true})
+	require.NoError(t, err)
+	require.Nil(t, routes)
+
+	routes, err = temporalCodecRoutes(&config.TemporalCodecConfig{
+		Encryption: &config.TemporalEncryptionConfig{
+			KeyID: "k1",
+			Keys:  map[string]*config.SecretKeyConfig{"k1": testSecretKey('a')},
+		},
+		Server: &config.TemporalCodecServerConfig{UIOrigin: "https://ui.example.com"},
+	})
+	require.NoError(t, err)
+	ctx, err := withAdminAuth(context.Background(), &Hooks{AuthorizeAdminRequest: func(r *http.Request) error {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			return errors.New("no token")
+		}
+		return nil
+	}}, nil)
+	require.NoError(t, err)
+	r := chi.NewRouter()
+	routes(log.PutLogger(ctx, log.NewDefaultLogger()), r)
+	serve := func(method, origin, authorization string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/-/temporal/codec/encode", strings.NewReader(`{"payloads": []}`))
+		req = req.WithContext(log.PutLogger(req.Context(), log.NewDefaultLogger()))
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Authorization", authorization)
+		req.Header.Set("Content-Type", "application/json")
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// the preflight requests of the UI are answered without credentials
+	w := serve(http.MethodOptions, "https://ui.example.com", "")
+	require.Equal(t, http.StatusNoContent, w.Code)
+	require.Equal(t, "https://ui.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	require.Equal(t, http.MethodPost, w.Header().Get("Access-Control-Allow-Methods"))
+	require.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "X-Namespace")
+
+	w = serve(http.MethodPost, "https://ui.example.com", "")
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	w = serve(http.MethodPost, "https://ui.example.com", "Bearer token")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "https://ui.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+
+	// other origins are not allowed
+	w = serve(http.MethodOptions, "https://other.example.com", "")
+	require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	require.NotEqual(t, http.StatusNoContent, w.Code)
+	w = serve(http.MethodPost, "https://other.example.com", "Bearer token")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
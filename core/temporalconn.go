@@ -0,0 +1,97 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/anz-bank/sysl-go/log"
+)
+
+// temporalClientOptions returns the client options that connect to hostPort as configured by cfg.
+func temporalClientOptions(
+	ctx context.Context,
+	hostPort, namespace, identity string,
+	cfg *config.TemporalConnectionConfig,
+) (client.Options, error) {
+	options := client.Options{
+		HostPort:  hostPort,
+		Namespace: namespace,
+		Identity:  identity,
+		ConnectionOptions: client.ConnectionOptions{
+			Authority:      cfg.Authority,
+			MaxPayloadSize: cfg.MaxPayloadSize,
+		},
+	}
+
+	if cfg.TLS != nil {
+		tlsConfig, err := config.MakeTLSConfig(ctx, cfg.TLS)
+		if err != nil {
+			return client.Options{}, fmt.Errorf("invalid temporal tls config: %w", err)
+		}
+		options.ConnectionOptions.TLS = tlsConfig
+	}
+
+	if cfg.APIKey != nil {
+		options.Credentials = client.NewAPIKeyStaticCredentials(cfg.APIKey.Value())
+	}
+
+	if len(cfg.Headers) > 0 {
+		headers := make(temporalHeaders, len(cfg.Headers))
+		for name, value := range cfg.Headers {
+			if value != nil {
+				headers[name] = value.Value()
+			}
+		}
+		options.HeadersProvider = headers
+	}
+
+	if ka := cfg.Keepalive; ka != nil {
+		options.ConnectionOptions.DisableKeepAliveCheck = ka.Disable
+		options.ConnectionOptions.KeepAliveTime = ka.Time
+		options.ConnectionOptions.KeepAliveTimeout = ka.Timeout
+		options.ConnectionOptions.DisableKeepAlivePermitWithoutStream = ka.DisablePermitWithoutStream
+	}
+
+	if logger := log.GetLogger(ctx); logger != nil {
+		options.Logger = NewTemporalLogger(logger)
+	}
+	if registry := getPrometheusRegistry(ctx); registry != nil {
+		options.MetricsHandler = NewTemporalMetricsHandler(registry)
+	}
+	options.ContextPropagators = []workflow.ContextPropagator{NewTemporalContextPropagator(cfg.PropagateHeaders)}
+
+	if err := setTemporalDataConverter(&options, cfg.Codec); err != nil {
+		return client.Options{}, err
+	}
+
+	return options, nil
+}
+
+// temporalWorkerOptions returns the worker options configured by cfg.
+func temporalWorkerOptions(ctx context.Context, cfg *config.TemporalWorkerConfig) worker.Options {
+	return worker.Options{
+		BackgroundActivityContext:               ctx,
+		MaxConcurrentActivityExecutionSize:      cfg.MaxConcurrentActivityExecutionSize,
+		MaxConcurrentLocalActivityExecutionSize: cfg.MaxConcurrentLocalActivityExecutionSize,
+		MaxConcurrentWorkflowTaskExecutionSize:  cfg.MaxConcurrentWorkflowTaskExecutionSize,
+		MaxConcurrentActivityTaskPollers:        cfg.MaxConcurrentActivityTaskPollers,
+		MaxConcurrentWorkflowTaskPollers:        cfg.MaxConcurrentWorkflowTaskPollers,
+		WorkerActivitiesPerSecond:               cfg.WorkerActivitiesPerSecond,
+		TaskQueueActivitiesPerSecond:            cfg.TaskQueueActivitiesPerSecond,
+		StickyScheduleToStartTimeout:            cfg.StickyScheduleToStartTimeout,
+		WorkerStopTimeout:                       cfg.WorkerStopTimeout,
+		EnableSessionWorker:                     cfg.EnableSessionWorker,
+	}
+}
+
+// temporalHeaders sends a fixed set of headers with every request to the Temporal frontend.
+type temporalHeaders map[string]string
+
+func (h temporalHeaders) GetHeaders(context.Context) (map[string]string, error) {
+	return h, nil
+}
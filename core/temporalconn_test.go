@@ -0,0 +1,75 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/anz-bank/sysl-go/config"
+	"github.com/anz-bank/sysl-go/testutil"
+)
+
+func Test_temporalClientOptions(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.NewTestContext()
+	key := config.NewSensitiveString("secret-key")
+	tenant := config.NewSensitiveString("tenant-a")
+
+	options, err := temporalClientOptions(ctx, "localhost:7233", "default", "me", &config.TemporalConnectionConfig{
+		TLS:     &config.TLSConfig{InsecureSkipVerify: true},
+		APIKey:  &key,
+		Headers: map[string]*config.SensitiveString{"x-tenant": &tenant},
+		Keepalive: &config.TemporalKeepaliveConfig{
+			Time:    20 * time.Second,
+			Timeout: 5 * time.Second,
+		},
+		MaxPayloadSize: 1024,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "localhost:7233", options.HostPort)
+	require.Equal(t, "default", options.Namespace)
+	require.Equal(t, "me", options.Identity)
+	require.NotNil(t, options.ConnectionOptions.TLS)
+	require.True(t, options.ConnectionOptions.TLS.InsecureSkipVerify)
+	require.NotNil(t, options.Credentials)
+	require.Equal(t, 20*time.Second, options.ConnectionOptions.KeepAliveTime)
+	require.Equal(t, 5*time.Second, options.ConnectionOptions.KeepAliveTimeout)
+	require.Equal(t, 1024, options.ConnectionOptions.MaxPayloadSize)
+
+	headers, err := options.HeadersProvider.GetHeaders(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"x-tenant": "tenant-a"}, headers)
+}
+
+func Test_temporalClientOptions_Defaults(t *testing.T) {
+	t.Parallel()
+	options, err := temporalClientOptions(testutil.NewTestContext(), "localhost:7233", "default", "", &config.TemporalConnectionConfig{})
+	require.NoError(t, err)
+	require.Nil(t, options.ConnectionOptions.TLS)
+	require.Nil(t, options.Credentials)
+	require.Nil(t, options.HeadersProvider)
+}
+
+func Test_temporalClientOptions_InvalidTLS(t *testing.T) {
+	t.Parallel()
+	invalid := "1.9"
+	_, err := temporalClientOptions(testutil.NewTestContext(), "localhost:7233", "default", "", &config.TemporalConnectionConfig{
+		TLS: &config.TLSConfig{MinVersion: &invalid},
+	})
+	require.Error(t, err)
+}
+
+func Test_temporalClientOptions_Observability(t *testing.T) {
+	t.Parallel()
+	ctx := WithPrometheusRegistry(testutil.NewTestContext(), prometheus.NewRegistry())
+	options, err := temporalClientOptions(ctx, "localhost:7233", "default", "", &config.TemporalConnectionConfig{
+		PropagateHeaders: []string{"x-tenant"},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, options.Logger)
+	require.NotNil(t, options.MetricsHandler)
+	require.Len(t, options.ContextPropagators, 1)
+}
@@ -0,0 +1,294 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+
+	"github.com/anz-bank/sysl-go/common"
+	"github.com/anz-bank/sysl-go/core/authrules"
+	"github.com/anz-bank/sysl-go/restlib"
+	"github.com/anz-bank/sysl-go/validator"
+)
+
+// TemporalWorkflowParam is a workflow parameter decoded from the body of a start request.
+type TemporalWorkflowParam struct {
+	Name string
+	// Value is a pointer to the value that the parameter is decoded into.
+	Value any
+}
+
+// TemporalWorkflowRoute describes a workflow exposed over HTTP by a TemporalWorkflowRouter.
+type TemporalWorkflowRoute struct {
+	// Workflow is the name of the workflow, which is also used as a segment of the route paths.
+	Workflow  string
+	TaskQueue string
+	// Options are the defaults used to start the workflow. An ID is generated when none is given.
+	Options client.StartWorkflowOptions
+	// Params returns the parameters of the workflow to decode a start request into. A workflow
+	// with a single parameter takes it as the body, otherwise the body is an object keyed by name.
+	Params func() []TemporalWorkflowParam
+	// Result returns a pointer to decode the result of the workflow into, nil means no result.
+	Result func() any
+	// AuthorizationRule is applied to all the routes of the workflow when it is set.
+	AuthorizationRule authrules.Rule
+}
+
+// TemporalWorkflowStarted is the response of a start request.
+type TemporalWorkflowStarted struct {
+	WorkflowID string `json:"workflowId"`
+	RunID      string `json:"runId"`
+}
+
+// TemporalWorkflowStatus is the response of a status request.
+type TemporalWorkflowStatus struct {
+	WorkflowID string `json:"workflowId"`
+	RunID      string `json:"runId"`
+	Status     string `json:"status"`
+	Result     any    `json:"result,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// TemporalWorkflowRouter exposes workflows as long-running operations:
+//
+//	POST /workflows/{workflow}                         starts the workflow and responds 202 with its IDs
+//	GET  /workflows/{workflow}/{workflowID}            responds with the status, and the result once closed
+//	POST /workflows/{workflow}/{workflowID}/cancel     requests the cancellation of the workflow
+//	POST /workflows/{workflow}/{workflowID}/terminate  terminates the workflow
+//
+// The runId query parameter selects a run other than the latest one. The wait query parameter
+// makes a status request wait for the workflow to close, within the downstream timeout.
+type TemporalWorkflowRouter struct {
+	gc     RestGenCallback
+	client client.Client
+	name   string
+	routes []TemporalWorkflowRoute
+}
+
+// NewTemporalWorkflowRouter creates a HandlerInitialiser for the given workflow routes.
+func NewTemporalWorkflowRouter(gc RestGenCallback, c client.Client, name string, routes ...TemporalWorkflowRoute) *TemporalWorkflowRouter {
+	return &TemporalWorkflowRouter{gc: gc, client: c, name: name, routes: routes}
+}
+
+// WireRoutes ...
+func (s *TemporalWorkflowRouter) WireRoutes(ctx context.Context, r chi.Router) {
+	prefix := strings.TrimSuffix(SelectBasePath("", s.gc.BasePath()), "/")
+	r.Group(func(r chi.Router) {
+		s.gc.AddMiddleware(ctx, r)
+		for _, route := range s.routes {
+			path := prefix + "/workflows/" + route.Workflow
+			r.Post(path, s.authorize(route, s.startHandler(route)))
+			r.Get(path+"/{workflowID}", s.authorize(route, s.statusHandler(route)))
+			r.Post(path+"/{workflowID}/cancel", s.authorize(route, s.cancelHandler))
+			r.Post(path+"/{workflowID}/terminate", s.authorize(route, s.terminateHandler))
+		}
+	})
+}
+
+// Config ...
+func (s *TemporalWorkflowRouter) Config() interface{} {
+	return s.gc.Config()
+}
+
+// Name ...
+func (s *TemporalWorkflowRouter) Name() string {
+	return s.name
+}
+
+func (s *TemporalWorkflowRouter) authorize(route TemporalWorkflowRoute, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := common.RequestHeaderToContext(r.Context(), r.Header)
+		if route.AuthorizationRule != nil {
+			var err error
+			ctx, err = route.AuthorizationRule(ctx)
+			if err != nil {
+				s.handleError(ctx, w, common.UnauthorizedError, "Auth error", err)
+				return
+			}
+		}
+		next(w, r.WithContext(ctx))
+	}
+}
+
+func (s *TemporalWorkflowRouter) startHandler(route TemporalWorkflowRoute) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			s.handleError(ctx, w, common.BadRequestError, "Error reading request body", err)
+			return
+		}
+
+		var args []any
+		if route.Params != nil {
+			args, err = decodeTemporalWorkflowParams(body, route.Params())
+			if err != nil {
+				s.handleError(ctx, w, common.BadRequestError, "Invalid request", err)
+				return
+			}
+		}
+
+		options := route.Options
+		if options.ID == "" {
+			options.ID = uuid.NewString()
+		}
+		if route.TaskQueue != "" {
+			options.TaskQueue = route.TaskQueue
+		}
+
+		ctx, cancel := s.gc.DownstreamTimeoutContext(ctx)
+		defer cancel()
+		run, err := s.client.ExecuteWorkflow(ctx, options, route.Workflow, args...)
+		if err != nil {
+			s.handleError(ctx, w, common.DownstreamUnavailableError, "Failed to start workflow", err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		restlib.SendHTTPResponse(w, http.StatusAccepted, &TemporalWorkflowStarted{
+			WorkflowID: run.GetID(),
+			RunID:      run.GetRunID(),
+		})
+	}
+}
+
+func (s *TemporalWorkflowRouter) statusHandler(route TemporalWorkflowRoute) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		workflowID, runID := chi.URLParam(r, "workflowID"), r.URL.Query().Get("runId")
+		ctx, cancel := s.gc.DownstreamTimeoutContext(r.Context())
+		defer cancel()
+
+		if r.URL.Query().Get("wait") == "true" {
+			// the workflow error, if any, is reported as part of the status below
+			_ = s.client.GetWorkflow(ctx, workflowID, runID).Get(ctx, nil)
+			if ctx.Err() != nil {
+				s.handleError(ctx, w, common.DownstreamTimeoutError, "Workflow did not close in time", ctx.Err())
+				return
+			}
+		}
+
+		desc, err := s.client.DescribeWorkflowExecution(ctx, workflowID, runID)
+		if err != nil {
+			s.handleError(ctx, w, common.DownstreamUnavailableError, "Failed to describe workflow", err)
+			return
+		}
+
+		info := desc.GetWorkflowExecutionInfo()
+		status := &TemporalWorkflowStatus{
+			WorkflowID: workflowID,
+			RunID:      info.GetExecution().GetRunId(),
+			Status:     info.GetStatus().String(),
+		}
+		if info.GetStatus() != enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING {
+			var result any
+			if route.Result != nil {
+				result = route.Result()
+			}
+			if err := s.client.GetWorkflow(ctx, workflowID, status.RunID).Get(ctx, result); err != nil {
+				status.Error = err.Error()
+			} else {
+				status.Result = result
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		restlib.SendHTTPResponse(w, http.StatusOK, status)
+	}
+}
+
+func (s *TemporalWorkflowRouter) cancelHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := s.gc.DownstreamTimeoutContext(r.Context())
+	defer cancel()
+
+	if err := s.client.CancelWorkflow(ctx, chi.URLParam(r, "workflowID"), r.URL.Query().Get("runId")); err != nil {
+		s.handleError(ctx, w, common.DownstreamUnavailableError, "Failed to cancel workflow", err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *TemporalWorkflowRouter) terminateHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := s.gc.DownstreamTimeoutContext(r.Context())
+	defer cancel()
+
+	reason := r.URL.Query().Get("reason")
+	if reason == "" {
+		reason = "terminated over HTTP"
+	}
+	if err := s.client.TerminateWorkflow(ctx, chi.URLParam(r, "workflowID"), r.URL.Query().Get("runId"), reason); err != nil {
+		s.handleError(ctx, w, common.DownstreamUnavailableError, "Failed to terminate workflow", err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// handleError responds with 404 when the workflow execution does not exist (or is already
+// closed for cancellations and terminations) and maps other errors as usual.
+func (s *TemporalWorkflowRouter) handleError(ctx context.Context, w http.ResponseWriter, kind common.Kind, message string, err error) {
+	mapError := func(ctx context.Context, err error) *common.HTTPError {
+		if httpError := s.gc.MapError(ctx, err); httpError != nil {
+			return httpError
+		}
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			return &common.HTTPError{
+				HTTPCode:    http.StatusNotFound,
+				Code:        "1004",
+				Description: "Workflow execution not found",
+			}
+		}
+		return nil
+	}
+	common.HandleError(ctx, w, kind, message, err, mapError, s.gc.WriteError)
+}
+
+// decodeTemporalWorkflowParams decodes and validates the body of a start request into the
+// given parameters and returns the arguments of the workflow.
+func decodeTemporalWorkflowParams(body []byte, params []TemporalWorkflowParam) ([]any, error) {
+	switch len(params) {
+	case 0:
+		return nil, nil
+	case 1:
+		if err := json.Unmarshal(body, params[0].Value); err != nil {
+			return nil, err
+		}
+	default:
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(body, &fields); err != nil {
+			return nil, err
+		}
+		for _, p := range params {
+			field, has := fields[p.Name]
+			if !has {
+				return nil, fmt.Errorf("missing workflow parameter %q", p.Name)
+			}
+			if err := json.Unmarshal(field, p.Value); err != nil {
+				return nil, fmt.Errorf("invalid workflow parameter %q: %w", p.Name, err)
+			}
+		}
+	}
+
+	args := make([]any, 0, len(params))
+	for _, p := range params {
+		arg := reflect.ValueOf(p.Value).Elem().Interface()
+		if reflect.Indirect(reflect.ValueOf(arg)).Kind() == reflect.Struct {
+			if err := validator.Validate(arg); err != nil {
+				return nil, err
+			}
+		}
+		args = append(args, arg)
+	}
+
+	return args, nil
+}
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/mocks"
+
+	"github.com/anz-bank/sysl-go/common"
+	"github.com/anz-bank/sysl-go/testutil"
+)
+
+type greeting struct {
+	Msg string `json:"msg" validate:"required"`
+}
+
+func newTemporalWorkflowTestRouter(c client.Client) http.Handler {
+	routes := []TemporalWorkflowRoute{
+		{
+			Workflow:  "Greet",
+			TaskQueue: "tq",
+			Params: func() []TemporalWorkflowParam {
+				return []TemporalWorkflowParam{{Name: "req", Value: new(greeting)}}
+			},
+			Result: func() any { return new(greeting) },
+		},
+		{
+			Workflow:  "Compare",
+			TaskQueue: "tq",
+			Params: func() []TemporalWorkflowParam {
+				return []TemporalWorkflowParam{{Name: "left", Value: new(greeting)}, {Name: "right", Value: new(string)}}
+			},
+		},
+		{
+			Workflow: "Secret",
+			AuthorizationRule: func(ctx context.Context) (context.Context, error) {
+				return ctx, errors.New("denied")
+			},
+		},
+	}
+
+	r := chi.NewRouter()
+	NewTemporalWorkflowRouter(common.DefaultCallback(), c, "app", routes...).WireRoutes(testutil.NewTestContext(), r)
+	return r
+}
+
+func serveTemporalWorkflowRequest(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body)).WithContext(testutil.NewTestContext())
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func TestTemporalWorkflowRouterStart(t *testing.T) {
+	t.Parallel()
+	c := mocks.NewClient(t)
+	run := mocks.NewWorkflowRun(t)
+	run.On("GetID").Return("wf")
+	run.On("GetRunID").Return("run")
+	c.On("ExecuteWorkflow", mock.Anything, mock.MatchedBy(func(o client.StartWorkflowOptions) bool {
+		return o.ID != "" && o.TaskQueue == "tq"
+	}), "Greet", greeting{Msg: "hi"}).Return(run, nil).Once()
+	h := newTemporalWorkflowTestRouter(c)
+
+	w := serveTemporalWorkflowRequest(h, http.MethodPost, "/workflows/Greet", `{"msg": "hi"}`)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	var started TemporalWorkflowStarted
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &started))
+	require.Equal(t, TemporalWorkflowStarted{WorkflowID: "wf", RunID: "run"}, started)
+
+	// the request is validated before the workflow is started
+	w = serveTemporalWorkflowRequest(h, http.MethodPost, "/workflows/Greet", `{}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTemporalWorkflowRouterStartWithMultipleParams(t *testing.T) {
+	t.Parallel()
+	c := mocks.NewClient(t)
+	run := mocks.NewWorkflowRun(t)
+	run.On("GetID").Return("wf")
+	run.On("GetRunID").Return("run")
+	c.On("ExecuteWorkflow", mock.Anything, mock.Anything, "Compare", greeting{Msg: "hi"}, "there").Return(run, nil).Once()
+	h := newTemporalWorkflowTestRouter(c)
+
+	w := serveTemporalWorkflowRequest(h, http.MethodPost, "/workflows/Compare", `{"left": {"msg": "hi"}, "right": "there"}`)
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	w = serveTemporalWorkflowRequest(h, http.MethodPost, "/workflows/Compare", `{"left": {"msg": "hi"}}`)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestTemporalWorkflowRouterStatus(t *testing.T) {
+	t.Parallel()
+	c := mocks.NewClient(t)
+	c.On("DescribeWorkflowExecution", mock.Anything, "wf", "").Return(&workflowservice.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &workflowpb.WorkflowExecutionInfo{
+			Execution: &commonpb.WorkflowExecution{WorkflowId: "wf", RunId: "run"},
+			Status:    enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED,
+		},
+	}, nil).Once()
+	run := mocks.NewWorkflowRun(t)
+	run.On("Get", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		*args.Get(1).(*greeting) = greeting{Msg: "done"}
+	}).Return(nil).Once()
+	c.On("GetWorkflow", mock.Anything, "wf", "run").Return(run).Once()
+	h := newTemporalWorkflowTestRouter(c)
+
+	w := serveTemporalWorkflowRequest(h, http.MethodGet, "/workflows/Greet/wf", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"workflowId": "wf", "runId": "run", "status": "Completed", "result": {"msg": "done"}}`, w.Body.String())
+}
+
+func TestTemporalWorkflowRouterStatusRunning(t *testing.T) {
+	t.Parallel()
+	c := mocks.NewClient(t)
+	c.On("DescribeWorkflowExecution", mock.Anything, "wf", "run").Return(&workflowservice.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &workflowpb.WorkflowExecutionInfo{
+			Execution: &commonpb.WorkflowExecution{WorkflowId: "wf", RunId: "run"},
+			Status:    enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING,
+		},
+	}, nil).Once()
+	h := newTemporalWorkflowTestRouter(c)
+
+	w := serveTemporalWorkflowRequest(h, http.MethodGet, "/workflows/Greet/wf?runId=run", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"workflowId": "wf", "runId": "run", "status": "Running"}`, w.Body.String())
+}
+
+func TestTemporalWorkflowRouterCancelAndTerminate(t *testing.T) {
+	t.Parallel()
+	c := mocks.NewClient(t)
+	c.On("CancelWorkflow", mock.Anything, "wf", "").Return(nil).Once()
+	c.On("CancelWorkflow", mock.Anything, "missing", "").Return(serviceerror.NewNotFound("not found")).Once()
+	c.On("TerminateWorkflow", mock.Anything, "wf", "run", "stuck").Return(nil).Once()
+	h := newTemporalWorkflowTestRouter(c)
+
+	require.Equal(t, http.StatusAccepted, serveTemporalWorkflowRequest(h, http.MethodPost, "/workflows/Greet/wf/cancel", "").Code)
+	require.Equal(t, http.StatusNotFound, serveTemporalWorkflowRequest(h, http.MethodPost, "/workflows/Greet/missing/cancel", "").Code)
+	require.Equal(t, http.StatusOK, serveTemporalWorkflowRequest(h, http.MethodPost, "/workflows/Greet/wf/terminate?runId=run&reason=stuck", "").Code)
+}
+
+func TestTemporalWorkflowRouterAuthorization(t *testing.T) {
+	t.Parallel()
+	h := newTemporalWorkflowTestRouter(mocks.NewClient(t))
+
+	w := serveTemporalWorkflowRequest(h, http.MethodPost, "/workflows/Secret", "")
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,375 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	temporallog "go.temporal.io/sdk/log"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/anz-bank/sysl-go/common"
+	"github.com/anz-bank/sysl-go/log"
+
+	"github.com/google/uuid"
+)
+
+// NewTemporalLogger adapts logger to the logger interface of the Temporal SDK. Warnings are
+// logged at info level. Key-value pairs are added to the log entry as fields.
+func NewTemporalLogger(logger log.Logger) temporallog.Logger {
+	return &temporalLogger{logger}
+}
+
+type temporalLogger struct {
+	logger log.Logger
+}
+
+func (l *temporalLogger) Debug(msg string, keyvals ...interface{}) {
+	l.with(keyvals).Debug(msg)
+}
+
+func (l *temporalLogger) Info(msg string, keyvals ...interface{}) {
+	l.with(keyvals).Info(msg)
+}
+
+func (l *temporalLogger) Warn(msg string, keyvals ...interface{}) {
+	l.with(keyvals).WithStr("severity", "warn").Info(msg)
+}
+
+func (l *temporalLogger) Error(msg string, keyvals ...interface{}) {
+	var err error
+	for i := 1; i < len(keyvals); i += 2 {
+		if e, ok := keyvals[i].(error); ok {
+			err = e
+			break
+		}
+	}
+	if err == nil {
+		err = fmt.Errorf("%s", msg)
+	}
+	l.with(keyvals).Error(err, msg)
+}
+
+// With implements log.WithLogger.
+func (l *temporalLogger) With(keyvals ...interface{}) temporallog.Logger {
+	return &temporalLogger{l.with(keyvals)}
+}
+
+func (l *temporalLogger) with(keyvals []interface{}) log.Logger {
+	logger := l.logger
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		switch v := keyvals[i+1].(type) {
+		case int:
+			logger = logger.WithInt(key, v)
+		case time.Duration:
+			logger = logger.WithDuration(key, v)
+		default:
+			logger = logger.WithStr(key, fmt.Sprint(v))
+		}
+	}
+	return logger
+}
+
+type promRegistryKey struct{}
+
+// WithPrometheusRegistry returns a context that makes the Temporal clients and workers built
+// with it report their metrics to registry. NewServer does this for the registry of the admin
+// server.
+func WithPrometheusRegistry(ctx context.Context, registry prometheus.Registerer) context.Context {
+	return context.WithValue(ctx, promRegistryKey{}, registry)
+}
+
+func getPrometheusRegistry(ctx context.Context) prometheus.Registerer {
+	registry, _ := ctx.Value(promRegistryKey{}).(prometheus.Registerer)
+	return registry
+}
+
+// NewTemporalMetricsHandler returns a Temporal metrics handler that registers the metrics of the
+// SDK with registry. Each metric has its tags as labels, so a metric that is used with different
+// sets of tags has series with different label names. The handlers of the same registry share
+// their metrics.
+func NewTemporalMetricsHandler(registry prometheus.Registerer) client.MetricsHandler {
+	metrics, _ := temporalMetricsByRegistry.LoadOrStore(registry, &temporalMetrics{
+		registry: registry,
+		metrics:  map[string]*temporalMetric{},
+	})
+	return &temporalMetricsHandler{metrics: metrics.(*temporalMetrics)}
+}
+
+// temporalMetricsByRegistry holds the *temporalMetrics of each prometheus.Registerer.
+var temporalMetricsByRegistry sync.Map
+
+type temporalMetrics struct {
+	registry prometheus.Registerer
+	mu       sync.Mutex
+	metrics  map[string]*temporalMetric
+}
+
+// temporalMetric is a metric of the SDK with a vector for each set of label names it is used
+// with. It describes no metric, which makes it an unchecked collector whose vectors can share
+// the name of the metric.
+type temporalMetric struct {
+	kind string
+	mu   sync.Mutex
+	vecs map[string]prometheus.Collector
+}
+
+func (m *temporalMetric) Describe(chan<- *prometheus.Desc) {}
+
+func (m *temporalMetric) Collect(ch chan<- prometheus.Metric) {
+	m.mu.Lock()
+	vecs := make([]prometheus.Collector, 0, len(m.vecs))
+	for _, vec := range m.vecs {
+		vecs = append(vecs, vec)
+	}
+	m.mu.Unlock()
+	for _, vec := range vecs {
+		vec.Collect(ch)
+	}
+}
+
+type temporalMetricsHandler struct {
+	metrics *temporalMetrics
+	tags    map[string]string
+}
+
+func (h *temporalMetricsHandler) WithTags(tags map[string]string) client.MetricsHandler {
+	merged := make(map[string]string, len(h.tags)+len(tags))
+	for k, v := range h.tags {
+		merged[k] = v
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return &temporalMetricsHandler{metrics: h.metrics, tags: merged}
+}
+
+func (h *temporalMetricsHandler) Counter(name string) client.MetricsCounter {
+	vec := h.metrics.get(name, "counter", h.tags, func(opts prometheus.Opts, labels []string) prometheus.Collector {
+		opts.Name += "_total"
+		return prometheus.NewCounterVec(prometheus.CounterOpts(opts), labels)
+	})
+	typed, ok := vec.(*prometheus.CounterVec)
+	if !ok {
+		return client.MetricsNopHandler.Counter(name)
+	}
+	counter, err := typed.GetMetricWith(h.tags)
+	if err != nil {
+		return client.MetricsNopHandler.Counter(name)
+	}
+	return metricsCounterFunc(func(d int64) { counter.Add(float64(d)) })
+}
+
+func (h *temporalMetricsHandler) Gauge(name string) client.MetricsGauge {
+	vec := h.metrics.get(name, "gauge", h.tags, func(opts prometheus.Opts, labels []string) prometheus.Collector {
+		return prometheus.NewGaugeVec(prometheus.GaugeOpts(opts), labels)
+	})
+	typed, ok := vec.(*prometheus.GaugeVec)
+	if !ok {
+		return client.MetricsNopHandler.Gauge(name)
+	}
+	gauge, err := typed.GetMetricWith(h.tags)
+	if err != nil {
+		return client.MetricsNopHandler.Gauge(name)
+	}
+	return metricsGaugeFunc(gauge.Set)
+}
+
+func (h *temporalMetricsHandler) Timer(name string) client.MetricsTimer {
+	vec := h.metrics.get(name, "timer", h.tags, func(opts prometheus.Opts, labels []string) prometheus.Collector {
+		return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: opts.Name + "_seconds",
+			Help: opts.Help,
+		}, labels)
+	})
+	typed, ok := vec.(*prometheus.HistogramVec)
+	if !ok {
+		return client.MetricsNopHandler.Timer(name)
+	}
+	observer, err := typed.GetMetricWith(h.tags)
+	if err != nil {
+		return client.MetricsNopHandler.Timer(name)
+	}
+	return metricsTimerFunc(func(d time.Duration) { observer.Observe(d.Seconds()) })
+}
+
+type metricsCounterFunc func(int64)
+
+func (f metricsCounterFunc) Inc(d int64) { f(d) }
+
+type metricsGaugeFunc func(float64)
+
+func (f metricsGaugeFunc) Update(d float64) { f(d) }
+
+type metricsTimerFunc func(time.Duration)
+
+func (f metricsTimerFunc) Record(d time.Duration) { f(d) }
+
+// get returns the vector of the metric with the given name for the label names of tags, creating
+// it on first use and registering the metric on the first use of its name. It returns nil if the
+// metric could not be registered or the name is already used by a metric of another kind.
+func (m *temporalMetrics) get(
+	name, kind string,
+	tags map[string]string,
+	create func(prometheus.Opts, []string) prometheus.Collector,
+) prometheus.Collector {
+	m.mu.Lock()
+	metric, has := m.metrics[name]
+	if !has {
+		metric = &temporalMetric{kind: kind, vecs: map[string]prometheus.Collector{}}
+		if err := m.registry.Register(metric); err != nil {
+			metric = nil
+		}
+		m.metrics[name] = metric
+	}
+	m.mu.Unlock()
+	if metric == nil || metric.kind != kind {
+		return nil
+	}
+
+	labels := make([]string, 0, len(tags))
+	for k := range tags {
+		labels = append(labels, k)
+	}
+	sort.Strings(labels)
+	key := strings.Join(labels, ",")
+	metric.mu.Lock()
+	defer metric.mu.Unlock()
+	vec, has := metric.vecs[key]
+	if !has {
+		promName := strings.NewReplacer(".", "_", "-", "_").Replace(name)
+		vec = create(prometheus.Opts{Name: promName, Help: "Temporal SDK metric " + name}, labels)
+		metric.vecs[key] = vec
+	}
+	return vec
+}
+
+// temporalPropagationHeader is the Temporal header that carries the propagated request context.
+const temporalPropagationHeader = "sysl-go-request"
+
+type temporalPropagatedContext struct {
+	TraceID string      `json:"traceId,omitempty"`
+	Headers http.Header `json:"headers,omitempty"`
+}
+
+type temporalPropagatedContextKey struct{}
+
+// NewTemporalContextPropagator returns a context propagator that carries the trace ID and the
+// given request headers of the request that started a workflow into the workflow and its
+// activities. Activities see them through common.GetTraceIDFromContext and
+// common.RequestHeaderFromContext; workflows through TemporalTraceIDFromWorkflow and
+// TemporalRequestHeaderFromWorkflow.
+func NewTemporalContextPropagator(headers []string) workflow.ContextPropagator {
+	names := make([]string, 0, len(headers))
+	for _, h := range headers {
+		names = append(names, http.CanonicalHeaderKey(h))
+	}
+	return &temporalContextPropagator{headers: names}
+}
+
+type temporalContextPropagator struct {
+	headers []string
+}
+
+func (p *temporalContextPropagator) Inject(ctx context.Context, writer workflow.HeaderWriter) error {
+	propagated := temporalPropagatedContext{}
+	if id, ok := common.LookupTraceIDFromContext(ctx); ok {
+		propagated.TraceID = id.String()
+	}
+	if reqHeader := common.RequestHeaderFromContext(ctx); reqHeader != nil {
+		propagated.Headers = p.selectHeaders(reqHeader)
+	}
+	return p.write(propagated, writer)
+}
+
+func (p *temporalContextPropagator) Extract(ctx context.Context, reader workflow.HeaderReader) (context.Context, error) {
+	propagated, ok, err := p.read(reader)
+	if err != nil || !ok {
+		return ctx, err
+	}
+	if id, err := uuid.Parse(propagated.TraceID); err == nil {
+		ctx = common.AddTraceIDToContext(ctx, id, true)
+	}
+	if len(propagated.Headers) > 0 {
+		ctx = common.RequestHeaderToContext(ctx, propagated.Headers)
+	}
+	return ctx, nil
+}
+
+func (p *temporalContextPropagator) InjectFromWorkflow(ctx workflow.Context, writer workflow.HeaderWriter) error {
+	propagated, ok := ctx.Value(temporalPropagatedContextKey{}).(*temporalPropagatedContext)
+	if !ok {
+		return nil
+	}
+	return p.write(*propagated, writer)
+}
+
+func (p *temporalContextPropagator) ExtractToWorkflow(ctx workflow.Context, reader workflow.HeaderReader) (workflow.Context, error) {
+	propagated, ok, err := p.read(reader)
+	if err != nil || !ok {
+		return ctx, err
+	}
+	return workflow.WithValue(ctx, temporalPropagatedContextKey{}, propagated), nil
+}
+
+func (p *temporalContextPropagator) selectHeaders(header http.Header) http.Header {
+	selected := http.Header{}
+	for _, name := range p.headers {
+		if values := header.Values(name); len(values) > 0 {
+			selected[name] = values
+		}
+	}
+	return selected
+}
+
+func (p *temporalContextPropagator) write(propagated temporalPropagatedContext, writer workflow.HeaderWriter) error {
+	if propagated.TraceID == "" && len(propagated.Headers) == 0 {
+		return nil
+	}
+	payload, err := converter.GetDefaultDataConverter().ToPayload(propagated)
+	if err != nil {
+		return err
+	}
+	writer.Set(temporalPropagationHeader, payload)
+	return nil
+}
+
+func (p *temporalContextPropagator) read(reader workflow.HeaderReader) (*temporalPropagatedContext, bool, error) {
+	payload, ok := reader.Get(temporalPropagationHeader)
+	if !ok {
+		return nil, false, nil
+	}
+	var propagated temporalPropagatedContext
+	if err := converter.GetDefaultDataConverter().FromPayload(payload, &propagated); err != nil {
+		return nil, false, err
+	}
+	return &propagated, true, nil
+}
+
+// TemporalTraceIDFromWorkflow returns the trace ID of the request that started the workflow.
+func TemporalTraceIDFromWorkflow(ctx workflow.Context) (uuid.UUID, bool) {
+	propagated, ok := ctx.Value(temporalPropagatedContextKey{}).(*temporalPropagatedContext)
+	if !ok {
+		return uuid.UUID{}, false
+	}
+	id, err := uuid.Parse(propagated.TraceID)
+	return id, err == nil
+}
+
+// TemporalRequestHeaderFromWorkflow returns the propagated headers of the request that started
+// the workflow.
+func TemporalRequestHeaderFromWorkflow(ctx workflow.Context) http.Header {
+	propagated, ok := ctx.Value(temporalPropagatedContextKey{}).(*temporalPropagatedContext)
+	if !ok {
+		return nil
+	}
+	return propagated.Headers.Clone()
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	temporallog "go.temporal.io/sdk/log"
+
+	"github.com/anz-bank/sysl-go/common"
+	"github.com/anz-bank/sysl-go/log"
+	"github.com/anz-bank/sysl-go/testutil"
+)
+
+func TestTemporalLogger(t *testing.T) {
+	t.Parallel()
+	logger := testutil.NewTestLogger()
+	logger.Level = log.DebugLevel
+	tl := NewTemporalLogger(logger)
+
+	tl.Info("started", "WorkflowID", "wf-1", "Attempt", 2)
+	entry := logger.LastEntry()
+	require.Equal(t, "started", entry.Message)
+	require.Equal(t, log.InfoLevel, entry.Level)
+	require.Equal(t, "wf-1", entry.Fields["WorkflowID"])
+	require.Equal(t, 2, entry.Fields["Attempt"])
+
+	tl.Warn("slow")
+	require.Equal(t, "warn", logger.LastEntry().Fields["severity"])
+
+	cause := errors.New("boom")
+	tl.Error("failed", "Error", cause)
+	require.Equal(t, log.ErrorLevel, logger.LastEntry().Level)
+	require.Equal(t, cause, logger.LastEntry().Error)
+
+	tl.(temporallog.WithLogger).With("Namespace", "default").Debug("polling")
+	require.Equal(t, "default", logger.LastEntry().Fields["Namespace"])
+}
+
+func TestTemporalMetricsHandler(t *testing.T) {
+	t.Parallel()
+	registry := prometheus.NewRegistry()
+	handler := NewTemporalMetricsHandler(registry).WithTags(map[string]string{"namespace": "default"})
+
+	handler.Counter("temporal_request").Inc(2)
+	handler.Gauge("temporal_num_pollers").Update(3)
+	handler.Timer("temporal_request_latency").Record(0)
+	// the same metric with other tags
+	handler.WithTags(map[string]string{"namespace": "other", "operation": "StartWorkflowExecution"}).Counter("temporal_request").Inc(1)
+	NewTemporalMetricsHandler(registry).Counter("temporal_request").Inc(1)
+	// a second handler on the same registry shares the registered metrics
+	NewTemporalMetricsHandler(registry).WithTags(map[string]string{"namespace": "default"}).Counter("temporal_request").Inc(1)
+	// a name that is already used by a metric of another kind is ignored
+	handler.Gauge("temporal_request").Update(1)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	series := map[string][]map[string]string{}
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			series[family.GetName()] = append(series[family.GetName()], labels)
+			if family.GetName() == "temporal_request_total" {
+				values[labels["namespace"]+"/"+labels["operation"]] = metric.GetCounter().GetValue()
+			}
+		}
+	}
+	require.Len(t, series["temporal_num_pollers"], 1)
+	require.Len(t, series["temporal_request_latency_seconds"], 1)
+	require.ElementsMatch(t, []map[string]string{
+		{"namespace": "default"},
+		{"namespace": "other", "operation": "StartWorkflowExecution"},
+		{},
+	}, series["temporal_request_total"])
+	require.Equal(t, map[string]float64{"default/": 3, "other/StartWorkflowExecution": 1, "/": 1}, values)
+	require.NotContains(t, series, "temporal_request")
+}
+
+type testHeader map[string]*commonpb.Payload
+
+func (h testHeader) Set(key string, value *commonpb.Payload) { h[key] = value }
+
+func (h testHeader) Get(key string) (*commonpb.Payload, bool) {
+	value, ok := h[key]
+	return value, ok
+}
+
+func (h testHeader) ForEachKey(handler func(string, *commonpb.Payload) error) error {
+	for k, v := range h {
+		if err := handler(k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestTemporalContextPropagator(t *testing.T) {
+	t.Parallel()
+	propagator := NewTemporalContextPropagator([]string{"x-tenant"})
+
+	traceID := uuid.New()
+	ctx := common.AddTraceIDToContext(context.Background(), traceID, true)
+	ctx = common.RequestHeaderToContext(ctx, http.Header{
+		"X-Tenant":      {"tenant-a"},
+		"Authorization": {"Bearer secret"},
+	})
+
+	header := testHeader{}
+	require.NoError(t, propagator.Inject(ctx, header))
+
+	extracted, err := propagator.Extract(context.Background(), header)
+	require.NoError(t, err)
+	id, ok := common.LookupTraceIDFromContext(extracted)
+	require.True(t, ok)
+	require.Equal(t, traceID, id)
+	require.Equal(t, http.Header{"X-Tenant": {"tenant-a"}}, common.RequestHeaderFromContext(extracted))
+
+	// nothing is propagated from a context without trace ID or headers
+	empty := testHeader{}
+	require.NoError(t, propagator.Inject(context.Background(), empty))
+	require.Empty(t, empty)
+	unchanged, err := propagator.Extract(context.Background(), empty)
+	require.NoError(t, err)
+	_, ok = common.LookupTraceIDFromContext(unchanged)
+	require.False(t, ok)
+}
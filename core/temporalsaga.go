@@ -0,0 +1,102 @@
+package core
+
+import (
+	"errors"
+
+	"go.temporal.io/sdk/workflow"
+)
+
+type sagaKeyType int
+
+const sagaKey sagaKeyType = iota
+
+// SagaOptions configure how a Saga runs its compensations.
+type SagaOptions struct {
+	// Parallel runs the compensations concurrently instead of one by one in reverse order. All of
+	// them run even when some fail, whatever ContinueOnError is.
+	Parallel bool
+	// ContinueOnError runs the remaining compensations when one of them fails, instead of
+	// stopping at the first failure. It only applies to sequential compensations.
+	ContinueOnError bool
+}
+
+// Saga records a compensation for each completed step of a workflow and runs them when a later
+// step fails.
+type Saga struct {
+	options       SagaOptions
+	compensations []func(ctx workflow.Context) error
+}
+
+// NewSaga returns a Saga without compensations that runs them with the given options.
+func NewSaga(options SagaOptions) *Saga {
+	return &Saga{options: options}
+}
+
+// AddCompensation records the compensation of a completed step.
+func (s *Saga) AddCompensation(compensation func(ctx workflow.Context) error) {
+	s.compensations = append(s.compensations, compensation)
+}
+
+// Compensate runs the recorded compensations, most recent first, and forgets them. They run in
+// a disconnected context so that a cancelled workflow is still compensated.
+func (s *Saga) Compensate(ctx workflow.Context) error {
+	ctx, _ = workflow.NewDisconnectedContext(ctx)
+	compensations := s.compensations
+	s.compensations = nil
+
+	errs := make([]error, len(compensations))
+	if s.options.Parallel {
+		wg := workflow.NewWaitGroup(ctx)
+		for i := range compensations {
+			wg.Add(1)
+			workflow.Go(ctx, func(ctx workflow.Context) {
+				defer wg.Done()
+				errs[i] = compensations[i](ctx)
+			})
+		}
+		wg.Wait(ctx)
+		return errors.Join(errs...)
+	}
+
+	for i := len(compensations) - 1; i >= 0; i-- {
+		errs[i] = compensations[i](ctx)
+		if errs[i] != nil && !s.options.ContinueOnError {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// WithSaga returns a context in which the activities executed with a compensation record it
+// with the given saga.
+func WithSaga(ctx workflow.Context, saga *Saga) workflow.Context {
+	return workflow.WithValue(ctx, sagaKey, saga)
+}
+
+// GetSaga returns the saga of the context or nil if there is none.
+func GetSaga(ctx workflow.Context) *Saga {
+	saga, _ := ctx.Value(sagaKey).(*Saga)
+	return saga
+}
+
+// WithCompensation records compensate with the saga of the context, if any, once the activity of
+// the future completes successfully. The compensation is only recorded by Future.Get.
+func WithCompensation[T any](ctx workflow.Context, f *Future[T], compensate func(ctx workflow.Context) error) *Future[T] {
+	if saga := GetSaga(ctx); saga != nil {
+		f.onSuccess = func() { saga.AddCompensation(compensate) }
+	}
+	return f
+}
+
+// RunSaga runs fn with a saga in its context and, if fn fails, runs the compensations of the
+// steps that completed. The errors of the compensations are joined to the error of fn.
+func RunSaga[T any](ctx workflow.Context, options SagaOptions, fn func(ctx workflow.Context) (T, error)) (T, error) {
+	saga := NewSaga(options)
+	t, err := fn(WithSaga(ctx, saga))
+	if err != nil {
+		if compensateErr := saga.Compensate(ctx); compensateErr != nil {
+			err = errors.Join(err, compensateErr)
+		}
+	}
+	return t, err
+}
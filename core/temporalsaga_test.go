@@ -0,0 +1,137 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+// sagaSteps executes the named activities in order and records their compensations, an
+// activity named "Fail" fails.
+func sagaSteps(ctx workflow.Context, steps ...string) (string, error) {
+	for _, step := range steps {
+		f := ExecuteActivityWithOptions[string](ctx, "app", step, ActivityOptions{
+			RetryPolicy: &temporal.RetryPolicy{MaximumAttempts: 1},
+		})
+		f = WithCompensation(ctx, f, func(ctx workflow.Context) error {
+			_, err := ExecuteActivity[string](ctx, "app", "Undo", step).Get(ctx)
+			return err
+		})
+		if _, err := f.Get(ctx); err != nil {
+			return "", err
+		}
+	}
+	return "done", nil
+}
+
+func newSagaTestEnv(options SagaOptions, undoErr error) (*testsuite.TestWorkflowEnvironment, *[]string) {
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+
+	var mu sync.Mutex
+	var undone []string
+	for _, name := range []string{"Reserve", "Charge", "Ship"} {
+		env.RegisterActivityWithOptions(func(context.Context) (string, error) {
+			return name, nil
+		}, activity.RegisterOptions{Name: name})
+	}
+	env.RegisterActivityWithOptions(func(context.Context) (string, error) {
+		return "", errors.New("failed")
+	}, activity.RegisterOptions{Name: "Fail"})
+	env.RegisterActivityWithOptions(func(_ context.Context, step string) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		undone = append(undone, step)
+		if step == "Charge" {
+			return "", undoErr
+		}
+		return step, nil
+	}, activity.RegisterOptions{Name: "Undo"})
+	env.RegisterWorkflowWithOptions(func(ctx workflow.Context, steps []string) (string, error) {
+		return RunSaga(ctx, options, func(ctx workflow.Context) (string, error) {
+			return sagaSteps(ctx, steps...)
+		})
+	}, workflow.RegisterOptions{Name: "Saga"})
+
+	return env, &undone
+}
+
+func TestRunSagaCompensatesInReverseOrder(t *testing.T) {
+	t.Parallel()
+	env, undone := newSagaTestEnv(SagaOptions{}, nil)
+
+	env.ExecuteWorkflow("Saga", []string{"Reserve", "Charge", "Fail", "Ship"})
+	require.Error(t, env.GetWorkflowError())
+	require.Equal(t, []string{"Charge", "Reserve"}, *undone)
+}
+
+func TestRunSagaDoesNotCompensateOnSuccess(t *testing.T) {
+	t.Parallel()
+	env, undone := newSagaTestEnv(SagaOptions{}, nil)
+
+	env.ExecuteWorkflow("Saga", []string{"Reserve", "Charge"})
+	require.NoError(t, env.GetWorkflowError())
+	require.Empty(t, *undone)
+}
+
+func TestRunSagaStopsOnCompensationError(t *testing.T) {
+	t.Parallel()
+	env, undone := newSagaTestEnv(SagaOptions{}, temporal.NewNonRetryableApplicationError("refund failed", "Refund", nil))
+
+	env.ExecuteWorkflow("Saga", []string{"Reserve", "Charge", "Fail"})
+	require.ErrorContains(t, env.GetWorkflowError(), "refund failed")
+	require.Equal(t, []string{"Charge"}, *undone)
+}
+
+func TestRunSagaContinuesOnCompensationError(t *testing.T) {
+	t.Parallel()
+	env, undone := newSagaTestEnv(SagaOptions{ContinueOnError: true}, temporal.NewNonRetryableApplicationError("refund failed", "Refund", nil))
+
+	env.ExecuteWorkflow("Saga", []string{"Reserve", "Charge", "Fail"})
+	require.ErrorContains(t, env.GetWorkflowError(), "refund failed")
+	require.Equal(t, []string{"Charge", "Reserve"}, *undone)
+}
+
+func TestRunSagaCompensatesInParallel(t *testing.T) {
+	t.Parallel()
+	env, undone := newSagaTestEnv(SagaOptions{Parallel: true}, nil)
+
+	env.ExecuteWorkflow("Saga", []string{"Reserve", "Charge", "Ship", "Fail"})
+	require.Error(t, env.GetWorkflowError())
+	require.ElementsMatch(t, []string{"Reserve", "Charge", "Ship"}, *undone)
+}
+
+func TestRunSagaRunsAllParallelCompensationsOnError(t *testing.T) {
+	t.Parallel()
+	env, undone := newSagaTestEnv(SagaOptions{Parallel: true}, temporal.NewNonRetryableApplicationError("refund failed", "Refund", nil))
+
+	env.ExecuteWorkflow("Saga", []string{"Reserve", "Charge", "Ship", "Fail"})
+	require.ErrorContains(t, env.GetWorkflowError(), "refund failed")
+	require.ElementsMatch(t, []string{"Reserve", "Charge", "Ship"}, *undone)
+}
+
+func TestWithCompensationWithoutSaga(t *testing.T) {
+	t.Parallel()
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestWorkflowEnvironment()
+	env.RegisterActivityWithOptions(func(context.Context) (string, error) {
+		return "reserved", nil
+	}, activity.RegisterOptions{Name: "Reserve"})
+	env.RegisterWorkflowWithOptions(func(ctx workflow.Context) (bool, error) {
+		_, err := sagaSteps(ctx, "Reserve")
+		return GetSaga(ctx) == nil, err
+	}, workflow.RegisterOptions{Name: "NoSaga"})
+
+	env.ExecuteWorkflow("NoSaga")
+	require.NoError(t, env.GetWorkflowError())
+	var noSaga bool
+	require.NoError(t, env.GetWorkflowResult(&noSaga))
+	require.True(t, noSaga)
+}
@@ -0,0 +1,185 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+
+	"github.com/anz-bank/sysl-go/log"
+)
+
+// TemporalSchedule is the schedule of a workflow given in the spec.
+type TemporalSchedule struct {
+	// Workflow is the name of the workflow to start.
+	Workflow string
+	// CronExpressions and Intervals give the times at which the workflow starts.
+	CronExpressions []string
+	Intervals       []time.Duration
+	// Overlap is one of skip (default), bufferOne, bufferAll, cancelOther, terminateOther or allowAll.
+	Overlap string
+	// Jitter delays each start by a random amount up to the given duration.
+	Jitter time.Duration
+}
+
+// TemporalScheduler is implemented by generated temporal service handlers to expose the
+// schedules given in the spec.
+type TemporalScheduler interface {
+	GetSchedules() []TemporalSchedule
+}
+
+var scheduleOverlapPolicies = map[string]enumspb.ScheduleOverlapPolicy{
+	"":               enumspb.SCHEDULE_OVERLAP_POLICY_SKIP,
+	"skip":           enumspb.SCHEDULE_OVERLAP_POLICY_SKIP,
+	"bufferOne":      enumspb.SCHEDULE_OVERLAP_POLICY_BUFFER_ONE,
+	"bufferAll":      enumspb.SCHEDULE_OVERLAP_POLICY_BUFFER_ALL,
+	"cancelOther":    enumspb.SCHEDULE_OVERLAP_POLICY_CANCEL_OTHER,
+	"terminateOther": enumspb.SCHEDULE_OVERLAP_POLICY_TERMINATE_OTHER,
+	"allowAll":       enumspb.SCHEDULE_OVERLAP_POLICY_ALLOW_ALL,
+}
+
+// temporalScheduleID returns the ID of the schedule of the given workflow. All schedules of a
+// task queue share its name as prefix so that schedules removed from the spec can be found.
+func temporalScheduleID(taskQueue, workflow string) string {
+	return taskQueue + "/" + workflow
+}
+
+func (s TemporalSchedule) spec() client.ScheduleSpec {
+	intervals := make([]client.ScheduleIntervalSpec, 0, len(s.Intervals))
+	for _, every := range s.Intervals {
+		intervals = append(intervals, client.ScheduleIntervalSpec{Every: every})
+	}
+	return client.ScheduleSpec{
+		CronExpressions: s.CronExpressions,
+		Intervals:       intervals,
+		Jitter:          s.Jitter,
+	}
+}
+
+func (s TemporalSchedule) action(taskQueue string) *client.ScheduleWorkflowAction {
+	return &client.ScheduleWorkflowAction{
+		ID:        temporalScheduleID(taskQueue, s.Workflow),
+		Workflow:  s.Workflow,
+		TaskQueue: taskQueue,
+	}
+}
+
+// ReconcileTemporalSchedules creates, updates and deletes the Temporal schedules of the task
+// queue so that they match the given schedules.
+func ReconcileTemporalSchedules(ctx context.Context, c client.Client, taskQueue string, schedules []TemporalSchedule) error {
+	desired := make(map[string]TemporalSchedule, len(schedules))
+	for _, s := range schedules {
+		if _, ok := scheduleOverlapPolicies[s.Overlap]; !ok {
+			return fmt.Errorf("invalid overlap policy %s for the schedule of workflow %s", s.Overlap, s.Workflow)
+		}
+		desired[temporalScheduleID(taskQueue, s.Workflow)] = s
+	}
+
+	existing, err := listTemporalSchedules(ctx, c, taskQueue)
+	if err != nil {
+		return err
+	}
+
+	sc := c.ScheduleClient()
+	for id, s := range desired {
+		overlap := scheduleOverlapPolicies[s.Overlap]
+		if _, ok := existing[id]; !ok {
+			log.Infof(ctx, "creating schedule %s", id)
+			_, err = sc.Create(ctx, client.ScheduleOptions{
+				ID:      id,
+				Spec:    s.spec(),
+				Action:  s.action(taskQueue),
+				Overlap: overlap,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create schedule %s: %w", id, err)
+			}
+			continue
+		}
+		log.Debugf(ctx, "updating schedule %s", id)
+		err = sc.GetHandle(ctx, id).Update(ctx, client.ScheduleUpdateOptions{
+			DoUpdate: func(input client.ScheduleUpdateInput) (*client.ScheduleUpdate, error) {
+				schedule := input.Description.Schedule
+				spec := s.spec()
+				schedule.Spec = &spec
+				schedule.Action = s.action(taskQueue)
+				if schedule.Policy == nil {
+					schedule.Policy = &client.SchedulePolicies{}
+				}
+				schedule.Policy.Overlap = overlap
+				return &client.ScheduleUpdate{Schedule: &schedule}, nil
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update schedule %s: %w", id, err)
+		}
+	}
+
+	for id := range existing {
+		if _, ok := desired[id]; !ok {
+			log.Infof(ctx, "deleting schedule %s", id)
+			if err = sc.GetHandle(ctx, id).Delete(ctx); err != nil {
+				return fmt.Errorf("failed to delete schedule %s: %w", id, err)
+			}
+		}
+	}
+	return nil
+}
+
+// listTemporalSchedules returns the schedules of the task queue by ID.
+func listTemporalSchedules(ctx context.Context, c client.Client, taskQueue string) (map[string]*client.ScheduleListEntry, error) {
+	iter, err := c.ScheduleClient().List(ctx, client.ScheduleListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+	prefix := temporalScheduleID(taskQueue, "")
+	schedules := map[string]*client.ScheduleListEntry{}
+	for iter.HasNext() {
+		entry, err := iter.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list schedules: %w", err)
+		}
+		if strings.HasPrefix(entry.ID, prefix) {
+			schedules[entry.ID] = entry
+		}
+	}
+	return schedules, nil
+}
+
+// TemporalScheduleStatus is the state of a schedule as listed by NewTemporalSchedulesHandler.
+type TemporalScheduleStatus struct {
+	ID           string      `json:"id"`
+	Workflow     string      `json:"workflow"`
+	Paused       bool        `json:"paused"`
+	NextRunTimes []time.Time `json:"nextRunTimes"`
+}
+
+// NewTemporalSchedulesHandler returns an http.Handler that lists the schedules of the task queue
+// with their next run times as JSON.
+func NewTemporalSchedulesHandler(c client.Client, taskQueue string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		schedules, err := listTemporalSchedules(r.Context(), c, taskQueue)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		statuses := make([]TemporalScheduleStatus, 0, len(schedules))
+		for id, entry := range schedules {
+			statuses = append(statuses, TemporalScheduleStatus{
+				ID:           id,
+				Workflow:     entry.WorkflowType.Name,
+				Paused:       entry.Paused,
+				NextRunTimes: entry.NextActionTimes,
+			})
+		}
+		sort.Slice(statuses, func(i, j int) bool { return statuses[i].ID < statuses[j].ID })
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(statuses)
+	})
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/mocks"
+
+	"github.com/anz-bank/sysl-go/testutil"
+)
+
+func mockScheduleList(t *testing.T, sc *mocks.ScheduleClient, entries ...*client.ScheduleListEntry) {
+	iter := mocks.NewScheduleListIterator(t)
+	for _, entry := range entries {
+		iter.On("HasNext").Return(true).Once()
+		iter.On("Next").Return(entry, nil).Once()
+	}
+	iter.On("HasNext").Return(false).Once()
+	sc.On("List", mock.Anything, mock.Anything).Return(iter, nil).Once()
+}
+
+func TestReconcileTemporalSchedules(t *testing.T) {
+	t.Parallel()
+	ctx := testutil.NewTestContext()
+	c := mocks.NewClient(t)
+	sc := mocks.NewScheduleClient(t)
+	c.On("ScheduleClient").Return(sc)
+
+	mockScheduleList(t, sc,
+		&client.ScheduleListEntry{ID: "app/Existing"},
+		&client.ScheduleListEntry{ID: "app/Removed"},
+		&client.ScheduleListEntry{ID: "other/Unrelated"},
+	)
+
+	sc.On("Create", mock.Anything, mock.MatchedBy(func(o client.ScheduleOptions) bool {
+		action := o.Action.(*client.ScheduleWorkflowAction)
+		return o.ID == "app/New" &&
+			o.Spec.CronExpressions[0] == "0 * * * *" &&
+			o.Overlap == enumspb.SCHEDULE_OVERLAP_POLICY_BUFFER_ONE &&
+			action.Workflow == "New" && action.TaskQueue == "app"
+	})).Return(nil, nil).Once()
+
+	existing := mocks.NewScheduleHandle(t)
+	sc.On("GetHandle", mock.Anything, "app/Existing").Return(existing)
+	existing.On("Update", mock.Anything, mock.MatchedBy(func(o client.ScheduleUpdateOptions) bool {
+		update, err := o.DoUpdate(client.ScheduleUpdateInput{})
+		return err == nil &&
+			update.Schedule.Spec.Intervals[0].Every == time.Hour &&
+			update.Schedule.Spec.Jitter == time.Minute &&
+			update.Schedule.Policy.Overlap == enumspb.SCHEDULE_OVERLAP_POLICY_SKIP
+	})).Return(nil).Once()
+
+	removed := mocks.NewScheduleHandle(t)
+	sc.On("GetHandle", mock.Anything, "app/Removed").Return(removed)
+	removed.On("Delete", mock.Anything).Return(nil).Once()
+
+	err := ReconcileTemporalSchedules(ctx, c, "app", []TemporalSchedule{
+		{Workflow: "New", CronExpressions: []string{"0 * * * *"}, Overlap: "bufferOne"},
+		{Workflow: "Existing", Intervals: []time.Duration{time.Hour}, Jitter: time.Minute},
+	})
+	require.NoError(t, err)
+}
+
+func TestReconcileTemporalSchedules_InvalidOverlap(t *testing.T) {
+	t.Parallel()
+	err := ReconcileTemporalSchedules(testutil.NewTestContext(), mocks.NewClient(t), "app", []TemporalSchedule{
+		{Workflow: "New", CronExpressions: []string{"0 * * * *"}, Overlap: "sometimes"},
+	})
+	require.ErrorContains(t, err, "invalid overlap policy")
+}
+
+func TestNewTemporalSchedulesHandler(t *testing.T) {
+	t.Parallel()
+	c := mocks.NewClient(t)
+	sc := mocks.NewScheduleClient(t)
+	c.On("ScheduleClient").Return(sc)
+	next := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	entry := &client.ScheduleListEntry{ID: "app/Nightly", NextActionTimes: []time.Time{next}}
+	entry.WorkflowType.Name = "Nightly"
+	mockScheduleList(t, sc, entry)
+
+	rec := httptest.NewRecorder()
+	NewTemporalSchedulesHandler(c, "app").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/-/temporal/schedules", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t,
+		`[{"id":"app/Nightly","workflow":"Nightly","paused":false,"nextRunTimes":["2026-01-01T00:00:00Z"]}]`,
+		rec.Body.String())
+}
@@ -0,0 +1,247 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoggerField is the field that names a logger, e.g. log.WithStr(ctx, LoggerField, "db").
+// The level of the named loggers can be changed with a LevelController.
+const LoggerField = "logger"
+
+// ParseLevel returns the level named error, info or debug.
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(s) {
+	case "error":
+		return ErrorLevel, nil
+	case "info":
+		return InfoLevel, nil
+	case "debug":
+		return DebugLevel, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q, expected one of error, info, debug", s)
+}
+
+// LevelScope is the scope of a level override of a LevelController: the loggers named Logger,
+// the requests of the Route pattern, or every logger if both are empty.
+type LevelScope struct {
+	Logger string
+	Route  string
+}
+
+// LevelOverride is a level set with a LevelController.
+type LevelOverride struct {
+	LevelScope
+	Level Level
+	// Expires is when the level reverts, zero if it never does.
+	Expires time.Time
+}
+
+// LevelController changes the log levels and the logging of payloads of a running application,
+// globally, by logger name or by route, optionally reverting after a time to live.
+//
+// The level of a logger named by the LoggerField is the level of its name if one is set, or else
+// the level of the route of the request, or else the global level, or else the configured one.
+// The levels of names, routes and requests only apply to the loggers of their context: the Logrus
+// logger, whose WithLevel changes the level of the whole logger, is copied for them.
+type LevelController struct {
+	mu         sync.Mutex
+	level      Level
+	logPayload bool
+	overrides  map[LevelScope]LevelOverride
+	payload    *payloadOverride
+	now        func() time.Time
+}
+
+type payloadOverride struct {
+	enabled bool
+	expires time.Time
+}
+
+// NewLevelController returns a LevelController of the configured level and logging of payloads.
+func NewLevelController(level Level, logPayload bool) *LevelController {
+	return &LevelController{
+		level:      level,
+		logPayload: logPayload,
+		overrides:  map[LevelScope]LevelOverride{},
+		now:        time.Now,
+	}
+}
+
+// SetLevel sets the level of the scope, reverting after ttl unless it is zero.
+func (c *LevelController) SetLevel(scope LevelScope, level Level, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	o := LevelOverride{LevelScope: scope, Level: level}
+	if ttl > 0 {
+		o.Expires = c.now().Add(ttl)
+	}
+	c.overrides[scope] = o
+}
+
+// ResetLevel reverts the level of the scope.
+func (c *LevelController) ResetLevel(scope LevelScope) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.overrides, scope)
+}
+
+// SetLogPayload sets whether the payloads of requests are logged, reverting after ttl unless it
+// is zero.
+func (c *LevelController) SetLogPayload(enabled bool, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.payload = &payloadOverride{enabled: enabled}
+	if ttl > 0 {
+		c.payload.expires = c.now().Add(ttl)
+	}
+}
+
+// ResetLogPayload reverts the logging of payloads to the configured one.
+func (c *LevelController) ResetLogPayload() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.payload = nil
+}
+
+// LogPayload returns whether the payloads of requests are logged.
+func (c *LevelController) LogPayload() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.payload != nil && !c.payload.expires.IsZero() && !c.now().Before(c.payload.expires) {
+		c.payload = nil
+	}
+	if c.payload != nil {
+		return c.payload.enabled
+	}
+	return c.logPayload
+}
+
+// ConfiguredLevel returns the level that applies when no level is set.
+func (c *LevelController) ConfiguredLevel() Level {
+	return c.level
+}
+
+// Level returns the level of the logger named logger in a request of the route pattern, either
+// of which may be empty.
+func (c *LevelController) Level(logger, route string) Level {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire()
+	if logger != "" {
+		if o, has := c.overrides[LevelScope{Logger: logger}]; has {
+			return o.Level
+		}
+	}
+	if route != "" {
+		if o, has := c.overrides[LevelScope{Route: route}]; has {
+			return o.Level
+		}
+	}
+	if o, has := c.overrides[LevelScope{}]; has {
+		return o.Level
+	}
+	return c.level
+}
+
+// loggerLevel returns the level set for the logger name, if any.
+func (c *LevelController) loggerLevel(logger string) (Level, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire()
+	o, has := c.overrides[LevelScope{Logger: logger}]
+	return o.Level, has
+}
+
+// Overrides returns the levels that are set, ordered by scope.
+func (c *LevelController) Overrides() []LevelOverride {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expire()
+	overrides := make([]LevelOverride, 0, len(c.overrides))
+	for _, o := range c.overrides {
+		overrides = append(overrides, o)
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		if overrides[i].Logger != overrides[j].Logger {
+			return overrides[i].Logger < overrides[j].Logger
+		}
+		return overrides[i].Route < overrides[j].Route
+	})
+	return overrides
+}
+
+// expire reverts the levels whose time to live has passed, c.mu must be held.
+func (c *LevelController) expire() {
+	now := c.now()
+	for scope, o := range c.overrides {
+		if !o.Expires.IsZero() && !now.Before(o.Expires) {
+			delete(c.overrides, scope)
+		}
+	}
+}
+
+type levelControllerKey struct{}
+
+type requestLevelKey struct{}
+
+// WithLevelController returns the given context with the LevelController of the application.
+func WithLevelController(ctx context.Context, c *LevelController) context.Context {
+	return context.WithValue(ctx, levelControllerKey{}, c)
+}
+
+// GetLevelController returns the LevelController from the context, or nil if there is none.
+func GetLevelController(ctx context.Context) *LevelController {
+	c, _ := ctx.Value(levelControllerKey{}).(*LevelController)
+	return c
+}
+
+// WithRequestLevel returns the given context with a logger that logs at the given level, which
+// also applies to the loggers named within the context, regardless of the levels of their names.
+// It is used to trace a single request at a level.
+func WithRequestLevel(ctx context.Context, level Level) context.Context {
+	return withScopedLevel(context.WithValue(ctx, requestLevelKey{}, level), level)
+}
+
+// WithRouteLevel returns the given context with a logger that logs at the level of the route of
+// a request, if the LevelController of the context sets one other than the configured level.
+func WithRouteLevel(ctx context.Context, route string) context.Context {
+	c := GetLevelController(ctx)
+	if c == nil {
+		return ctx
+	}
+	if level := c.Level("", route); level != c.ConfiguredLevel() {
+		return withScopedLevel(ctx, level)
+	}
+	return ctx
+}
+
+// withScopedLevel returns the given context with a logger that logs at the given level, leaving
+// the level of the logger it is derived from as it is.
+func withScopedLevel(ctx context.Context, level Level) context.Context {
+	logger := GetLogger(ctx)
+	if l, ok := logger.(*logrusLogger); ok {
+		return PutLogger(ctx, l.withOwnLevel(level))
+	}
+	return PutLogger(ctx, logger.WithLevel(level))
+}
+
+// withLoggerLevel returns the given context with the level of the logger of the context named
+// name, if the LevelController of the context sets one and the request level does not apply.
+func withLoggerLevel(ctx context.Context, name string) context.Context {
+	c := GetLevelController(ctx)
+	if c == nil {
+		return ctx
+	}
+	if _, forced := ctx.Value(requestLevelKey{}).(Level); forced {
+		return ctx
+	}
+	if level, has := c.loggerLevel(name); has {
+		return withScopedLevel(ctx, level)
+	}
+	return ctx
+}
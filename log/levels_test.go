@@ -0,0 +1,162 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	zero "github.com/anz-bank/pkg/logging"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevel(t *testing.T) {
+	t.Parallel()
+
+	for _, level := range []Level{ErrorLevel, InfoLevel, DebugLevel} {
+		parsed, err := ParseLevel(level.String())
+		require.NoError(t, err)
+		require.Equal(t, level, parsed)
+	}
+	_, err := ParseLevel("trace")
+	require.EqualError(t, err, `unknown log level "trace", expected one of error, info, debug`)
+}
+
+func TestLevelControllerLevel(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	c := NewLevelController(InfoLevel, false)
+	c.now = func() time.Time { return now }
+	require.Equal(t, InfoLevel, c.Level("db", "/accounts/{id}"))
+
+	c.SetLevel(LevelScope{}, ErrorLevel, 0)
+	c.SetLevel(LevelScope{Route: "/accounts/{id}"}, DebugLevel, time.Minute)
+	c.SetLevel(LevelScope{Logger: "db"}, InfoLevel, 2*time.Minute)
+	require.Equal(t, InfoLevel, c.Level("db", "/accounts/{id}"))
+	require.Equal(t, DebugLevel, c.Level("cache", "/accounts/{id}"))
+	require.Equal(t, ErrorLevel, c.Level("cache", "/health"))
+	require.Equal(t, []LevelOverride{
+		{LevelScope: LevelScope{}, Level: ErrorLevel},
+		{LevelScope: LevelScope{Route: "/accounts/{id}"}, Level: DebugLevel, Expires: now.Add(time.Minute)},
+		{LevelScope: LevelScope{Logger: "db"}, Level: InfoLevel, Expires: now.Add(2 * time.Minute)},
+	}, c.Overrides())
+
+	// the levels revert after their time to live
+	now = now.Add(time.Minute)
+	require.Equal(t, ErrorLevel, c.Level("cache", "/accounts/{id}"))
+	require.Equal(t, InfoLevel, c.Level("db", "/accounts/{id}"))
+	now = now.Add(time.Minute)
+	require.Equal(t, ErrorLevel, c.Level("db", "/accounts/{id}"))
+
+	c.ResetLevel(LevelScope{})
+	require.Equal(t, InfoLevel, c.Level("db", "/accounts/{id}"))
+	require.Empty(t, c.Overrides())
+}
+
+func TestLevelControllerLogPayload(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	c := NewLevelController(InfoLevel, false)
+	c.now = func() time.Time { return now }
+	require.False(t, c.LogPayload())
+
+	c.SetLogPayload(true, time.Minute)
+	require.True(t, c.LogPayload())
+	now = now.Add(time.Minute)
+	require.False(t, c.LogPayload())
+
+	c.SetLogPayload(true, 0)
+	require.True(t, c.LogPayload())
+	c.ResetLogPayload()
+	require.False(t, c.LogPayload())
+}
+
+func TestNamedLoggerLevel(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	c := NewLevelController(InfoLevel, false)
+	c.SetLevel(LevelScope{Logger: "db"}, DebugLevel, 0)
+	ctx := WithLevelController(PutLogger(context.Background(), NewZeroPkgLogger(zero.New(buf)).WithLevel(InfoLevel)), c)
+
+	Debug(WithStr(ctx, LoggerField, "cache"), "cache debug")
+	require.NotContains(t, buf.String(), "cache debug")
+	Debug(WithStr(ctx, LoggerField, "db"), "db debug")
+	require.Contains(t, buf.String(), "db debug")
+
+	// the level of a request applies to the named loggers of the request
+	reqCtx := WithRequestLevel(ctx, ErrorLevel)
+	Info(WithStr(reqCtx, LoggerField, "db"), "db info")
+	require.NotContains(t, buf.String(), "db info")
+	Debug(WithStr(WithRequestLevel(ctx, DebugLevel), LoggerField, "cache"), "traced debug")
+	require.Contains(t, buf.String(), "traced debug")
+}
+
+func TestScopedLevelsKeepTheLogrusLevel(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	lrs := logrus.New()
+	lrs.Out = buf
+	c := NewLevelController(InfoLevel, false)
+	c.SetLevel(LevelScope{Route: "/accounts/{id}"}, DebugLevel, 0)
+	c.SetLevel(LevelScope{Logger: "db"}, DebugLevel, 0)
+	ctx := WithLevelController(WithLevel(PutLogger(context.Background(), NewLogrusLogger(lrs)), InfoLevel), c)
+
+	Debug(WithRequestLevel(ctx, DebugLevel), "request debug")
+	Debug(WithRouteLevel(ctx, "/accounts/{id}"), "route debug")
+	Debug(WithStr(ctx, LoggerField, "db"), "db debug")
+	Debug(ctx, "other debug")
+	require.Contains(t, buf.String(), "request debug")
+	require.Contains(t, buf.String(), "route debug")
+	require.Contains(t, buf.String(), "db debug")
+	require.NotContains(t, buf.String(), "other debug")
+	require.Equal(t, logrus.InfoLevel, lrs.GetLevel())
+
+	// the configured level is applied without copying the logger
+	require.Same(t, GetLogger(ctx), GetLogger(WithRouteLevel(ctx, "/health")))
+}
+
+// exclusiveWriter fails the writes that overlap with another one.
+type exclusiveWriter struct {
+	writing atomic.Bool
+	overlap atomic.Bool
+}
+
+func (w *exclusiveWriter) Write(p []byte) (int, error) {
+	if !w.writing.CompareAndSwap(false, true) {
+		w.overlap.Store(true)
+		return len(p), nil
+	}
+	time.Sleep(time.Microsecond)
+	w.writing.Store(false)
+	return len(p), nil
+}
+
+func TestScopedLevelsSerialiseTheLogrusWrites(t *testing.T) {
+	t.Parallel()
+
+	out := &exclusiveWriter{}
+	lrs := logrus.New()
+	lrs.Out = out
+	ctx := WithLevel(PutLogger(context.Background(), NewLogrusLogger(lrs)), InfoLevel)
+	scoped := WithRequestLevel(ctx, DebugLevel)
+
+	var wg sync.WaitGroup
+	for _, ctx := range []context.Context{ctx, scoped, WithRequestLevel(ctx, DebugLevel)} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				Info(ctx, "concurrent")
+			}
+		}()
+	}
+	wg.Wait()
+	require.False(t, out.overlap.Load())
+}
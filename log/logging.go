@@ -3,6 +3,8 @@ package log
 import (
 	"context"
 	"fmt"
+	"io"
+	"sync"
 	"time"
 
 	zero "github.com/anz-bank/pkg/logging"
@@ -91,8 +93,13 @@ func Debugf(ctx context.Context, format string, args ...interface{}) {
 }
 
 // WithStr returns the given context with a logger that persists the given key/value.
+// The LoggerField names the logger, which then logs at the level of its name, see LevelController.
 func WithStr(ctx context.Context, key string, value string) context.Context {
-	return PutLogger(ctx, GetLogger(ctx).WithStr(key, value))
+	ctx = PutLogger(ctx, GetLogger(ctx).WithStr(key, value))
+	if key == LoggerField {
+		ctx = withLoggerLevel(ctx, value)
+	}
+	return ctx
 }
 
 // WithInt returns the given context with a logger that persists the given key/value.
@@ -247,6 +254,55 @@ func (l *logrusLogger) withField(key string, value interface{}) Logger {
 func (l *logrusLogger) WithLevel(level Level) Logger {
 	// Note: This method returns the same logger instance because the logrus logger mutates
 	// the logger instance itself when setting the log level.
+	l.logger.SetLevel(logrusLevel(level))
+	return l
+}
+
+// withOwnLevel returns a logger that logs at the given level with a copy of the Logrus logger,
+// which writes to the same output with the same formatter and hooks. The copy does not share the
+// lock of the logger, so the output of both is wrapped in a syncWriter that serialises the writes.
+func (l *logrusLogger) withOwnLevel(level Level) Logger {
+	logger := &logrus.Logger{
+		Out:          syncOutput(l.logger),
+		Hooks:        l.logger.Hooks,
+		Formatter:    l.logger.Formatter,
+		ReportCaller: l.logger.ReportCaller,
+		Level:        logrusLevel(level),
+		ExitFunc:     l.logger.ExitFunc,
+		BufferPool:   l.logger.BufferPool,
+	}
+	return &logrusLogger{logger, l.fields}
+}
+
+// syncWriter serialises the writes of a Logrus logger and of its copies with their own levels.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (w *syncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.w.Write(p)
+}
+
+// syncOutputs guards the replacement of the outputs of Logrus loggers by syncOutput.
+var syncOutputs sync.Mutex
+
+// syncOutput returns the output of the Logrus logger after wrapping it in a syncWriter, unless
+// it already is one.
+func syncOutput(logger *logrus.Logger) io.Writer {
+	syncOutputs.Lock()
+	defer syncOutputs.Unlock()
+	if w, ok := logger.Out.(*syncWriter); ok {
+		return w
+	}
+	w := &syncWriter{w: logger.Out}
+	logger.SetOutput(w)
+	return w
+}
+
+func logrusLevel(level Level) logrus.Level {
 	var lvl logrus.Level
 	switch level {
 	case ErrorLevel:
@@ -256,8 +312,7 @@ func (l *logrusLogger) WithLevel(level Level) Logger {
 	case DebugLevel:
 		lvl = logrus.DebugLevel
 	}
-	l.logger.SetLevel(lvl)
-	return l
+	return lvl
 }
 
 func (l *logrusLogger) Inject(ctx context.Context) (context.Context, func(ctx context.Context) Logger) {
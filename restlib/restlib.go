@@ -121,6 +121,10 @@ type HTTPRequest struct {
 	Required          []string
 	Responses         func(int) any
 	ExtraHeaders      map[string][]string
+
+	// HeaderPolicy decides which incoming headers are sent with the request.
+	// When nil, all incoming headers are sent.
+	HeaderPolicy *common.HeaderPolicy
 }
 
 // DoHTTPRequest returns HTTPResult.
@@ -128,8 +132,13 @@ type HTTPRequest struct {
 //nolint:funlen // TODO: Refactor this function to be shorter.
 func DoHTTPRequest(ctx context.Context, config *HTTPRequest) (*HTTPResult, error) {
 	var reader io.Reader
-	headers := common.RequestHeaderFromContext(ctx)
-	contentType := headers.Get("Content-Type")
+	incoming := common.RequestHeaderFromContext(ctx)
+	contentType := incoming.Get("Content-Type")
+	headers := config.HeaderPolicy.Apply(ctx, incoming)
+	if contentType != "" && headers.Get("Content-Type") == "" {
+		// The content type describes the body we send, so it is kept regardless of the policy.
+		headers.Set("Content-Type", contentType)
+	}
 
 	// Validations 1:
 	// If we have body, marshal it based on the Content-Type of the request.
@@ -157,6 +166,22 @@ func DoHTTPRequest(ctx context.Context, config *HTTPRequest) (*HTTPResult, error
 		}
 	}
 
+	// The client gives up after its timeout (the ClientTimeout of the downstream), so the call is
+	// given the smaller of the remaining time budget and that timeout.
+	if config.Client != nil && config.Client.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Client.Timeout)
+		defer cancel()
+	}
+
+	// Send the remaining time budget onwards so the downstream can stop work we no longer wait for.
+	if name, value, ok := common.OutgoingRequestTimeoutHeader(ctx); ok {
+		if headers == nil {
+			headers = make(http.Header)
+		}
+		headers.Set(name, value)
+	}
+
 	httpRequest, err := http.NewRequestWithContext(ctx, config.Method, config.URLString, reader)
 	if err != nil {
 		return nil, err
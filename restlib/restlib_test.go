@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/anz-bank/sysl-go/common"
+	"github.com/anz-bank/sysl-go/config"
 	"github.com/stretchr/testify/require"
 )
 
@@ -53,6 +54,7 @@ func testDoHTTPRequest(ctx context.Context,
 			}
 		},
 		nil,
+		nil,
 	})
 }
 
@@ -177,6 +179,86 @@ func TestDoHTTPRequestOkType(t *testing.T) {
 	require.IsType(t, &OkType{}, result.Response)
 }
 
+func TestDoHTTPRequestPropagatesRequestTimeout(t *testing.T) {
+	var received string
+	srv := common.NewHTTPTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("X-Request-Timeout")
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(okJSON))
+	}))
+	defer srv.Close()
+
+	cfg := config.DefaultConfig{}
+	cfg.Library.Deadline.Header = "X-Request-Timeout"
+	ctx, cancel := context.WithTimeout(config.PutDefaultConfig(context.Background(), &cfg), time.Second)
+	defer cancel()
+
+	_, err := testDoHTTPRequest(ctx, srv.Client(), "GET", srv.URL, nil, make([]string, 0), &OkType{}, &ErrorType{})
+	require.NoError(t, err)
+	budget, err := common.ParseRequestTimeout(received)
+	require.NoError(t, err)
+	require.LessOrEqual(t, budget, time.Second)
+	require.Greater(t, budget, time.Duration(0))
+}
+
+func TestDoHTTPRequestLimitsRequestTimeoutToClientTimeout(t *testing.T) {
+	var received string
+	srv := common.NewHTTPTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get("X-Request-Timeout")
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(okJSON))
+	}))
+	defer srv.Close()
+
+	cfg := config.DefaultConfig{}
+	cfg.Library.Deadline.Header = "X-Request-Timeout"
+	ctx := config.PutDefaultConfig(context.Background(), &cfg)
+	client := srv.Client()
+	client.Timeout = 200 * time.Millisecond
+
+	for _, timeout := range []time.Duration{time.Minute, 0} {
+		ctx, cancel := ctx, context.CancelFunc(func() {})
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		_, err := testDoHTTPRequest(ctx, client, "GET", srv.URL, nil, make([]string, 0), &OkType{}, &ErrorType{})
+		cancel()
+		require.NoError(t, err)
+		budget, err := common.ParseRequestTimeout(received)
+		require.NoError(t, err)
+		require.LessOrEqual(t, budget, 200*time.Millisecond)
+		require.Greater(t, budget, time.Duration(0))
+	}
+}
+
+func TestDoHTTPRequestAppliesHeaderPolicy(t *testing.T) {
+	var received http.Header
+	srv := common.NewHTTPTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(okJSON))
+	}))
+	defer srv.Close()
+
+	policy, err := common.NewHeaderPolicy(&config.HeaderPropagationConfig{Deny: []string{"Authorization"}})
+	require.NoError(t, err)
+	ctx := common.RequestHeaderToContext(context.Background(), http.Header{
+		"Authorization": {"Bearer abc"},
+		"X-Tenant":      {"acme"},
+	})
+
+	_, err = DoHTTPRequest(ctx, &HTTPRequest{
+		Client:       srv.Client(),
+		Method:       "GET",
+		URLString:    srv.URL,
+		Responses:    func(int) any { return &OkType{} },
+		HeaderPolicy: policy,
+	})
+	require.NoError(t, err)
+	require.Empty(t, received.Get("Authorization"))
+	require.Equal(t, "acme", received.Get("X-Tenant"))
+}
+
 func TestDoHTTPRequest204Response(t *testing.T) {
 	srv := common.NewHTTPTestServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(204)
@@ -29,17 +29,22 @@ type BuildMetadata struct {
 	CommitSha  string `json:"commit_sha"`
 	BranchName string `json:"branch_name"`
 	TagName    string `json:"tag_name"`
+	GoVersion  string `json:"go_version"`
+	// Dirty is whether the build had uncommitted changes to the commit.
+	Dirty bool `json:"dirty,omitempty"`
 }
 
 func (m *BuildMetadata) String() string {
 	return fmt.Sprintf(
-		"%s version=%s build=%s commit=%s branch=%s tag=%s",
+		"%s version=%s build=%s commit=%s branch=%s tag=%s go=%s dirty=%t",
 		m.Name,
 		m.Version,
 		m.BuildID,
 		m.CommitSha,
 		m.BranchName,
 		m.TagName,
+		m.GoVersion,
+		m.Dirty,
 	)
 }
 
@@ -0,0 +1,560 @@
+package temporal_tester
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+	"unsafe"
+
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	failurepb "go.temporal.io/api/failure/v1"
+	historypb "go.temporal.io/api/history/v1"
+	taskqueuepb "go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// workflowTaskTimeout is the start to close timeout of the recorded workflow tasks.
+const workflowTaskTimeout = 10 * time.Second
+
+// historyRecorder builds the event history of the workflow executed by a TestWorkflowEnvironment,
+// which does not keep one, as a Temporal server would write it.
+//
+// The environment runs the workflow after each activity result, fired timer and signal, so each
+// of them is followed by a workflow task of its own. Only activities, timers and signals are
+// recorded, which are the commands of the generated workflows; using anything else makes the
+// recording fail rather than write a history that cannot be replayed.
+type historyRecorder struct {
+	interceptor.WorkerInterceptorBase
+
+	env *testsuite.TestWorkflowEnvironment
+	dc  converter.DataConverter
+
+	mu         sync.Mutex
+	workflowID string
+	events     []*historypb.HistoryEvent
+	// commands are the events of the commands of the current workflow task.
+	commands []*historypb.HistoryEvent
+	// taskStarted is the ID of the started event of the current workflow task, 0 if none.
+	taskStarted int64
+	taskQueue   string
+	activities  map[string]int64 // the scheduled event IDs by activity ID
+	timers      map[string]int64 // the started event IDs by timer ID of the environment
+	err         error
+}
+
+// newHistoryRecorder returns a recorder of the history of the workflow executed by env. It wraps
+// the data converter, the worker interceptors and the activity and timer listeners of env, which
+// keep working. A listener set on env afterwards replaces the recording one.
+func newHistoryRecorder(env *testsuite.TestWorkflowEnvironment) *historyRecorder {
+	dc := envField[converter.DataConverter](env, "dataConverter")
+	if dc == nil {
+		dc = converter.GetDefaultDataConverter()
+	}
+	r := &historyRecorder{
+		env:        env,
+		dc:         rawDataConverter{dc},
+		activities: map[string]int64{},
+		timers:     map[string]int64{},
+	}
+	env.SetDataConverter(r.dc)
+	options := envField[worker.Options](env, "workerOptions")
+	options.Interceptors = append(options.Interceptors[:len(options.Interceptors):len(options.Interceptors)], r)
+	env.SetWorkerOptions(options)
+
+	activityCompleted := envField[func(*activity.Info, converter.EncodedValue, error)](env, "onActivityCompletedListener")
+	env.SetOnActivityCompletedListener(func(info *activity.Info, result converter.EncodedValue, err error) {
+		r.activityCompleted(info, result, err)
+		if activityCompleted != nil {
+			activityCompleted(info, result, err)
+		}
+	})
+	activityCanceled := envField[func(*activity.Info)](env, "onActivityCanceledListener")
+	env.SetOnActivityCanceledListener(func(info *activity.Info) {
+		r.unsupported("activity cancellation")
+		if activityCanceled != nil {
+			activityCanceled(info)
+		}
+	})
+	timerScheduled := envField[func(string, time.Duration)](env, "onTimerScheduledListener")
+	env.SetOnTimerScheduledListener(func(timerID string, d time.Duration) {
+		r.timerStarted(timerID, d)
+		if timerScheduled != nil {
+			timerScheduled(timerID, d)
+		}
+	})
+	timerFired := envField[func(string)](env, "onTimerFiredListener")
+	env.SetOnTimerFiredListener(func(timerID string) {
+		r.timerFired(timerID)
+		if timerFired != nil {
+			timerFired(timerID)
+		}
+	})
+	timerCanceled := envField[func(string)](env, "onTimerCanceledListener")
+	env.SetOnTimerCanceledListener(func(timerID string) {
+		r.unsupported("timer cancellation")
+		if timerCanceled != nil {
+			timerCanceled(timerID)
+		}
+	})
+	return r
+}
+
+// envField returns the field of the given name of the implementation of env, or the zero value
+// if it has no field of that name and type. TestWorkflowEnvironment has no getters for what the
+// recorder wraps.
+func envField[T any](env *testsuite.TestWorkflowEnvironment, name string) T {
+	var value T
+	impl := reflect.ValueOf(env).Elem().FieldByName("impl")
+	if impl.Kind() != reflect.Ptr || impl.IsNil() {
+		return value
+	}
+	f := impl.Elem().FieldByName(name)
+	if !f.IsValid() || f.Type() != reflect.TypeOf(&value).Elem() {
+		return value
+	}
+	return reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem().Interface().(T)
+}
+
+// history returns the events recorded so far for the workflow with the given ID, or for the
+// executed workflow if workflowID is empty.
+func (r *historyRecorder) history(workflowID string) (*historypb.History, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch {
+	case r.err != nil:
+		return nil, r.err
+	case r.workflowID == "":
+		return nil, errors.New("no workflow has been executed")
+	case workflowID != "" && workflowID != r.workflowID:
+		return nil, fmt.Errorf("workflow %s has not been executed", workflowID)
+	}
+	return &historypb.History{Events: append([]*historypb.HistoryEvent(nil), r.events...)}, nil
+}
+
+func (r *historyRecorder) unsupported(what string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fail(fmt.Errorf("recording the history of workflows that use %s is not supported", what))
+}
+
+func (r *historyRecorder) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// event appends an event of the given type.
+func (r *historyRecorder) event(eventType enumspb.EventType) *historypb.HistoryEvent {
+	e := &historypb.HistoryEvent{
+		EventId:   int64(len(r.events) + 1),
+		EventTime: timestamppb.New(r.env.Now()),
+		EventType: eventType,
+	}
+	r.events = append(r.events, e)
+	return e
+}
+
+// command adds the event of a command of the current workflow task. Its ID is the one that the
+// SDK predicts when it replays the task, which is also the ID it gives to activities and timers.
+func (r *historyRecorder) command(eventType enumspb.EventType) *historypb.HistoryEvent {
+	e := &historypb.HistoryEvent{
+		EventId:   r.taskStarted + 2 + int64(len(r.commands)),
+		EventTime: timestamppb.New(r.env.Now()),
+		EventType: eventType,
+	}
+	r.commands = append(r.commands, e)
+	return e
+}
+
+func (r *historyRecorder) startTask() {
+	scheduled := r.event(enumspb.EVENT_TYPE_WORKFLOW_TASK_SCHEDULED)
+	scheduled.Attributes = &historypb.HistoryEvent_WorkflowTaskScheduledEventAttributes{
+		WorkflowTaskScheduledEventAttributes: &historypb.WorkflowTaskScheduledEventAttributes{
+			TaskQueue:           &taskqueuepb.TaskQueue{Name: r.taskQueue},
+			StartToCloseTimeout: durationpb.New(workflowTaskTimeout),
+			Attempt:             1,
+		},
+	}
+	started := r.event(enumspb.EVENT_TYPE_WORKFLOW_TASK_STARTED)
+	started.Attributes = &historypb.HistoryEvent_WorkflowTaskStartedEventAttributes{
+		WorkflowTaskStartedEventAttributes: &historypb.WorkflowTaskStartedEventAttributes{
+			ScheduledEventId: scheduled.EventId,
+		},
+	}
+	r.taskStarted = started.EventId
+}
+
+// completeTask completes the current workflow task with its commands and returns the ID of its
+// completed event.
+func (r *historyRecorder) completeTask() int64 {
+	if r.taskStarted == 0 {
+		return 0
+	}
+	completed := r.event(enumspb.EVENT_TYPE_WORKFLOW_TASK_COMPLETED)
+	completed.Attributes = &historypb.HistoryEvent_WorkflowTaskCompletedEventAttributes{
+		WorkflowTaskCompletedEventAttributes: &historypb.WorkflowTaskCompletedEventAttributes{
+			ScheduledEventId: r.taskStarted - 1,
+			StartedEventId:   r.taskStarted,
+		},
+	}
+	r.events = append(r.events, r.commands...)
+	r.commands = nil
+	r.taskStarted = 0
+	return completed.EventId
+}
+
+func (r *historyRecorder) workflowStarted(info *workflow.Info, args []interface{}) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.workflowID != "" {
+		// a child workflow, which fails the recording when it is started
+		return false
+	}
+	r.workflowID = info.WorkflowExecution.ID
+	r.taskQueue = info.TaskQueueName
+	input, err := r.dc.ToPayloads(args...)
+	if err != nil {
+		r.fail(err)
+	}
+	started := r.event(enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED)
+	started.Attributes = &historypb.HistoryEvent_WorkflowExecutionStartedEventAttributes{
+		WorkflowExecutionStartedEventAttributes: &historypb.WorkflowExecutionStartedEventAttributes{
+			WorkflowType:           &commonpb.WorkflowType{Name: info.WorkflowType.Name},
+			TaskQueue:              &taskqueuepb.TaskQueue{Name: r.taskQueue},
+			Input:                  input,
+			WorkflowRunTimeout:     durationpb.New(info.WorkflowRunTimeout),
+			WorkflowTaskTimeout:    durationpb.New(workflowTaskTimeout),
+			OriginalExecutionRunId: info.WorkflowExecution.RunID,
+			FirstExecutionRunId:    info.WorkflowExecution.RunID,
+			Attempt:                1,
+		},
+	}
+	r.startTask()
+	return true
+}
+
+func (r *historyRecorder) workflowClosed(result interface{}, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var canceled *temporal.CanceledError
+	if workflow.IsContinueAsNewError(err) || errors.As(err, &canceled) {
+		r.fail(fmt.Errorf("recording the history of workflows that end with %v is not supported", err))
+		return
+	}
+	taskCompleted := r.completeTask()
+	if err != nil {
+		failed := r.event(enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED)
+		failed.Attributes = &historypb.HistoryEvent_WorkflowExecutionFailedEventAttributes{
+			WorkflowExecutionFailedEventAttributes: &historypb.WorkflowExecutionFailedEventAttributes{
+				Failure:                      r.failure(err),
+				RetryState:                   enumspb.RETRY_STATE_RETRY_POLICY_NOT_SET,
+				WorkflowTaskCompletedEventId: taskCompleted,
+			},
+		}
+		return
+	}
+	var payloads *commonpb.Payloads
+	if result != nil {
+		if payloads, err = r.dc.ToPayloads(result); err != nil {
+			r.fail(err)
+		}
+	}
+	completed := r.event(enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED)
+	completed.Attributes = &historypb.HistoryEvent_WorkflowExecutionCompletedEventAttributes{
+		WorkflowExecutionCompletedEventAttributes: &historypb.WorkflowExecutionCompletedEventAttributes{
+			Result:                       payloads,
+			WorkflowTaskCompletedEventId: taskCompleted,
+		},
+	}
+}
+
+func (r *historyRecorder) signaled(name string, input *commonpb.Payloads) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.completeTask()
+	signaled := r.event(enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_SIGNALED)
+	signaled.Attributes = &historypb.HistoryEvent_WorkflowExecutionSignaledEventAttributes{
+		WorkflowExecutionSignaledEventAttributes: &historypb.WorkflowExecutionSignaledEventAttributes{
+			SignalName: name,
+			Input:      input,
+		},
+	}
+	r.startTask()
+}
+
+// activityScheduled records the command of an activity and sets the ID that the SDK gives to
+// the activity when it is replayed, so that its result can be found by ID.
+func (r *historyRecorder) activityScheduled(activityType string, options *workflow.ActivityOptions, args []interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	input, err := r.dc.ToPayloads(args...)
+	if err != nil {
+		r.fail(err)
+	}
+	taskQueue := options.TaskQueue
+	if taskQueue == "" {
+		taskQueue = r.taskQueue
+	}
+	scheduled := r.command(enumspb.EVENT_TYPE_ACTIVITY_TASK_SCHEDULED)
+	if options.ActivityID == "" {
+		options.ActivityID = strconv.FormatInt(scheduled.EventId, 10)
+	}
+	scheduled.Attributes = &historypb.HistoryEvent_ActivityTaskScheduledEventAttributes{
+		ActivityTaskScheduledEventAttributes: &historypb.ActivityTaskScheduledEventAttributes{
+			ActivityId:                   options.ActivityID,
+			ActivityType:                 &commonpb.ActivityType{Name: activityType},
+			TaskQueue:                    &taskqueuepb.TaskQueue{Name: taskQueue},
+			Input:                        input,
+			ScheduleToCloseTimeout:       durationpb.New(options.ScheduleToCloseTimeout),
+			ScheduleToStartTimeout:       durationpb.New(options.ScheduleToStartTimeout),
+			StartToCloseTimeout:          durationpb.New(options.StartToCloseTimeout),
+			HeartbeatTimeout:             durationpb.New(options.HeartbeatTimeout),
+			WorkflowTaskCompletedEventId: r.taskStarted + 1,
+		},
+	}
+	r.activities[options.ActivityID] = scheduled.EventId
+}
+
+func (r *historyRecorder) activityCompleted(info *activity.Info, result converter.EncodedValue, err error) {
+	if errors.Is(err, activity.ErrResultPending) {
+		r.unsupported("asynchronous activity completion")
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	scheduledID, ok := r.activities[info.ActivityID]
+	if !ok {
+		return
+	}
+	delete(r.activities, info.ActivityID)
+	r.completeTask()
+	started := r.event(enumspb.EVENT_TYPE_ACTIVITY_TASK_STARTED)
+	started.Attributes = &historypb.HistoryEvent_ActivityTaskStartedEventAttributes{
+		ActivityTaskStartedEventAttributes: &historypb.ActivityTaskStartedEventAttributes{
+			ScheduledEventId: scheduledID,
+			Attempt:          info.Attempt,
+		},
+	}
+	if err != nil {
+		var activityErr *temporal.ActivityError
+		if errors.As(err, &activityErr) {
+			err = activityErr.Unwrap()
+		}
+		failed := r.event(enumspb.EVENT_TYPE_ACTIVITY_TASK_FAILED)
+		failed.Attributes = &historypb.HistoryEvent_ActivityTaskFailedEventAttributes{
+			ActivityTaskFailedEventAttributes: &historypb.ActivityTaskFailedEventAttributes{
+				Failure:          r.failure(err),
+				ScheduledEventId: scheduledID,
+				StartedEventId:   started.EventId,
+				RetryState:       enumspb.RETRY_STATE_NON_RETRYABLE_FAILURE,
+			},
+		}
+	} else {
+		var raw rawPayloads
+		if result != nil && result.HasValue() {
+			if err = result.Get(&raw); err != nil {
+				r.fail(err)
+			}
+		}
+		completed := r.event(enumspb.EVENT_TYPE_ACTIVITY_TASK_COMPLETED)
+		completed.Attributes = &historypb.HistoryEvent_ActivityTaskCompletedEventAttributes{
+			ActivityTaskCompletedEventAttributes: &historypb.ActivityTaskCompletedEventAttributes{
+				Result:           raw.payloads,
+				ScheduledEventId: scheduledID,
+				StartedEventId:   started.EventId,
+			},
+		}
+	}
+	r.startTask()
+}
+
+func (r *historyRecorder) timerStarted(timerID string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	started := r.command(enumspb.EVENT_TYPE_TIMER_STARTED)
+	started.Attributes = &historypb.HistoryEvent_TimerStartedEventAttributes{
+		TimerStartedEventAttributes: &historypb.TimerStartedEventAttributes{
+			TimerId:                      strconv.FormatInt(started.EventId, 10),
+			StartToFireTimeout:           durationpb.New(d),
+			WorkflowTaskCompletedEventId: r.taskStarted + 1,
+		},
+	}
+	r.timers[timerID] = started.EventId
+}
+
+func (r *historyRecorder) timerFired(timerID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	startedID, ok := r.timers[timerID]
+	if !ok {
+		return
+	}
+	delete(r.timers, timerID)
+	r.completeTask()
+	fired := r.event(enumspb.EVENT_TYPE_TIMER_FIRED)
+	fired.Attributes = &historypb.HistoryEvent_TimerFiredEventAttributes{
+		TimerFiredEventAttributes: &historypb.TimerFiredEventAttributes{
+			TimerId:        strconv.FormatInt(startedID, 10),
+			StartedEventId: startedID,
+		},
+	}
+	r.startTask()
+}
+
+func (r *historyRecorder) failure(err error) *failurepb.Failure {
+	return temporal.GetDefaultFailureConverter().ErrorToFailure(err)
+}
+
+func (r *historyRecorder) InterceptWorkflow(
+	ctx workflow.Context, next interceptor.WorkflowInboundInterceptor,
+) interceptor.WorkflowInboundInterceptor {
+	return &historyWorkflowInbound{WorkflowInboundInterceptorBase: interceptor.WorkflowInboundInterceptorBase{Next: next}, r: r}
+}
+
+type historyWorkflowInbound struct {
+	interceptor.WorkflowInboundInterceptorBase
+	r *historyRecorder
+}
+
+func (w *historyWorkflowInbound) Init(outbound interceptor.WorkflowOutboundInterceptor) error {
+	return w.Next.Init(&historyWorkflowOutbound{WorkflowOutboundInterceptorBase: interceptor.WorkflowOutboundInterceptorBase{Next: outbound}, r: w.r})
+}
+
+func (w *historyWorkflowInbound) ExecuteWorkflow(ctx workflow.Context, in *interceptor.ExecuteWorkflowInput) (interface{}, error) {
+	recorded := w.r.workflowStarted(workflow.GetInfo(ctx), in.Args)
+	result, err := w.Next.ExecuteWorkflow(ctx, in)
+	if recorded {
+		w.r.workflowClosed(result, err)
+	}
+	return result, err
+}
+
+func (w *historyWorkflowInbound) HandleSignal(ctx workflow.Context, in *interceptor.HandleSignalInput) error {
+	w.r.signaled(in.SignalName, in.Arg)
+	return w.Next.HandleSignal(ctx, in)
+}
+
+func (w *historyWorkflowInbound) ExecuteUpdate(ctx workflow.Context, in *interceptor.UpdateInput) (interface{}, error) {
+	w.r.unsupported("updates")
+	return w.Next.ExecuteUpdate(ctx, in)
+}
+
+type historyWorkflowOutbound struct {
+	interceptor.WorkflowOutboundInterceptorBase
+	r *historyRecorder
+}
+
+func (w *historyWorkflowOutbound) ExecuteActivity(ctx workflow.Context, activityType string, args ...interface{}) workflow.Future {
+	options := workflow.GetActivityOptions(ctx)
+	w.r.activityScheduled(activityType, &options, args)
+	return w.Next.ExecuteActivity(workflow.WithActivityOptions(ctx, options), activityType, args...)
+}
+
+func (w *historyWorkflowOutbound) ExecuteLocalActivity(ctx workflow.Context, activityType string, args ...interface{}) workflow.Future {
+	w.r.unsupported("local activities")
+	return w.Next.ExecuteLocalActivity(ctx, activityType, args...)
+}
+
+func (w *historyWorkflowOutbound) ExecuteChildWorkflow(ctx workflow.Context, childWorkflowType string, args ...interface{}) workflow.ChildWorkflowFuture {
+	w.r.unsupported("child workflows")
+	return w.Next.ExecuteChildWorkflow(ctx, childWorkflowType, args...)
+}
+
+func (w *historyWorkflowOutbound) SignalExternalWorkflow(ctx workflow.Context, workflowID, runID, signalName string, arg interface{}) workflow.Future {
+	w.r.unsupported("external signals")
+	return w.Next.SignalExternalWorkflow(ctx, workflowID, runID, signalName, arg)
+}
+
+func (w *historyWorkflowOutbound) RequestCancelExternalWorkflow(ctx workflow.Context, workflowID, runID string) workflow.Future {
+	w.r.unsupported("external cancellation")
+	return w.Next.RequestCancelExternalWorkflow(ctx, workflowID, runID)
+}
+
+func (w *historyWorkflowOutbound) SideEffect(ctx workflow.Context, f func(ctx workflow.Context) interface{}) converter.EncodedValue {
+	w.r.unsupported("side effects")
+	return w.Next.SideEffect(ctx, f)
+}
+
+func (w *historyWorkflowOutbound) MutableSideEffect(
+	ctx workflow.Context, id string, f func(ctx workflow.Context) interface{}, equals func(a, b interface{}) bool,
+) converter.EncodedValue {
+	w.r.unsupported("side effects")
+	return w.Next.MutableSideEffect(ctx, id, f, equals)
+}
+
+func (w *historyWorkflowOutbound) GetVersion(ctx workflow.Context, changeID string, minSupported, maxSupported workflow.Version) workflow.Version {
+	w.r.unsupported("versions")
+	return w.Next.GetVersion(ctx, changeID, minSupported, maxSupported)
+}
+
+func (w *historyWorkflowOutbound) UpsertSearchAttributes(ctx workflow.Context, attributes map[string]interface{}) error {
+	w.r.unsupported("search attributes")
+	return w.Next.UpsertSearchAttributes(ctx, attributes)
+}
+
+func (w *historyWorkflowOutbound) UpsertTypedSearchAttributes(ctx workflow.Context, attributes ...temporal.SearchAttributeUpdate) error {
+	w.r.unsupported("search attributes")
+	return w.Next.UpsertTypedSearchAttributes(ctx, attributes...)
+}
+
+func (w *historyWorkflowOutbound) UpsertMemo(ctx workflow.Context, memo map[string]interface{}) error {
+	w.r.unsupported("memos")
+	return w.Next.UpsertMemo(ctx, memo)
+}
+
+// rawPayloads receives the payloads decoded by rawDataConverter as they are.
+type rawPayloads struct {
+	payloads *commonpb.Payloads
+}
+
+// rawDataConverter is a data converter that can decode payloads into rawPayloads, which is how
+// the recorder reads the encoded results of activities.
+type rawDataConverter struct {
+	converter.DataConverter
+}
+
+func (c rawDataConverter) FromPayloads(payloads *commonpb.Payloads, valuePtrs ...interface{}) error {
+	if len(valuePtrs) == 1 {
+		if raw, ok := valuePtrs[0].(*rawPayloads); ok {
+			raw.payloads = payloads
+			return nil
+		}
+	}
+	return c.DataConverter.FromPayloads(payloads, valuePtrs...)
+}
+
+var _ client.HistoryEventIterator = &historyIterator{}
+
+// historyIterator iterates over the events of a recorded history.
+type historyIterator struct {
+	events []*historypb.HistoryEvent
+	err    error
+}
+
+func (it *historyIterator) HasNext() bool {
+	return it.err != nil || len(it.events) > 0
+}
+
+func (it *historyIterator) Next() (*historypb.HistoryEvent, error) {
+	if it.err != nil {
+		err := it.err
+		it.err = nil
+		return nil, err
+	}
+	event := it.events[0]
+	it.events = it.events[1:]
+	return event, nil
+}
@@ -0,0 +1,159 @@
+package temporal_tester
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+)
+
+func orderWorkflow(ctx workflow.Context, order string) (string, error) {
+	return order, runOrder(ctx, order, false)
+}
+
+func changedOrderWorkflow(ctx workflow.Context, order string) (string, error) {
+	return order, runOrder(ctx, order, true)
+}
+
+func runOrder(ctx workflow.Context, order string, notifyFirst bool) error {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: time.Minute,
+		RetryPolicy:         &temporal.RetryPolicy{MaximumAttempts: 1},
+	})
+	var reservation string
+	if err := workflow.ExecuteActivity(ctx, "Reserve", order).Get(ctx, &reservation); err != nil {
+		return err
+	}
+	names := []string{"Charge", "Notify"}
+	if notifyFirst {
+		names = []string{"Notify", "Charge"}
+	}
+	var futures []workflow.Future
+	for _, name := range names {
+		futures = append(futures, workflow.ExecuteActivity(ctx, name, reservation))
+	}
+	for _, f := range futures {
+		// notifications fail without failing the order
+		_ = f.Get(ctx, nil)
+	}
+	if err := workflow.Sleep(ctx, time.Hour); err != nil {
+		return err
+	}
+	var approval string
+	workflow.GetSignalChannel(ctx, "approve").Receive(ctx, &approval)
+	if approval != "yes" {
+		return temporal.NewApplicationError("order is not approved", "NotApproved")
+	}
+	return nil
+}
+
+func executeOrder(t *testing.T, approval string) *MockClient {
+	env := (&testsuite.WorkflowTestSuite{}).NewTestWorkflowEnvironment()
+	env.RegisterWorkflowWithOptions(orderWorkflow, workflow.RegisterOptions{Name: "Order"})
+	env.RegisterActivityWithOptions(func(_ context.Context, order string) (string, error) {
+		return "reservation of " + order, nil
+	}, activity.RegisterOptions{Name: "Reserve"})
+	env.RegisterActivityWithOptions(func(context.Context, string) error { return nil }, activity.RegisterOptions{Name: "Charge"})
+	env.RegisterActivityWithOptions(func(context.Context, string) error {
+		return errors.New("no email address")
+	}, activity.RegisterOptions{Name: "Notify"})
+
+	c := NewTemporalMockClientWithHistory(env).(*MockClient)
+	env.RegisterDelayedCallback(func() {
+		require.NoError(t, c.SignalWorkflow(context.Background(), "order", "", "approve", approval))
+	}, 2*time.Hour)
+	_, err := c.ExecuteWorkflow(context.Background(), client.StartWorkflowOptions{ID: "order", TaskQueue: "orders"}, "Order", "o1")
+	require.NoError(t, err)
+	require.True(t, env.IsWorkflowCompleted())
+	return c
+}
+
+func TestMockClientRecordsHistory(t *testing.T) {
+	c := executeOrder(t, "yes")
+	require.NoError(t, c.Env.GetWorkflowError())
+
+	path := filepath.Join(t.TempDir(), "order.json")
+	RecordGoldenHistory(context.Background(), t, c, "order", "", path)
+	history, err := GetWorkflowHistory(context.Background(), c, "order", "")
+	require.NoError(t, err)
+	var types []enumspb.EventType
+	for i, event := range history.Events {
+		require.Equal(t, int64(i+1), event.EventId)
+		types = append(types, event.EventType)
+	}
+	require.Contains(t, types, enumspb.EVENT_TYPE_ACTIVITY_TASK_FAILED)
+	require.Contains(t, types, enumspb.EVENT_TYPE_TIMER_FIRED)
+	require.Contains(t, types, enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_SIGNALED)
+	require.Equal(t, enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED, types[len(types)-1])
+
+	rw := NewReplayWorker()
+	rw.RegisterWorkflowWithOptions(orderWorkflow, workflow.RegisterOptions{Name: "Order"})
+	rw.ReplayHistoryFile(t, path)
+
+	changed := NewReplayWorker()
+	changed.RegisterWorkflowWithOptions(changedOrderWorkflow, workflow.RegisterOptions{Name: "Order"})
+	require.Error(t, changed.GetReplayer().ReplayWorkflowHistoryFromJSONFile(nil, path))
+}
+
+func TestMockClientRecordsFailedWorkflow(t *testing.T) {
+	c := executeOrder(t, "no")
+	require.Error(t, c.Env.GetWorkflowError())
+
+	path := filepath.Join(t.TempDir(), "order.json")
+	require.NoError(t, RecordWorkflowHistory(context.Background(), c, "order", "", path))
+	rw := NewReplayWorker()
+	rw.RegisterWorkflowWithOptions(orderWorkflow, workflow.RegisterOptions{Name: "Order"})
+	rw.ReplayHistoryFile(t, path)
+}
+
+func TestMockClientDoesNotRecordUnsupportedCommands(t *testing.T) {
+	env := (&testsuite.WorkflowTestSuite{}).NewTestWorkflowEnvironment()
+	env.RegisterWorkflowWithOptions(func(ctx workflow.Context) error {
+		workflow.SideEffect(ctx, func(workflow.Context) interface{} { return 1 })
+		return nil
+	}, workflow.RegisterOptions{Name: "SideEffect"})
+	c := NewTemporalMockClientWithHistory(env)
+	_, err := c.ExecuteWorkflow(context.Background(), client.StartWorkflowOptions{ID: "wf"}, "SideEffect")
+	require.NoError(t, err)
+
+	_, err = GetWorkflowHistory(context.Background(), c, "wf", "")
+	require.ErrorContains(t, err, "side effects is not supported")
+	_, err = GetWorkflowHistory(context.Background(), &MockClient{Env: env}, "wf", "")
+	require.Error(t, err)
+}
+
+func TestMockClientRecordsHistoryOnlyWhenAsked(t *testing.T) {
+	env := (&testsuite.WorkflowTestSuite{}).NewTestWorkflowEnvironment()
+	env.RegisterWorkflowWithOptions(orderWorkflow, workflow.RegisterOptions{Name: "Order"})
+	env.RegisterActivityWithOptions(func(_ context.Context, order string) (string, error) {
+		return "", errors.New("out of stock")
+	}, activity.RegisterOptions{Name: "Reserve"})
+	var completed []string
+	env.SetOnActivityCompletedListener(func(info *activity.Info, _ converter.EncodedValue, err error) {
+		completed = append(completed, info.ActivityType.Name)
+	})
+
+	c := NewTemporalMockClient(env).(*MockClient)
+	_, err := GetWorkflowHistory(context.Background(), c, "order", "")
+	require.ErrorContains(t, err, "RecordHistory")
+
+	// the listener set before the recording keeps being called
+	c.RecordHistory()
+	_, err = c.ExecuteWorkflow(context.Background(), client.StartWorkflowOptions{ID: "order"}, "Order", "o1")
+	require.NoError(t, err)
+	require.Error(t, env.GetWorkflowError())
+	require.Equal(t, []string{"Reserve"}, completed)
+	history, err := GetWorkflowHistory(context.Background(), c, "order", "")
+	require.NoError(t, err)
+	require.Equal(t, enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED, history.Events[len(history.Events)-1].EventType)
+}
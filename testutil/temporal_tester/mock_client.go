@@ -2,16 +2,21 @@ package temporal_tester
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/assert"
+	commonpb "go.temporal.io/api/common/v1"
 	enumspb "go.temporal.io/api/enums/v1"
 	"go.temporal.io/api/operatorservice/v1"
+	"go.temporal.io/api/serviceerror"
+	workflowpb "go.temporal.io/api/workflow/v1"
 	"go.temporal.io/api/workflowservice/v1"
 	"go.temporal.io/sdk/activity"
 	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/testsuite"
 	"go.temporal.io/sdk/workflow"
 
@@ -104,8 +109,9 @@ func mockWithoutResponse[Context, Resp any](m *MockBase[Resp]) func(ctx Context,
 }
 
 type MockWorkflowRun struct {
-	Env *testsuite.TestWorkflowEnvironment
-	ID  string
+	Env   *testsuite.TestWorkflowEnvironment
+	ID    string
+	RunID string
 }
 
 func (m *MockWorkflowRun) GetID() string {
@@ -113,7 +119,7 @@ func (m *MockWorkflowRun) GetID() string {
 }
 
 func (m *MockWorkflowRun) GetRunID() string {
-	return ""
+	return m.RunID
 }
 
 func (m *MockWorkflowRun) Get(ctx context.Context, valuePtr interface{}) error {
@@ -125,7 +131,47 @@ func (m *MockWorkflowRun) GetWithOptions(ctx context.Context, valuePtr interface
 }
 
 type MockClient struct {
-	Env *testsuite.TestWorkflowEnvironment
+	Env     *testsuite.TestWorkflowEnvironment
+	run     *MockWorkflowRun
+	updates map[string]*MockWorkflowUpdateHandle
+	history *historyRecorder
+}
+
+var _ client.WorkflowUpdateHandle = &MockWorkflowUpdateHandle{}
+
+// MockWorkflowUpdateHandle holds the outcome of an update sent through MockClient.
+type MockWorkflowUpdateHandle struct {
+	workflowID string
+	runID      string
+	updateID   string
+	completed  bool
+	result     interface{}
+	err        error
+}
+
+func (h *MockWorkflowUpdateHandle) WorkflowID() string { return h.workflowID }
+func (h *MockWorkflowUpdateHandle) RunID() string      { return h.runID }
+func (h *MockWorkflowUpdateHandle) UpdateID() string   { return h.updateID }
+
+func (h *MockWorkflowUpdateHandle) Get(ctx context.Context, valuePtr interface{}) error {
+	if !h.completed {
+		return fmt.Errorf("update %s has not completed yet", h.updateID)
+	}
+	if h.err != nil || valuePtr == nil {
+		return h.err
+	}
+	dc := converter.GetDefaultDataConverter()
+	payload, err := dc.ToPayload(h.result)
+	if err != nil {
+		return err
+	}
+	return dc.FromPayload(payload, valuePtr)
+}
+
+func (h *MockWorkflowUpdateHandle) complete(result interface{}, err error) {
+	h.completed = true
+	h.result = result
+	h.err = err
 }
 
 type MockWorkflow struct {
@@ -143,7 +189,25 @@ func NewEnvWithWorkflows(wfs ...MockWorkflow) *testsuite.TestWorkflowEnvironment
 }
 
 func NewTemporalMockClient(env *testsuite.TestWorkflowEnvironment) client.Client {
-	return &MockClient{env}
+	return &MockClient{Env: env}
+}
+
+// NewTemporalMockClientWithHistory returns a client of the workflow executed by env that records
+// its event history for GetWorkflowHistory, see RecordHistory.
+func NewTemporalMockClientWithHistory(env *testsuite.TestWorkflowEnvironment) client.Client {
+	c := &MockClient{Env: env}
+	c.RecordHistory()
+	return c
+}
+
+// RecordHistory makes the client record the event history of the workflow executed from now on
+// for GetWorkflowHistory. The data converter, the worker interceptors and the activity and timer
+// listeners set on the environment are wrapped and keep working; a listener set afterwards
+// replaces the recording one.
+func (m *MockClient) RecordHistory() {
+	if m.history == nil {
+		m.history = newHistoryRecorder(m.Env)
+	}
 }
 
 func (m *MockClient) GetEnv() *testsuite.TestWorkflowEnvironment {
@@ -161,12 +225,17 @@ func (m *MockClient) ExecuteWorkflow(
 		options.ID = uuid.New()
 	}
 	m.Env = m.Env.SetStartWorkflowOptions(options)
+	m.run = &MockWorkflowRun{Env: m.Env, ID: options.ID, RunID: uuid.New()}
 	m.Env.ExecuteWorkflow(workflow, args...)
-	return &MockWorkflowRun{m.Env, options.ID}, nil
+	return m.run, nil
 }
 
+// GetWorkflow returns the run of the workflow executed by the test environment.
 func (m *MockClient) GetWorkflow(ctx context.Context, workflowID string, runID string) client.WorkflowRun {
-	return nil
+	if runID == "" && m.run != nil {
+		runID = m.run.RunID
+	}
+	return &MockWorkflowRun{Env: m.Env, ID: workflowID, RunID: runID}
 }
 
 func (m *MockClient) SignalWorkflow(
@@ -180,7 +249,17 @@ func (m *MockClient) SignalWorkflow(
 }
 
 func (m *MockClient) SignalWithStartWorkflow(ctx context.Context, workflowID string, signalName string, signalArg interface{}, options client.StartWorkflowOptions, workflow interface{}, workflowArgs ...interface{}) (client.WorkflowRun, error) {
-	return nil, nil
+	options.ID = workflowID
+	// deliver the signal as soon as the workflow has started
+	var signalErr error
+	m.Env.RegisterDelayedCallback(func() {
+		signalErr = m.Env.SignalWorkflowByID(workflowID, signalName, signalArg)
+	}, 0)
+	run, err := m.ExecuteWorkflow(ctx, options, workflow, workflowArgs...)
+	if err == nil {
+		err = signalErr
+	}
+	return run, err
 }
 
 func (m *MockClient) CancelWorkflow(ctx context.Context, workflowID string, runID string) error {
@@ -192,8 +271,17 @@ func (m *MockClient) TerminateWorkflow(ctx context.Context, workflowID string, r
 	return nil
 }
 
+// GetWorkflowHistory returns the event history recorded for the workflow executed by the test
+// environment, see RecordGoldenHistory.
 func (m *MockClient) GetWorkflowHistory(ctx context.Context, workflowID string, runID string, isLongPoll bool, filterType enumspb.HistoryEventFilterType) client.HistoryEventIterator {
-	return nil
+	if m.history == nil {
+		return &historyIterator{err: errors.New("the history is only recorded after MockClient.RecordHistory")}
+	}
+	history, err := m.history.history(workflowID)
+	if err != nil {
+		return &historyIterator{err: err}
+	}
+	return &historyIterator{events: history.Events}
 }
 
 func (m *MockClient) CompleteActivity(ctx context.Context, taskToken []byte, result interface{}, err error) error {
@@ -248,8 +336,30 @@ func (m *MockClient) QueryWorkflowWithOptions(ctx context.Context, request *clie
 	return nil, nil
 }
 
+// DescribeWorkflowExecution reports the workflow executed by the test environment as running
+// until it completes, and then as completed, canceled or failed.
 func (m *MockClient) DescribeWorkflowExecution(ctx context.Context, workflowID, runID string) (*workflowservice.DescribeWorkflowExecutionResponse, error) {
-	return nil, nil
+	if m.run == nil || m.run.ID != workflowID {
+		return nil, serviceerror.NewNotFound(fmt.Sprintf("workflow %s not found", workflowID))
+	}
+	status := enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING
+	if m.Env.IsWorkflowCompleted() {
+		var canceled *temporal.CanceledError
+		switch err := m.Env.GetWorkflowError(); {
+		case err == nil:
+			status = enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED
+		case errors.As(err, &canceled):
+			status = enumspb.WORKFLOW_EXECUTION_STATUS_CANCELED
+		default:
+			status = enumspb.WORKFLOW_EXECUTION_STATUS_FAILED
+		}
+	}
+	return &workflowservice.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &workflowpb.WorkflowExecutionInfo{
+			Execution: &commonpb.WorkflowExecution{WorkflowId: workflowID, RunId: m.run.RunID},
+			Status:    status,
+		},
+	}, nil
 }
 
 func (m *MockClient) DescribeTaskQueue(ctx context.Context, taskqueue string, taskqueueType enumspb.TaskQueueType) (*workflowservice.DescribeTaskQueueResponse, error) {
@@ -273,6 +383,9 @@ func (m *MockClient) GetWorkerBuildIdCompatibility(ctx context.Context, options
 }
 
 func (m *MockClient) GetWorkflowUpdateHandle(ref client.GetWorkflowUpdateHandleOptions) client.WorkflowUpdateHandle {
+	if h, has := m.updates[ref.UpdateID]; has {
+		return h
+	}
 	return nil
 }
 
@@ -284,16 +397,35 @@ func (m *MockClient) CheckHealth(ctx context.Context, request *client.CheckHealt
 	return nil, nil
 }
 
+// UpdateWorkflow sends the update to the workflow running in the test environment. The update
+// is handled once the current callback returns, so the result of the returned handle is only
+// available after that.
 func (m *MockClient) UpdateWorkflow(
 	ctx context.Context, options client.UpdateWorkflowOptions,
 ) (client.WorkflowUpdateHandle, error) {
-	return nil, nil
+	if options.UpdateID == "" {
+		options.UpdateID = uuid.New()
+	}
+	h := &MockWorkflowUpdateHandle{workflowID: options.WorkflowID, runID: options.RunID, updateID: options.UpdateID}
+	err := m.Env.UpdateWorkflowByID(options.WorkflowID, options.UpdateName, options.UpdateID, &testsuite.TestUpdateCallback{
+		OnAccept:   func() {},
+		OnReject:   func(err error) { h.complete(nil, err) },
+		OnComplete: h.complete,
+	}, options.Args...)
+	if err != nil {
+		return nil, err
+	}
+	if m.updates == nil {
+		m.updates = map[string]*MockWorkflowUpdateHandle{}
+	}
+	m.updates[options.UpdateID] = h
+	return h, nil
 }
 
 func (m *MockClient) UpdateWorkflowWithOptions(
 	ctx context.Context, request *client.UpdateWorkflowOptions,
 ) (client.WorkflowUpdateHandle, error) {
-	return nil, nil
+	return m.UpdateWorkflow(ctx, *request)
 }
 
 func (m *MockClient) UpdateWorkerVersioningRules(ctx context.Context, options client.UpdateWorkerVersioningRulesOptions) (*client.WorkerVersioningRules, error) {
@@ -0,0 +1,115 @@
+package temporal_tester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/anz-bank/sysl-go/core"
+)
+
+func approvalWorkflow(ctx workflow.Context) (string, error) {
+	status := "pending"
+	if err := workflow.SetQueryHandler(ctx, "Status", func() (string, error) {
+		return status, nil
+	}); err != nil {
+		return "", err
+	}
+	if err := core.SetUpdateHandler(ctx, "Rename", func(ctx workflow.Context, s string) (string, error) {
+		status = s
+		return "renamed to " + s, nil
+	}, nil); err != nil {
+		return "", err
+	}
+	decision, _ := core.GetSignalChannel[string](ctx, "Approve").Receive(ctx)
+	status = decision
+	return status, nil
+}
+
+func TestMockClientRoutesSignalsQueriesAndUpdates(t *testing.T) {
+	env := NewEnvWithWorkflows(MockWorkflow{
+		Workflow: approvalWorkflow,
+		Option:   workflow.RegisterOptions{Name: "Approval"},
+	})
+	c := NewTemporalMockClient(env)
+	ctx := context.Background()
+
+	var update *core.UpdateHandle[string]
+	env.RegisterDelayedCallback(func() {
+		status, err := core.QueryWorkflow[string](ctx, c, "wf", "", "Status")
+		require.NoError(t, err)
+		require.Equal(t, "pending", status)
+
+		update, err = core.UpdateWorkflow[string](ctx, c, "wf", "", "Rename", "reviewing")
+		require.NoError(t, err)
+	}, time.Minute)
+	env.RegisterDelayedCallback(func() {
+		require.NoError(t, c.SignalWorkflow(ctx, "wf", "", "Approve", "approved"))
+	}, time.Hour)
+
+	run, err := core.ExecuteWorkflow[string](ctx, client.StartWorkflowOptions{ID: "wf"}, c, "tq", "Approval")
+	require.NoError(t, err)
+	result, err := run.Get(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "approved", result)
+
+	updated, err := update.Get(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "renamed to reviewing", updated)
+}
+
+func TestMockClientSignalWithStartWorkflow(t *testing.T) {
+	env := NewEnvWithWorkflows(MockWorkflow{
+		Workflow: approvalWorkflow,
+		Option:   workflow.RegisterOptions{Name: "Approval"},
+	})
+	c := NewTemporalMockClient(env)
+	ctx := context.Background()
+
+	run, err := c.SignalWithStartWorkflow(ctx, "wf", "Approve", "rejected", client.StartWorkflowOptions{}, "Approval")
+	require.NoError(t, err)
+	var result string
+	require.NoError(t, run.Get(ctx, &result))
+	require.Equal(t, "rejected", result)
+}
+
+func TestMockClientDescribesTheWorkflow(t *testing.T) {
+	env := NewEnvWithWorkflows(MockWorkflow{
+		Workflow: approvalWorkflow,
+		Option:   workflow.RegisterOptions{Name: "Approval"},
+	})
+	c := NewTemporalMockClient(env)
+	ctx := context.Background()
+
+	_, err := c.DescribeWorkflowExecution(ctx, "wf", "")
+	var notFound *serviceerror.NotFound
+	require.ErrorAs(t, err, &notFound)
+
+	env.RegisterDelayedCallback(func() {
+		desc, err := c.DescribeWorkflowExecution(ctx, "wf", "")
+		require.NoError(t, err)
+		require.Equal(t, enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING, desc.GetWorkflowExecutionInfo().GetStatus())
+		require.NoError(t, c.SignalWorkflow(ctx, "wf", "", "Approve", "approved"))
+	}, time.Minute)
+
+	run, err := c.ExecuteWorkflow(ctx, client.StartWorkflowOptions{ID: "wf"}, "Approval")
+	require.NoError(t, err)
+	require.NotEmpty(t, run.GetRunID())
+
+	desc, err := c.DescribeWorkflowExecution(ctx, "wf", "")
+	require.NoError(t, err)
+	require.Equal(t, enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED, desc.GetWorkflowExecutionInfo().GetStatus())
+	require.Equal(t, run.GetRunID(), desc.GetWorkflowExecutionInfo().GetExecution().GetRunId())
+
+	got := c.GetWorkflow(ctx, "wf", "")
+	require.Equal(t, run.GetRunID(), got.GetRunID())
+	var result string
+	require.NoError(t, got.Get(ctx, &result))
+	require.Equal(t, "approved", result)
+}
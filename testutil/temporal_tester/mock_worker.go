@@ -23,6 +23,10 @@ func MockWorkerBuilder(client client.Client, taskQueue string, options worker.Op
 		panic("MockWorker needs MockClient")
 	}
 	suite := &testsuite.WorkflowTestSuite{}
+	if mockClient.history != nil {
+		// keep recording the history of the workflow
+		options.Interceptors = append(options.Interceptors[:len(options.Interceptors):len(options.Interceptors)], mockClient.history)
+	}
 	mockClient.Env = mockClient.Env.SetWorkerOptions(options)
 
 	return &MockWorker{
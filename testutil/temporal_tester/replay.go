@@ -0,0 +1,139 @@
+package temporal_tester
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/nexus-rpc/sdk-go/nexus"
+	"github.com/stretchr/testify/require"
+	enumspb "go.temporal.io/api/enums/v1"
+	historypb "go.temporal.io/api/history/v1"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/anz-bank/sysl-go/syslgo"
+)
+
+// UpdateHistoriesEnv is the environment variable that makes RecordGoldenHistory overwrite
+// the golden history files instead of leaving the existing ones in place.
+const UpdateHistoriesEnv = "SYSLGO_UPDATE_TEMPORAL_HISTORIES"
+
+var _ worker.Worker = &ReplayWorker{}
+
+// ReplayWorker is a worker that registers workflows with a workflow replayer, which lets the
+// generated TemporalServiceHandler register its workflows for replay tests.
+// Activities are never executed when replaying so their registrations are ignored.
+type ReplayWorker struct {
+	replayer worker.WorkflowReplayer
+}
+
+func NewReplayWorker() *ReplayWorker {
+	return &ReplayWorker{replayer: worker.NewWorkflowReplayer()}
+}
+
+// ReplayWorkerBuilder can be used as the ExperimentalTemporalWorkerBuilder hook to build a ReplayWorker.
+func ReplayWorkerBuilder(client.Client, string, worker.Options) worker.Worker {
+	return NewReplayWorker()
+}
+
+func (rw *ReplayWorker) Start() error                             { return nil }
+func (rw *ReplayWorker) Run(interruptCh <-chan interface{}) error { return nil }
+func (rw *ReplayWorker) Stop()                                    {}
+
+func (rw *ReplayWorker) RegisterWorkflow(w interface{}) {
+	rw.replayer.RegisterWorkflow(w)
+}
+
+func (rw *ReplayWorker) RegisterWorkflowWithOptions(w interface{}, options workflow.RegisterOptions) {
+	rw.replayer.RegisterWorkflowWithOptions(w, options)
+}
+
+func (rw *ReplayWorker) RegisterDynamicWorkflow(w interface{}, options workflow.DynamicRegisterOptions) {
+	rw.replayer.RegisterDynamicWorkflow(w, options)
+}
+
+func (rw *ReplayWorker) RegisterActivity(a interface{}) {}
+
+func (rw *ReplayWorker) RegisterActivityWithOptions(a interface{}, options activity.RegisterOptions) {
+}
+
+func (rw *ReplayWorker) RegisterDynamicActivity(a interface{}, options activity.DynamicRegisterOptions) {
+}
+
+func (rw *ReplayWorker) RegisterNexusService(s *nexus.Service) {}
+
+// GetReplayer returns the underlying replayer for the cases the helpers below do not cover.
+func (rw *ReplayWorker) GetReplayer() worker.WorkflowReplayer {
+	return rw.replayer
+}
+
+// ReplayHistoryFile replays the workflow history in the given JSON file, as exported by the
+// Temporal CLI or recorded by RecordWorkflowHistory, and fails the test on non-determinism.
+func (rw *ReplayWorker) ReplayHistoryFile(t syslgo.TestingT, path string) {
+	require.NoError(t, rw.replayer.ReplayWorkflowHistoryFromJSONFile(nil, path), "replaying %s", path)
+}
+
+// ReplayHistoryDir replays every JSON workflow history found in dir.
+func (rw *ReplayWorker) ReplayHistoryDir(t syslgo.TestingT, dir string) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths, "no workflow histories found in %s", dir)
+
+	sort.Strings(paths)
+	for _, path := range paths {
+		rw.ReplayHistoryFile(t, path)
+	}
+}
+
+// GetWorkflowHistory reads the full event history of a workflow execution.
+func GetWorkflowHistory(ctx context.Context, c client.Client, workflowID, runID string) (*historypb.History, error) {
+	iter := c.GetWorkflowHistory(ctx, workflowID, runID, false, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+
+	history := &historypb.History{}
+	for iter.HasNext() {
+		event, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		history.Events = append(history.Events, event)
+	}
+
+	return history, nil
+}
+
+// RecordWorkflowHistory writes the event history of a workflow execution to path in the JSON
+// format that the replayer reads.
+//
+// The client is either connected to a Temporal server or is a MockClient that records the
+// history of the workflow executed by its TestWorkflowEnvironment, see MockClient.RecordHistory.
+func RecordWorkflowHistory(ctx context.Context, c client.Client, workflowID, runID, path string) error {
+	history, err := GetWorkflowHistory(ctx, c, workflowID, runID)
+	if err != nil {
+		return err
+	}
+
+	b, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(history)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, b, 0o600)
+}
+
+// RecordGoldenHistory records the event history of a workflow execution into the golden file at
+// path when the file does not exist yet or when UpdateHistoriesEnv is set.
+func RecordGoldenHistory(ctx context.Context, t syslgo.TestingT, c client.Client, workflowID, runID, path string) {
+	if _, err := os.Stat(path); err == nil && os.Getenv(UpdateHistoriesEnv) == "" {
+		return
+	}
+	require.NoError(t, RecordWorkflowHistory(ctx, c, workflowID, runID, path), "recording %s", path)
+}
@@ -0,0 +1,115 @@
+package temporal_tester
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	historypb "go.temporal.io/api/history/v1"
+	taskqueuepb "go.temporal.io/api/taskqueue/v1"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/mocks"
+	"go.temporal.io/sdk/workflow"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func greetWorkflow(ctx workflow.Context) (string, error) {
+	return "hello", nil
+}
+
+func changedGreetWorkflow(ctx workflow.Context) (string, error) {
+	if err := workflow.Sleep(ctx, time.Minute); err != nil {
+		return "", err
+	}
+	return "hello", nil
+}
+
+func greetHistory(t *testing.T) []*historypb.HistoryEvent {
+	result, err := converter.GetDefaultDataConverter().ToPayloads("hello")
+	require.NoError(t, err)
+
+	now := time.Now()
+	event := func(id int64, eventType enumspb.EventType) *historypb.HistoryEvent {
+		return &historypb.HistoryEvent{EventId: id, EventType: eventType, EventTime: timestamppb.New(now)}
+	}
+
+	started := event(1, enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED)
+	started.Attributes = &historypb.HistoryEvent_WorkflowExecutionStartedEventAttributes{
+		WorkflowExecutionStartedEventAttributes: &historypb.WorkflowExecutionStartedEventAttributes{
+			WorkflowType: &commonpb.WorkflowType{Name: "Greet"},
+			TaskQueue:    &taskqueuepb.TaskQueue{Name: "tq"},
+		},
+	}
+	scheduled := event(2, enumspb.EVENT_TYPE_WORKFLOW_TASK_SCHEDULED)
+	scheduled.Attributes = &historypb.HistoryEvent_WorkflowTaskScheduledEventAttributes{
+		WorkflowTaskScheduledEventAttributes: &historypb.WorkflowTaskScheduledEventAttributes{
+			TaskQueue: &taskqueuepb.TaskQueue{Name: "tq"},
+		},
+	}
+	taskStarted := event(3, enumspb.EVENT_TYPE_WORKFLOW_TASK_STARTED)
+	taskStarted.Attributes = &historypb.HistoryEvent_WorkflowTaskStartedEventAttributes{
+		WorkflowTaskStartedEventAttributes: &historypb.WorkflowTaskStartedEventAttributes{ScheduledEventId: 2},
+	}
+	taskCompleted := event(4, enumspb.EVENT_TYPE_WORKFLOW_TASK_COMPLETED)
+	taskCompleted.Attributes = &historypb.HistoryEvent_WorkflowTaskCompletedEventAttributes{
+		WorkflowTaskCompletedEventAttributes: &historypb.WorkflowTaskCompletedEventAttributes{
+			ScheduledEventId: 2,
+			StartedEventId:   3,
+		},
+	}
+	completed := event(5, enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED)
+	completed.Attributes = &historypb.HistoryEvent_WorkflowExecutionCompletedEventAttributes{
+		WorkflowExecutionCompletedEventAttributes: &historypb.WorkflowExecutionCompletedEventAttributes{
+			Result:                       result,
+			WorkflowTaskCompletedEventId: 4,
+		},
+	}
+
+	return []*historypb.HistoryEvent{started, scheduled, taskStarted, taskCompleted, completed}
+}
+
+func mockHistoryClient(t *testing.T) *mocks.Client {
+	iter := &mocks.HistoryEventIterator{}
+	for _, event := range greetHistory(t) {
+		iter.On("HasNext").Return(true).Once()
+		iter.On("Next").Return(event, nil).Once()
+	}
+	iter.On("HasNext").Return(false)
+
+	c := &mocks.Client{}
+	c.On("GetWorkflowHistory", mock.Anything, "wf", "run", false, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT).Return(iter)
+	return c
+}
+
+func TestReplayWorkerReplaysRecordedHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "histories", "greet.json")
+	require.NoError(t, RecordWorkflowHistory(context.Background(), mockHistoryClient(t), "wf", "run", path))
+
+	rw := NewReplayWorker()
+	rw.RegisterWorkflowWithOptions(greetWorkflow, workflow.RegisterOptions{Name: "Greet"})
+	rw.RegisterActivity(func(context.Context) error { return nil })
+	rw.ReplayHistoryFile(t, path)
+	rw.ReplayHistoryDir(t, filepath.Dir(path))
+}
+
+func TestReplayWorkerDetectsNonDeterminism(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "greet.json")
+	require.NoError(t, RecordWorkflowHistory(context.Background(), mockHistoryClient(t), "wf", "run", path))
+
+	rw := NewReplayWorker()
+	rw.RegisterWorkflowWithOptions(changedGreetWorkflow, workflow.RegisterOptions{Name: "Greet"})
+	require.Error(t, rw.GetReplayer().ReplayWorkflowHistoryFromJSONFile(nil, path))
+}
+
+func TestRecordGoldenHistoryKeepsExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "greet.json")
+	RecordGoldenHistory(context.Background(), t, mockHistoryClient(t), "wf", "run", path)
+
+	// the golden file exists so the client is not asked for the history again
+	RecordGoldenHistory(context.Background(), t, &mocks.Client{}, "wf", "run", path)
+}
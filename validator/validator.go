@@ -1,6 +1,7 @@
 package validator
 
 import (
+	"errors"
 	"reflect"
 	"strings"
 	"time"
@@ -66,7 +67,8 @@ func RegisterStructLevel(fn vv10.StructLevelFunc, types ...interface{}) {
 
 // Validate validates the fields of a struct based
 // on 'validator' tags and returns errors found indexed
-// by the field name.
+// by the field name. The elements of a slice are all
+// validated and their errors joined.
 func Validate(v interface{}) error {
 	if reflect.TypeOf(v).Kind() == reflect.String {
 		return nil
@@ -78,15 +80,18 @@ func Validate(v interface{}) error {
 			return nil
 		}
 	} else if val.Kind() == reflect.Slice {
+		// validate every element, so that all the invalid ones are reported at once
+		var errs []error
 		n := val.Len()
 		for i := 0; i < n; i++ {
-			err := Validate(val.Index(i).Interface())
-			if err != nil {
-				return err
+			if err := Validate(val.Index(i).Interface()); err != nil {
+				errs = append(errs, err)
 			}
 		}
-
-		return nil
+		if len(errs) == 1 {
+			return errs[0]
+		}
+		return errors.Join(errs...)
 	}
 
 	if DefaultValidator == nil {
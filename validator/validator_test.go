@@ -113,6 +113,12 @@ func TestValidateArray(t *testing.T) {
 	req.Nil(err)
 	err = Validate([]dummyObj{{Foo: 10}})
 	req.NotNil(err)
+	_, ok := err.(vv10.ValidationErrors)
+	req.True(ok)
+
+	err = Validate([]dummyObj{{Foo: 4}, {Foo: 6}, {Foo: 10}})
+	req.Error(err)
+	req.Len(err.(interface{ Unwrap() []error }).Unwrap(), 2)
 }
 
 type innerDummyType struct {